@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/backup"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/config"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/httpclient"
+	"github.com/spf13/cobra"
+)
+
+var (
+	applyInput       string
+	applyAllowShrink bool
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Write a projects JSON file to Contentful without running GitHub or Gemini",
+	Long: "Reads a JSON array of projects (for example a hand-edited snapshot) and writes and " +
+		"publishes it to the configured projects entry. Refuses an empty file, and a file that " +
+		"would drop more than half of the current projects unless --allow-shrink is given.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if applyInput == "" {
+			return fmt.Errorf("--input is required")
+		}
+
+		cfg, err := config.LoadContentful()
+		if err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		cmaClient := contentful.NewClient(cfg.SpaceID, cfg.Environment, cfg.CMAToken, httpclient.New(cfg.HTTPTimeout))
+		cmaClient.SetFieldNames(cfg.FieldNames)
+		return applyFile(ctx, cmaClient, cfg, applyInput, applyAllowShrink)
+	},
+}
+
+func init() {
+	applyCmd.Flags().StringVar(&applyInput, "input", "", "JSON file with the projects to write")
+	applyCmd.Flags().BoolVar(&applyAllowShrink, "allow-shrink", false, "Allow dropping more than half of the current projects")
+	rootCmd.AddCommand(applyCmd)
+}
+
+// applyFile writes and publishes the projects in the JSON file at input to
+// the configured projects entry, after the shrink guard and an optional
+// backup of the current content.
+func applyFile(ctx context.Context, cmaClient *contentful.Client, cfg *config.Config, input string, allowShrink bool) error {
+	projects, err := readProjectsFile(input)
+	if err != nil {
+		return err
+	}
+
+	entryID, err := cmaClient.ResolveEntryID(ctx, cfg.EntryID, cfg.SectionID)
+	if err != nil {
+		return err
+	}
+	result, err := cmaClient.GetProjects(ctx, entryID)
+	if err != nil {
+		return fmt.Errorf("get projects: %w", err)
+	}
+
+	if err := checkShrink(len(result.Projects), len(projects), allowShrink); err != nil {
+		return err
+	}
+
+	if cfg.BackupDir != "" {
+		path, err := backup.Write(cfg.BackupDir, "default", result.Projects)
+		if err != nil {
+			return fmt.Errorf("backup: %w", err)
+		}
+		log.Printf("Backed up %d current projects to %s", len(result.Projects), path)
+	}
+
+	newVersion, err := cmaClient.UpdateProjects(ctx, result, projects)
+	if err != nil {
+		return fmt.Errorf("update projects: %w", err)
+	}
+	cmaClient.WaitBeforePublish(ctx, cfg.PublishDelay)
+	if err := cmaClient.PublishEntry(ctx, result.EntryID, newVersion); err != nil {
+		return fmt.Errorf("publish: %w", err)
+	}
+
+	diff := contentful.DiffProjects(result.Projects, projects)
+	log.Printf("Applied %s to %s: %d projects (added %v, removed %v, updated %v)",
+		input, result.EntryID, len(projects), diff.Added, diff.Removed, diff.Updated)
+	return nil
+}
+
+func readProjectsFile(path string) ([]contentful.Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read input: %w", err)
+	}
+	var projects []contentful.Project
+	if err := json.Unmarshal(data, &projects); err != nil {
+		return nil, fmt.Errorf("parse input: %w", err)
+	}
+	return projects, nil
+}
+
+// checkShrink rejects writing no projects at all, and unless allowed, a list
+// less than half the size of what the entry currently holds.
+func checkShrink(current, next int, allow bool) error {
+	if next == 0 {
+		return fmt.Errorf("refusing to write an empty project list")
+	}
+	if !allow && next*2 < current {
+		return fmt.Errorf("refusing to shrink projects from %d to %d (use --allow-shrink)", current, next)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/config"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+// fakeEntryCMA serves one projects entry over the CMA HTTP API and records
+// the writes made to it.
+type fakeEntryCMA struct {
+	content   []contentful.Project
+	version   int
+	puts      [][]contentful.Project
+	published []string
+}
+
+func (f *fakeEntryCMA) RoundTrip(req *http.Request) (*http.Response, error) {
+	respond := func(status int, body string) (*http.Response, error) {
+		return &http.Response{StatusCode: status, Header: make(http.Header), Body: io.NopCloser(strings.NewReader(body))}, nil
+	}
+	entry := func() (*http.Response, error) {
+		data, _ := json.Marshal(map[string]interface{}{
+			"sys":    map[string]interface{}{"id": "projects", "version": f.version},
+			"fields": map[string]interface{}{"content": map[string]interface{}{contentful.DefaultLocale: f.content}},
+		})
+		return respond(200, string(data))
+	}
+
+	switch {
+	case req.Method == "GET" && strings.HasSuffix(req.URL.Path, "/entries/projects"):
+		return entry()
+	case req.Method == "PUT" && strings.HasSuffix(req.URL.Path, "/entries/projects"):
+		var body struct {
+			Fields struct {
+				Content map[string][]contentful.Project `json:"content"`
+			} `json:"fields"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return respond(400, err.Error())
+		}
+		f.content = body.Fields.Content[contentful.DefaultLocale]
+		f.puts = append(f.puts, f.content)
+		f.version++
+		return entry()
+	case req.Method == "PUT" && strings.HasSuffix(req.URL.Path, "/entries/projects/published"):
+		f.published = append(f.published, req.Header.Get("X-Contentful-Version"))
+		return entry()
+	}
+	return respond(404, "not found")
+}
+
+func TestApplyFile(t *testing.T) {
+	current := []contentful.Project{{Slug: "a"}, {Slug: "b"}, {Slug: "c"}, {Slug: "d"}}
+
+	tests := []struct {
+		name        string
+		input       string
+		allowShrink bool
+		wantErr     string
+		wantSlugs   []string
+	}{
+		{name: "writes and publishes", input: `[{"slug":"a","name":"A (edited)"},{"slug":"b"},{"slug":"e"}]`, wantSlugs: []string{"a", "b", "e"}},
+		{name: "empty file is refused", input: `[]`, wantErr: "empty project list"},
+		{name: "shrink is refused", input: `[{"slug":"a"}]`, wantErr: "refusing to shrink"},
+		{name: "shrink allowed", input: `[{"slug":"a"}]`, allowShrink: true, wantSlugs: []string{"a"}},
+		{name: "invalid JSON", input: `{"slug":"a"}`, wantErr: "parse input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "projects.json")
+			if err := os.WriteFile(path, []byte(tt.input), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cma := &fakeEntryCMA{content: current, version: 3}
+			client := contentful.NewClient("space", "", "token", &http.Client{Transport: cma})
+			cfg := &config.Config{EntryID: "projects"}
+
+			err := applyFile(context.Background(), client, cfg, path, tt.allowShrink)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				if len(cma.puts) > 0 || len(cma.published) > 0 {
+					t.Errorf("refused apply wrote %d times and published %v", len(cma.puts), cma.published)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(cma.puts) != 1 {
+				t.Fatalf("made %d writes, want 1", len(cma.puts))
+			}
+			var slugs []string
+			for _, p := range cma.puts[0] {
+				slugs = append(slugs, p.Slug)
+			}
+			if !reflect.DeepEqual(slugs, tt.wantSlugs) {
+				t.Errorf("written slugs = %v, want %v", slugs, tt.wantSlugs)
+			}
+			if !reflect.DeepEqual(cma.published, []string{"4"}) {
+				t.Errorf("published versions = %v, want the written version 4", cma.published)
+			}
+		})
+	}
+}
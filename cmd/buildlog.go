@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/config"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/httpclient"
+	"github.com/spf13/cobra"
+)
+
+var (
+	buildLogSince  string
+	buildLogUntil  string
+	buildLogStatus string
+)
+
+var buildLogCmd = &cobra.Command{
+	Use:   "buildlog",
+	Short: "Show the shared build log grouped by service",
+	Long: "Prints recent build-log entries from every service writing to the shared log, " +
+		"grouped by service with the latest status of each marked.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		since, err := parseDateFlag("since", buildLogSince)
+		if err != nil {
+			return err
+		}
+		until, err := parseDateFlag("until", buildLogUntil)
+		if err != nil {
+			return err
+		}
+		if !until.IsZero() && len(buildLogUntil) == len("2006-01-02") {
+			// A bare date includes the whole day.
+			until = until.Add(24*time.Hour - time.Nanosecond)
+		}
+
+		cfg, err := config.LoadContentful()
+		if err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		cmaClient := contentful.NewClient(cfg.SpaceID, cfg.Environment, cfg.CMAToken, httpclient.New(cfg.HTTPTimeout))
+		result, err := cmaClient.GetBuildLog(ctx)
+		if err != nil {
+			return fmt.Errorf("get build log: %w", err)
+		}
+
+		entries := filterBuildLog(result.Entries, since, until, buildLogStatus)
+		printBuildLog(cmd.OutOrStdout(), groupBuildLog(entries))
+		return nil
+	},
+}
+
+func init() {
+	buildLogCmd.Flags().StringVar(&buildLogSince, "since", "", "Only entries at or after this date (YYYY-MM-DD or RFC3339)")
+	buildLogCmd.Flags().StringVar(&buildLogUntil, "until", "", "Only entries at or before this date (YYYY-MM-DD or RFC3339)")
+	buildLogCmd.Flags().StringVar(&buildLogStatus, "status", "", "Only entries with this status")
+	rootCmd.AddCommand(buildLogCmd)
+}
+
+// serviceLog holds one service's entries in chronological order.
+type serviceLog struct {
+	Service string
+	Entries []contentful.BuildLogEntry
+}
+
+func parseDateFlag(name, v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--%s: expected YYYY-MM-DD or RFC3339, got %q", name, v)
+	}
+	return t, nil
+}
+
+// filterBuildLog keeps entries within [since, until] with a matching status.
+// Zero times and an empty status disable the respective filter. Entries with
+// an unparsable timestamp are dropped when a date filter is active.
+func filterBuildLog(entries []contentful.BuildLogEntry, since, until time.Time, status string) []contentful.BuildLogEntry {
+	var filtered []contentful.BuildLogEntry
+	for _, e := range entries {
+		if status != "" && !strings.EqualFold(e.Status, status) {
+			continue
+		}
+		if !since.IsZero() || !until.IsZero() {
+			ts, err := time.Parse(time.RFC3339, e.Timestamp)
+			if err != nil {
+				continue
+			}
+			if !since.IsZero() && ts.Before(since) {
+				continue
+			}
+			if !until.IsZero() && ts.After(until) {
+				continue
+			}
+		}
+		filtered = append(filtered, e)
+	}
+	return filtered
+}
+
+// groupBuildLog groups entries by service, sorted by service name. Each
+// group's entries are ordered oldest first by timestamp.
+func groupBuildLog(entries []contentful.BuildLogEntry) []serviceLog {
+	byService := make(map[string][]contentful.BuildLogEntry)
+	for _, e := range entries {
+		byService[e.Service] = append(byService[e.Service], e)
+	}
+
+	groups := make([]serviceLog, 0, len(byService))
+	for service, es := range byService {
+		sort.SliceStable(es, func(i, j int) bool {
+			return es[i].Timestamp < es[j].Timestamp
+		})
+		groups = append(groups, serviceLog{Service: service, Entries: es})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Service < groups[j].Service
+	})
+	return groups
+}
+
+func printBuildLog(w io.Writer, groups []serviceLog) {
+	if len(groups) == 0 {
+		fmt.Fprintln(w, "No build-log entries match.")
+		return
+	}
+
+	for _, g := range groups {
+		latest := g.Entries[len(g.Entries)-1]
+		fmt.Fprintf(w, "%s (latest: %s at %s)\n", g.Service, latest.Status, latest.Timestamp)
+		for i, e := range g.Entries {
+			marker := " "
+			if i == len(g.Entries)-1 {
+				marker = "*"
+			}
+			fmt.Fprintf(w, "  %s %-25s %-10s %-15s added=%d total=%d force=%t\n",
+				marker, e.Timestamp, e.Status, e.TriggeredBy, e.NewAdded, e.TotalAfterSync, e.ForceUpdate)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+func logEntry(service, ts, status string) contentful.BuildLogEntry {
+	var e contentful.BuildLogEntry
+	e.Service, e.Timestamp, e.Status = service, ts, status
+	return e
+}
+
+func TestFilterBuildLog(t *testing.T) {
+	entries := []contentful.BuildLogEntry{
+		logEntry("github-sync", "2026-03-01T10:00:00Z", "success"),
+		logEntry("github-sync", "2026-03-05T10:00:00Z", "error"),
+		logEntry("blog-sync", "2026-03-10T10:00:00Z", "Success"),
+		logEntry("blog-sync", "not a time", "success"),
+	}
+	day := func(s string) time.Time {
+		t, _ := time.Parse("2006-01-02", s)
+		return t
+	}
+
+	tests := []struct {
+		name   string
+		since  time.Time
+		until  time.Time
+		status string
+		want   []string
+	}{
+		{name: "no filters", want: []string{"2026-03-01T10:00:00Z", "2026-03-05T10:00:00Z", "2026-03-10T10:00:00Z", "not a time"}},
+		{name: "status ignores case", status: "success", want: []string{"2026-03-01T10:00:00Z", "2026-03-10T10:00:00Z", "not a time"}},
+		{name: "since", since: day("2026-03-05"), want: []string{"2026-03-05T10:00:00Z", "2026-03-10T10:00:00Z"}},
+		{name: "until", until: day("2026-03-05"), want: []string{"2026-03-01T10:00:00Z"}},
+		{name: "range and status", since: day("2026-03-02"), until: day("2026-03-31"), status: "error", want: []string{"2026-03-05T10:00:00Z"}},
+		{name: "nothing matches", status: "running"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range filterBuildLog(entries, tt.since, tt.until, tt.status) {
+				got = append(got, e.Timestamp)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("timestamps = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupBuildLog(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []contentful.BuildLogEntry
+		want    map[string][]string
+		order   []string
+	}{
+		{name: "empty"},
+		{
+			name: "grouped by service, oldest first",
+			entries: []contentful.BuildLogEntry{
+				logEntry("github-sync", "2026-03-05T10:00:00Z", "error"),
+				logEntry("blog-sync", "2026-03-10T10:00:00Z", "success"),
+				logEntry("github-sync", "2026-03-01T10:00:00Z", "success"),
+			},
+			order: []string{"blog-sync", "github-sync"},
+			want: map[string][]string{
+				"blog-sync":   {"2026-03-10T10:00:00Z"},
+				"github-sync": {"2026-03-01T10:00:00Z", "2026-03-05T10:00:00Z"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := groupBuildLog(tt.entries)
+			var order []string
+			for _, g := range groups {
+				order = append(order, g.Service)
+				var ts []string
+				for _, e := range g.Entries {
+					ts = append(ts, e.Timestamp)
+				}
+				if !reflect.DeepEqual(ts, tt.want[g.Service]) {
+					t.Errorf("%s timestamps = %v, want %v", g.Service, ts, tt.want[g.Service])
+				}
+			}
+			if !reflect.DeepEqual(order, tt.order) {
+				t.Errorf("services = %v, want %v", order, tt.order)
+			}
+		})
+	}
+}
+
+func TestPrintBuildLogMarksLatest(t *testing.T) {
+	groups := groupBuildLog([]contentful.BuildLogEntry{
+		logEntry("github-sync", "2026-03-05T10:00:00Z", "error"),
+		logEntry("github-sync", "2026-03-01T10:00:00Z", "success"),
+	})
+	var buf bytes.Buffer
+	printBuildLog(&buf, groups)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || lines[0] != "github-sync (latest: error at 2026-03-05T10:00:00Z)" {
+		t.Fatalf("output = %q", buf.String())
+	}
+	if !strings.HasPrefix(lines[1], "    2026-03-01") || !strings.HasPrefix(lines[2], "  * 2026-03-05") {
+		t.Errorf("latest entry not marked: %q", buf.String())
+	}
+}
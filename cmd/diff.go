@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/config"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/github"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/httpclient"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/syncer"
+	"github.com/spf13/cobra"
+)
+
+var diffAgainstVersion int
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Dry-run the sync against a past version of the projects entry",
+	Long: "Runs the full pipeline like sync --dry-run, but diffs the primary target against " +
+		"the published snapshot of its projects entry at --against-version instead of its " +
+		"current content. Nothing is written.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if diffAgainstVersion <= 0 {
+			return fmt.Errorf("--against-version must be a positive entry version")
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+		defer cancel()
+
+		httpClient := httpclient.New(cfg.HTTPTimeout)
+		targets, _ := syncTargets(cfg, httpClient)
+		s := syncer.New(cfg, github.NewClient(cfg.GitHubToken, httpClient), targets, httpClient)
+
+		stats, err := s.Run(ctx, syncer.RunOptions{DryRun: true, AgainstVersion: diffAgainstVersion})
+		if err != nil {
+			return fmt.Errorf("diff: %w", err)
+		}
+		printDryRun(cmd.OutOrStdout(), stats)
+		return nil
+	},
+}
+
+func init() {
+	diffCmd.Flags().IntVar(&diffAgainstVersion, "against-version", 0, "Published version of the projects entry to diff against")
+	rootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/config"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/enricher"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/httpclient"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/mapper"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/transform"
+	"github.com/spf13/cobra"
+)
+
+var (
+	enrichName       string
+	enrichReadmeFile string
+	enrichLanguages  string
+	enrichRawFile    string
+	enrichPrompt     bool
+)
+
+var enrichCmd = &cobra.Command{
+	Use:   "enrich",
+	Short: "Run the Gemini enricher on a single synthetic project",
+	Long: "Runs only the enricher against a local README and prints the resulting project JSON. " +
+		"No GitHub or Contentful calls are made. Use --readme-file - to read the README from stdin, " +
+		"or --raw-file to enrich every project in a file written by sync --dump-raw.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadGemini()
+		if err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+
+		var rawProjects []mapper.RawProject
+		if enrichRawFile != "" {
+			rawProjects, err = readRawFile(enrichRawFile)
+			if err != nil {
+				return err
+			}
+		} else {
+			if enrichName == "" {
+				return fmt.Errorf("--name or --raw-file is required")
+			}
+			raw, err := syntheticProject(cmd, cfg)
+			if err != nil {
+				return err
+			}
+			rawProjects = []mapper.RawProject{raw}
+		}
+
+		if enrichPrompt {
+			system, user := enricher.Prompts(rawProjects)
+			fmt.Fprintf(cmd.OutOrStdout(), "=== System prompt ===\n%s\n\n=== User prompt (%d repos) ===\n%s\n", system, len(rawProjects), user)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		projects, err := enricher.Enrich(ctx, enricher.Options{
+			APIKey:     cfg.GeminiAPIKey,
+			BaseURL:    cfg.GeminiBaseURL,
+			Model:      cfg.GeminiModel,
+			Seed:       cfg.RandomSeed,
+			HTTPClient: httpclient.New(cfg.HTTPTimeout),
+			StrictURLs: cfg.StrictURLs,
+			RetryOn:    cfg.GeminiRetryOn,
+
+			LongDescFromSection: cfg.LongDescSource == "readme-section",
+		}, rawProjects)
+		if err != nil {
+			return fmt.Errorf("enrich: %w", err)
+		}
+		if len(projects) == 0 {
+			return fmt.Errorf("enrich: Gemini returned no project")
+		}
+		projects = transform.FilterTechnologies(projects, cfg.TechAliases, cfg.TechDenylist, cfg.TechAllowlist, cfg.MaxTechnologies)
+
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		if enrichRawFile != "" {
+			return enc.Encode(projects)
+		}
+		return enc.Encode(projects[0])
+	},
+}
+
+func init() {
+	enrichCmd.Flags().StringVar(&enrichName, "name", "", "Repository name to enrich")
+	enrichCmd.Flags().StringVar(&enrichReadmeFile, "readme-file", "", "Path to a README file (- for stdin)")
+	enrichCmd.Flags().StringVar(&enrichLanguages, "languages", "", "Comma-separated languages, most used first")
+	enrichCmd.Flags().StringVar(&enrichRawFile, "raw-file", "", "Enrich the raw projects in a file written by sync --dump-raw")
+	enrichCmd.Flags().BoolVar(&enrichPrompt, "print-prompt", false, "Print the Gemini prompts and exit without calling Gemini")
+	rootCmd.AddCommand(enrichCmd)
+}
+
+// syntheticProject builds a single raw project from the --name, --readme-file
+// and --languages flags.
+func syntheticProject(cmd *cobra.Command, cfg *config.Config) (mapper.RawProject, error) {
+	readme, err := readReadme(cmd, enrichReadmeFile)
+	if err != nil {
+		return mapper.RawProject{}, err
+	}
+
+	var languages []string
+	for _, l := range strings.Split(enrichLanguages, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			languages = append(languages, l)
+		}
+	}
+
+	return mapper.RawProject{
+		Name:      enrichName,
+		Slug:      enrichName,
+		Languages: languages,
+		ReadmeRaw: readme,
+		Overview:  mapper.ExtractSection(readme, cfg.ReadmeSection),
+		PushedAt:  time.Now(),
+	}, nil
+}
+
+func readRawFile(path string) ([]mapper.RawProject, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read raw file: %w", err)
+	}
+	var projects []mapper.RawProject
+	if err := json.Unmarshal(data, &projects); err != nil {
+		return nil, fmt.Errorf("parse raw file: %w", err)
+	}
+	return projects, nil
+}
+
+func readReadme(cmd *cobra.Command, path string) (string, error) {
+	switch path {
+	case "":
+		return "", nil
+	case "-":
+		b, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return "", fmt.Errorf("read stdin: %w", err)
+		}
+		return string(b), nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read readme: %w", err)
+	}
+	return string(b), nil
+}
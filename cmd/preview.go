@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/config"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/github"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/httpclient"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/syncer"
+	"github.com/spf13/cobra"
+)
+
+var previewCmd = &cobra.Command{
+	Use:   "preview",
+	Short: "Compare GitHub repos with Contentful without enriching",
+	Long: "Lists which repos are new, kept, or dropped by MAX_PROJECTS, and which CMS projects " +
+		"no longer have a repo. No Gemini calls are made and nothing is written.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithoutGemini()
+		if err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		httpClient := httpclient.New(cfg.HTTPTimeout)
+		primary := cfg.Targets[0]
+		client := contentful.NewClient(primary.SpaceID, primary.Environment, primary.CMAToken, httpClient)
+		client.SetFieldNames(cfg.FieldNames)
+		target := syncer.Target{
+			Name:      primary.Name,
+			CMA:       client,
+			EntryID:   primary.EntryID,
+			SectionID: primary.SectionID,
+		}
+		s := syncer.New(cfg, github.NewClient(cfg.GitHubToken, httpClient), []syncer.Target{target}, httpClient)
+
+		rows, err := s.Preview(ctx)
+		if err != nil {
+			return fmt.Errorf("preview: %w", err)
+		}
+
+		w := cmd.OutOrStdout()
+		fmt.Fprintf(w, "%-40s %s\n", "SLUG", "STATUS")
+		for _, r := range rows {
+			fmt.Fprintf(w, "%-40s %s\n", r.Slug, r.Status)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(previewCmd)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/backup"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/config"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/httpclient"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rollbackFrom string
+	rollbackYes  bool
+)
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Restore the projects entry from a backup snapshot",
+	Long: "Reads a snapshot written via BACKUP_DIR and writes and publishes it to the " +
+		"configured projects entry. Asks for confirmation unless --yes is given.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if rollbackFrom == "" {
+			return fmt.Errorf("--from is required")
+		}
+
+		projects, err := backup.Read(rollbackFrom)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadContentful()
+		if err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		cmaClient := contentful.NewClient(cfg.SpaceID, cfg.Environment, cfg.CMAToken, httpclient.New(cfg.HTTPTimeout))
+		cmaClient.SetFieldNames(cfg.FieldNames)
+		entryID, err := cmaClient.ResolveEntryID(ctx, cfg.EntryID, cfg.SectionID)
+		if err != nil {
+			return err
+		}
+		result, err := cmaClient.GetProjects(ctx, entryID)
+		if err != nil {
+			return fmt.Errorf("get projects: %w", err)
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Entry %s currently has %d projects; %s has %d.\n",
+			result.EntryID, len(result.Projects), rollbackFrom, len(projects))
+		if !rollbackYes && !confirm(cmd, "Overwrite and publish?") {
+			return fmt.Errorf("rollback aborted")
+		}
+
+		newVersion, err := cmaClient.UpdateProjects(ctx, result, projects)
+		if err != nil {
+			return fmt.Errorf("update projects: %w", err)
+		}
+		cmaClient.WaitBeforePublish(ctx, cfg.PublishDelay)
+		if err := cmaClient.PublishEntry(ctx, result.EntryID, newVersion); err != nil {
+			return fmt.Errorf("publish: %w", err)
+		}
+
+		log.Printf("Rolled back %s to %s (%d projects)", result.EntryID, rollbackFrom, len(projects))
+		return nil
+	},
+}
+
+func init() {
+	rollbackCmd.Flags().StringVar(&rollbackFrom, "from", "", "Backup snapshot file to restore")
+	rollbackCmd.Flags().BoolVar(&rollbackYes, "yes", false, "Skip the confirmation prompt")
+	rootCmd.AddCommand(rollbackCmd)
+}
+
+// confirm asks a yes/no question on the command's stdin; only "y" or "yes" confirm.
+func confirm(cmd *cobra.Command, question string) bool {
+	fmt.Fprintf(cmd.OutOrStdout(), "%s [y/N] ", question)
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/config"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of a synced project",
+	Long: "Prints a JSON Schema for the project objects written to the content field, " +
+		"generated from the Go type. No configuration is needed; field renames set by " +
+		"CONTENTFUL_SHORT_DESCRIPTION_FIELD and CONTENTFUL_LONG_DESCRIPTION_FIELD are applied.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := config.LoadFieldNames()
+		if err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(contentful.ProjectSchema(names))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -2,25 +2,56 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
+	"sync"
 	"time"
 
-	servicekit "github.com/alberto-moreno-sa/go-service-kit/contentful"
-	githubapi "github.com/alberto-moreno-sa/go-service-kit/github"
 	"github.com/alberto-moreno-sa/github-cms-sync/internal/config"
 	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/enricher"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/github"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/httpclient"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/sqlite"
 	"github.com/alberto-moreno-sa/github-cms-sync/internal/syncer"
+	servicekit "github.com/alberto-moreno-sa/go-service-kit/contentful"
 	"github.com/spf13/cobra"
 )
 
-var forceFlag bool
+var (
+	forceFlag       bool
+	forceEnrichFlag bool
+	printPromptFlag bool
+	fillGapsFlag    bool
+	dumpRawFlag     string
+	dryRunFlag      bool
+	progressFlag    bool
+	stageFlag       string
+	concurrencyFlag int
+)
+
+// stages maps --stage values to the syncer stage the run stops after. "write"
+// is the full pipeline.
+var stages = map[string]string{
+	"fetch":     syncer.StageDetails,
+	"enrich":    syncer.StageEnrich,
+	"heuristic": syncer.StageHeuristic,
+	"write":     "",
+}
 
 var syncCmd = &cobra.Command{
 	Use:   "sync",
 	Short: "Sync GitHub projects to Contentful",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		stopAfter, ok := stages[stageFlag]
+		if stageFlag != "" && !ok {
+			return fmt.Errorf("--stage must be fetch, enrich, heuristic or write, got %q", stageFlag)
+		}
+
 		cfg, err := config.Load()
 		if err != nil {
 			return fmt.Errorf("config: %w", err)
@@ -29,96 +60,347 @@ var syncCmd = &cobra.Command{
 		if forceFlag {
 			cfg.ForceUpdate = true
 		}
+		if forceEnrichFlag {
+			cfg.ForceEnrich = true
+		}
+		if fillGapsFlag {
+			cfg.FillGaps = true
+		}
+		if err := cfg.ApplyConcurrency(concurrencyFlag); err != nil {
+			return fmt.Errorf("--concurrency: %w", err)
+		}
 
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
 		defer cancel()
 
 		// Initialize clients
-		ghClient := githubapi.NewClient(cfg.GitHubToken)
-		cmaClient := contentful.NewClient(cfg.SpaceID, cfg.CMAToken)
+		httpClient := httpclient.New(cfg.HTTPTimeout)
+		ghClient := github.NewClient(cfg.GitHubToken, httpClient)
+		targets, cmaClient := syncTargets(cfg, httpClient)
+
+		s := syncer.New(cfg, ghClient, targets, httpClient)
+
+		if printPromptFlag {
+			rawProjects, err := s.FetchRaw(ctx)
+			if err != nil {
+				return fmt.Errorf("sync: %w", err)
+			}
+			system, user := enricher.Prompts(rawProjects)
+			fmt.Fprintf(cmd.OutOrStdout(), "=== System prompt ===\n%s\n\n=== User prompt (%d repos) ===\n%s\n", system, len(rawProjects), user)
+			return nil
+		}
+
+		if dumpRawFlag != "" {
+			rawProjects, err := s.FetchRaw(ctx)
+			if err != nil {
+				return fmt.Errorf("sync: %w", err)
+			}
+			data, err := json.MarshalIndent(rawProjects, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshal raw projects: %w", err)
+			}
+			if err := os.WriteFile(dumpRawFlag, data, 0o644); err != nil {
+				return fmt.Errorf("write raw projects: %w", err)
+			}
+			log.Printf("Wrote %d raw projects to %s", len(rawProjects), dumpRawFlag)
+			return nil
+		}
+
+		// Rate-limit usage is informational only
+		rateBefore, rateErr := ghClient.GetRateLimit(ctx)
+		if rateErr != nil {
+			log.Printf("WARNING: read GitHub rate limit: %v", rateErr)
+		}
+
+		if cfg.SQLitePath != "" && !dryRunFlag {
+			sink, err := sqlite.Open(cfg.SQLitePath)
+			if err != nil {
+				return fmt.Errorf("sqlite: %w", err)
+			}
+			defer sink.Close()
+			s.SetSink(sink)
+		}
 
 		// Run sync
-		s := syncer.New(cfg, ghClient, cmaClient)
-		stats, err := s.Run(ctx)
+		opts := syncer.RunOptions{DryRun: dryRunFlag, StopAfter: stopAfter}
+		if progressFlag {
+			opts.OnEvent = progressReporter(cmd.ErrOrStderr())
+		}
+		stats, err := s.Run(ctx, opts)
 		if err != nil {
 			return fmt.Errorf("sync: %w", err)
 		}
 
+		var rateUsed int
+		if rateErr == nil {
+			rateUsed = rateLimitUsage(ctx, ghClient, rateBefore)
+		}
+
+		if stats.Status == "stopped" {
+			return printStage(cmd.OutOrStdout(), stopAfter, stats)
+		}
+
+		if dryRunFlag {
+			printDryRun(cmd.OutOrStdout(), stats)
+			return nil
+		}
+
 		log.Printf("Sync complete: %d projects (%d new)", stats.Total, stats.NewAdded)
+		if stats.FetchFailures > 0 {
+			log.Printf("  fetch failures: %d repos synced with missing languages or README", stats.FetchFailures)
+		}
+		if stats.Degraded > 0 {
+			log.Printf("  degraded: %d repos failed enrichment and kept their CMS copy", stats.Degraded)
+		}
+		if stats.Pruned > 0 {
+			log.Printf("  pruned: %d orphaned projects", stats.Pruned)
+		}
+		for _, ts := range stats.Sections {
+			if ts.Err == nil {
+				log.Printf("  %s: %d projects", ts.Name, ts.Total)
+			}
+		}
+		if stats.ArchiveTotal > 0 {
+			log.Printf("  archive: %d projects", stats.ArchiveTotal)
+		}
+		for _, ts := range stats.Targets {
+			if ts.Err != nil {
+				log.Printf("  %s: failed: %v", ts.Name, ts.Err)
+			} else {
+				log.Printf("  %s: %d projects (%d new)", ts.Name, ts.Total, ts.NewAdded)
+			}
+		}
 
 		// Record build log (non-fatal)
-		recordBuildLog(ctx, cmaClient, cfg, stats)
+		if result, err := recordBuildLog(ctx, cmaClient, cfg, stats, rateUsed); err != nil {
+			log.Printf("WARNING: %v", err)
+		} else {
+			log.Printf("Build log updated (%d total entries)", len(result.Entries))
+			if len(stats.AddedSlugs)+len(stats.RemovedSlugs)+len(stats.UpdatedSlugs) > 0 {
+				log.Printf("  added=%v removed=%v updated=%v", stats.AddedSlugs, stats.RemovedSlugs, stats.UpdatedSlugs)
+			}
+			log.Printf("  order churn: %d moved, %d positions", stats.Churn.Moved, stats.Churn.Distance)
+		}
 
 		return nil
 	},
 }
 
 func init() {
-	syncCmd.Flags().BoolVar(&forceFlag, "force", false, "Force update all projects")
+	syncCmd.Flags().BoolVar(&forceFlag, "force", false, "Write and publish every target even when nothing changed")
+	syncCmd.Flags().BoolVar(&forceEnrichFlag, "force-enrich", false, "Re-run enrichment even when SKIP_UNCHANGED would skip it; only changed targets are written")
+	syncCmd.Flags().BoolVar(&fillGapsFlag, "fill-gaps", false, "Only enrich repos that have no CMS project yet and merge them in")
+	syncCmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Run the pipeline and print what would change without writing to Contentful")
+	syncCmd.Flags().BoolVar(&progressFlag, "progress", false, "Print progress events to stderr")
+	syncCmd.Flags().IntVar(&concurrencyFlag, "concurrency", 0, "Fan-out for GitHub fetches and Gemini batches unless FETCH_CONCURRENCY or GEMINI_CONCURRENCY is set")
+	syncCmd.Flags().StringVar(&stageFlag, "stage", "", "Run up to this stage (fetch, enrich, heuristic or write), print its output and stop")
+	syncCmd.Flags().StringVar(&dumpRawFlag, "dump-raw", "", "Write the fetched raw projects to this file and exit without calling Gemini or Contentful")
+	syncCmd.Flags().BoolVar(&printPromptFlag, "print-prompt", false, "Print the Gemini prompts and exit without calling Gemini or Contentful")
 	rootCmd.AddCommand(syncCmd)
 }
 
-func recordBuildLog(ctx context.Context, cmaClient *contentful.Client, cfg *config.Config, stats *syncer.SyncStats) {
-	log.Println("Recording build log...")
+// syncTargets builds a syncer target for each configured Contentful space,
+// also returning the primary target's client for the build log.
+func syncTargets(cfg *config.Config, httpClient *http.Client) ([]syncer.Target, *contentful.Client) {
+	var targets []syncer.Target
+	var primary *contentful.Client
+	for _, t := range cfg.Targets {
+		client := contentful.NewClient(t.SpaceID, t.Environment, t.CMAToken, httpClient)
+		client.SetPublishRate(cfg.PublishRPS)
+		client.SetFieldNames(cfg.FieldNames)
+		client.SetWatermarkField(cfg.WatermarkField)
+		if primary == nil {
+			primary = client
+		}
+		targets = append(targets, syncer.Target{Name: t.Name, CMA: client, EntryID: t.EntryID, SectionID: t.SectionID})
+	}
+	return targets, primary
+}
 
-	const serviceName = "github-cms-sync"
-	triggeredBy := "local"
-	if os.Getenv("GITHUB_ACTIONS") == "true" {
-		triggeredBy = "github-actions"
+// pruneBuildLog splits one service's entries, oldest first, into the ones
+// kept alongside a new entry so that keep entries remain, and the older ones
+// dropped. A keep of zero drops nothing.
+func pruneBuildLog(entries []contentful.BuildLogEntry, keep int) (kept, pruned []contentful.BuildLogEntry) {
+	if keep <= 0 || len(entries) < keep {
+		return entries, nil
 	}
+	cut := len(entries) - (keep - 1)
+	return entries[cut:], entries[:cut]
+}
 
-	logEntry := servicekit.BuildLogEntry{
-		Service:         serviceName,
-		Timestamp:       time.Now().UTC().Format(time.RFC3339),
-		TriggeredBy:     triggeredBy,
-		ForceUpdate:     cfg.ForceUpdate,
-		TranslationUsed: false,
-		NewAdded:        stats.NewAdded,
-		TotalAfterSync:  stats.Total,
-		Status:          stats.Status,
+// mergeBuildLog appends entry to the shared log entries, pruning only this
+// service's own entries to keep. Other services' entries come first,
+// unchanged.
+func mergeBuildLog(entries []contentful.BuildLogEntry, entry contentful.BuildLogEntry, keep int) (merged, pruned []contentful.BuildLogEntry) {
+	var own, other []contentful.BuildLogEntry
+	for _, e := range entries {
+		if e.Service == syncer.ServiceName {
+			own = append(own, e)
+		} else {
+			other = append(other, e)
+		}
 	}
+	own, pruned = pruneBuildLog(own, keep)
+	return append(other, append(own, entry)...), pruned
+}
 
-	buildLogResult, err := cmaClient.GetBuildLog(ctx)
-	if err != nil {
-		log.Printf("WARNING: failed to fetch build log: %v", err)
-		return
+// progressReporter returns an event callback that prints one line per event.
+func progressReporter(w io.Writer) func(syncer.Event) {
+	var mu sync.Mutex
+	return func(e syncer.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch e.Kind {
+		case syncer.EventStageStarted:
+			fmt.Fprintf(w, "==> %s\n", e.Stage)
+		case syncer.EventRepoFetched:
+			fmt.Fprintf(w, "    fetched %s\n", e.Repo)
+		case syncer.EventEnrichBatchSent:
+			fmt.Fprintf(w, "    sent batch of %d to Gemini\n", e.Count)
+		case syncer.EventPublished:
+			fmt.Fprintf(w, "    published %d projects to %s\n", e.Count, e.Target)
+		}
 	}
+}
 
-	var ownEntries, otherEntries []servicekit.BuildLogEntry
-	for _, e := range buildLogResult.Entries {
-		if e.Service == serviceName {
-			ownEntries = append(ownEntries, e)
-		} else {
-			otherEntries = append(otherEntries, e)
+// printDryRun summarises what a dry run would write to each target.
+func printDryRun(w io.Writer, stats *syncer.SyncStats) {
+	for _, ts := range stats.Targets {
+		if ts.Err != nil {
+			fmt.Fprintf(w, "%s: failed: %v\n", ts.Name, ts.Err)
+			continue
+		}
+		fmt.Fprintf(w, "%s: %d projects (added %v, removed %v, updated %v; %d moved by %d positions)\n",
+			ts.Name, ts.Total, ts.Diff.Added, ts.Diff.Removed, ts.Diff.Updated, ts.Churn.Moved, ts.Churn.Distance)
+		for _, p := range ts.Projects {
+			marker := " "
+			if p.Featured {
+				marker = "*"
+			}
+			fmt.Fprintf(w, "  %s %-30s %-12s %s\n", marker, p.Name, p.Category, p.Slug)
 		}
 	}
-	if len(ownEntries) >= 3 {
-		ownEntries = ownEntries[len(ownEntries)-2:]
+}
+
+// printStage writes the output of the stage a --stage run stopped after as
+// indented JSON.
+func printStage(w io.Writer, stage string, stats *syncer.SyncStats) error {
+	var out interface{} = stats.Projects
+	if stage == syncer.StageDetails {
+		out = stats.Raw
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// rateLimitUsage logs and returns how much of the GitHub rate limit the run
+// spent since before was taken. It is zero when unknown.
+func rateLimitUsage(ctx context.Context, ghClient *github.Client, before github.RateLimit) int {
+	after, err := ghClient.GetRateLimit(ctx)
+	if err != nil {
+		log.Printf("WARNING: read GitHub rate limit: %v", err)
+		return 0
+	}
+	if !after.Reset.Equal(before.Reset) {
+		log.Printf("GitHub rate limit: window reset during the run, %d/%d remaining", after.Remaining, after.Limit)
+		return 0
+	}
+	used := after.Used(before)
+	log.Printf("GitHub rate limit: used %d, %d/%d remaining (resets %s)",
+		used, after.Remaining, after.Limit, after.Reset.UTC().Format(time.RFC3339))
+	return used
+}
+
+// newBuildLogEntry builds this run's build-log entry from stats and the
+// GitHub requests it used.
+func newBuildLogEntry(cfg *config.Config, stats *syncer.SyncStats, rateUsed int, now time.Time) contentful.BuildLogEntry {
+	triggeredBy := "local"
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		triggeredBy = "github-actions"
+	}
+
+	return contentful.BuildLogEntry{
+		BuildLogEntry: servicekit.BuildLogEntry{
+			Service:         syncer.ServiceName,
+			Timestamp:       now.UTC().Format(time.RFC3339),
+			TriggeredBy:     triggeredBy,
+			ForceUpdate:     cfg.ForceUpdate,
+			TranslationUsed: false,
+			NewAdded:        stats.NewAdded,
+			TotalAfterSync:  stats.Total,
+			Status:          stats.Status,
+		},
+		AddedSlugs:   stats.AddedSlugs,
+		RemovedSlugs: stats.RemovedSlugs,
+		UpdatedSlugs: stats.UpdatedSlugs,
+
+		OrderMoved:    stats.Churn.Moved,
+		OrderDistance: stats.Churn.Distance,
+
+		RateLimitUsed: rateUsed,
+		ConfigHash:    syncer.ConfigHash(cfg),
+
+		FeaturedScheme: stats.FeaturedScheme,
 	}
-	allLogEntries := append(otherEntries, append(ownEntries, logEntry)...)
+}
+
+// recordBuildLog appends an entry for stats and rateUsed to the shared build
+// log, keeping at most cfg.BuildLogKeep of this service's entries including
+// the new one, and publishes it. It returns the entry as written: its ID, new
+// version and full entry list.
+func recordBuildLog(ctx context.Context, cmaClient *contentful.Client, cfg *config.Config, stats *syncer.SyncStats, rateUsed int) (contentful.BuildLogResult, error) {
+	log.Println("Recording build log...")
+
+	logEntry := newBuildLogEntry(cfg, stats, rateUsed, time.Now())
 
 	var buildLogEntryID string
 	var buildLogVersion int
+	var allLogEntries, pruned []contentful.BuildLogEntry
 
-	if buildLogResult.EntryID == "" {
-		buildLogEntryID, buildLogVersion, err = cmaClient.CreateBuildLog(ctx, allLogEntries)
+	// Other services write to the same entry, so a conflict re-reads it and
+	// merges again before retrying.
+	err := contentful.RetryOnConflict(ctx, func(int) error {
+		buildLogResult, err := cmaClient.GetBuildLog(ctx)
 		if err != nil {
-			log.Printf("WARNING: failed to create build log: %v", err)
-			return
+			return fmt.Errorf("failed to fetch build log: %w", err)
+		}
+
+		allLogEntries, pruned = mergeBuildLog(buildLogResult.Entries, logEntry, cfg.BuildLogKeep)
+
+		if buildLogResult.EntryID == "" {
+			buildLogEntryID, buildLogVersion, err = cmaClient.CreateBuildLog(ctx, allLogEntries)
+			if err != nil {
+				return fmt.Errorf("failed to create build log: %w", err)
+			}
+			return nil
 		}
-	} else {
 		buildLogEntryID = buildLogResult.EntryID
 		buildLogVersion, err = cmaClient.UpdateBuildLog(ctx, buildLogResult, allLogEntries)
 		if err != nil {
-			log.Printf("WARNING: failed to update build log: %v", err)
-			return
+			return fmt.Errorf("failed to update build log: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		return contentful.BuildLogResult{}, err
 	}
 
 	if err := cmaClient.PublishEntry(ctx, buildLogEntryID, buildLogVersion); err != nil {
-		log.Printf("WARNING: failed to publish build log: %v", err)
-		return
+		return contentful.BuildLogResult{}, fmt.Errorf("failed to publish build log: %w", err)
+	}
+
+	// Pruned entries are already gone from the log, so a failed roll-up only
+	// loses them from the monthly totals.
+	if cfg.BuildLogSummarySectionID != "" && len(pruned) > 0 {
+		if err := cmaClient.CompactBuildLog(ctx, cfg.BuildLogSummarySectionID, pruned); err != nil {
+			log.Printf("WARNING: build log summary: %v", err)
+		}
 	}
 
-	log.Printf("Build log updated (%d total entries)", len(allLogEntries))
+	return contentful.BuildLogResult{
+		EntryID: buildLogEntryID,
+		Version: buildLogVersion,
+		Entries: allLogEntries,
+	}, nil
 }
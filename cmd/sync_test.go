@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/config"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/heuristic"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/mapper"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/syncer"
+)
+
+func TestNewBuildLogEntry(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	stats := &syncer.SyncStats{
+		Status:       "success",
+		NewAdded:     1,
+		Total:        3,
+		AddedSlugs:   []string{"new-app"},
+		RemovedSlugs: []string{"old-app"},
+		UpdatedSlugs: []string{"api", "cli"},
+		Churn:        heuristic.Churn{Moved: 2, Distance: 4},
+	}
+
+	entry := newBuildLogEntry(&config.Config{}, stats, 42, now)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded contentful.BuildLogEntry
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Service != syncer.ServiceName || decoded.Timestamp != "2025-06-01T12:00:00Z" || decoded.TotalAfterSync != 3 {
+		t.Errorf("base fields = %+v", decoded.BuildLogEntry)
+	}
+	if !reflect.DeepEqual(decoded.AddedSlugs, stats.AddedSlugs) ||
+		!reflect.DeepEqual(decoded.RemovedSlugs, stats.RemovedSlugs) ||
+		!reflect.DeepEqual(decoded.UpdatedSlugs, stats.UpdatedSlugs) {
+		t.Errorf("slugs = %v %v %v", decoded.AddedSlugs, decoded.RemovedSlugs, decoded.UpdatedSlugs)
+	}
+	if decoded.RateLimitUsed != 42 {
+		t.Errorf("RateLimitUsed = %d, want 42", decoded.RateLimitUsed)
+	}
+	if decoded.OrderMoved != 2 || decoded.OrderDistance != 4 {
+		t.Errorf("churn = %d moved, %d positions, want 2, 4", decoded.OrderMoved, decoded.OrderDistance)
+	}
+
+	empty, err := json.Marshal(newBuildLogEntry(&config.Config{}, &syncer.SyncStats{Status: "no-changes"}, 0, now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(empty), "Slugs") {
+		t.Errorf("empty slug arrays should be omitted: %s", empty)
+	}
+}
+
+func TestPruneBuildLog(t *testing.T) {
+	entries := func(n int) []contentful.BuildLogEntry {
+		out := make([]contentful.BuildLogEntry, n)
+		for i := range out {
+			out[i].Timestamp = time.Date(2025, 6, i+1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+		}
+		return out
+	}
+	days := func(list []contentful.BuildLogEntry) []int {
+		var out []int
+		for _, e := range list {
+			ts, _ := time.Parse(time.RFC3339, e.Timestamp)
+			out = append(out, ts.Day())
+		}
+		return out
+	}
+
+	tests := []struct {
+		name       string
+		existing   int
+		keep       int
+		wantKept   []int
+		wantPruned []int
+	}{
+		{name: "zero keeps everything", existing: 4, keep: 0, wantKept: []int{1, 2, 3, 4}},
+		{name: "one keeps only the new entry", existing: 4, keep: 1, wantPruned: []int{1, 2, 3, 4}},
+		{name: "one with an empty log", existing: 0, keep: 1},
+		{name: "three keeps the two newest", existing: 4, keep: 3, wantKept: []int{3, 4}, wantPruned: []int{1, 2}},
+		{name: "three with room to spare", existing: 2, keep: 3, wantKept: []int{1, 2}},
+		{name: "three when exactly full", existing: 3, keep: 3, wantKept: []int{2, 3}, wantPruned: []int{1}},
+		{name: "five keeps the four newest", existing: 6, keep: 5, wantKept: []int{3, 4, 5, 6}, wantPruned: []int{1, 2}},
+		{name: "five with fewer entries", existing: 3, keep: 5, wantKept: []int{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, pruned := pruneBuildLog(entries(tt.existing), tt.keep)
+			if got := days(kept); !reflect.DeepEqual(got, tt.wantKept) {
+				t.Errorf("kept = %v, want %v", got, tt.wantKept)
+			}
+			if got := days(pruned); !reflect.DeepEqual(got, tt.wantPruned) {
+				t.Errorf("pruned = %v, want %v", got, tt.wantPruned)
+			}
+		})
+	}
+}
+
+func TestMergeBuildLog(t *testing.T) {
+	entry := func(service, ts string) contentful.BuildLogEntry {
+		var e contentful.BuildLogEntry
+		e.Service, e.Timestamp = service, ts
+		return e
+	}
+	existing := []contentful.BuildLogEntry{
+		entry("other-sync", "o1"),
+		entry(syncer.ServiceName, "s1"),
+		entry("other-sync", "o2"),
+		entry(syncer.ServiceName, "s2"),
+		entry(syncer.ServiceName, "s3"),
+		entry("third", "t1"),
+	}
+
+	tests := []struct {
+		name       string
+		keep       int
+		wantMerged []string
+		wantPruned []string
+	}{
+		{name: "unlimited", keep: 0, wantMerged: []string{"o1", "o2", "t1", "s1", "s2", "s3", "new"}},
+		{name: "keep 1", keep: 1, wantMerged: []string{"o1", "o2", "t1", "new"}, wantPruned: []string{"s1", "s2", "s3"}},
+		{name: "keep 3", keep: 3, wantMerged: []string{"o1", "o2", "t1", "s2", "s3", "new"}, wantPruned: []string{"s1"}},
+		{name: "keep 5", keep: 5, wantMerged: []string{"o1", "o2", "t1", "s1", "s2", "s3", "new"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, pruned := mergeBuildLog(existing, entry(syncer.ServiceName, "new"), tt.keep)
+			stamps := func(list []contentful.BuildLogEntry) []string {
+				var out []string
+				for _, e := range list {
+					out = append(out, e.Timestamp)
+				}
+				return out
+			}
+			if got := stamps(merged); !reflect.DeepEqual(got, tt.wantMerged) {
+				t.Errorf("merged = %v, want %v", got, tt.wantMerged)
+			}
+			if got := stamps(pruned); !reflect.DeepEqual(got, tt.wantPruned) {
+				t.Errorf("pruned = %v, want %v", got, tt.wantPruned)
+			}
+		})
+	}
+}
+
+func TestPrintStage(t *testing.T) {
+	stats := &syncer.SyncStats{
+		Status:   "stopped",
+		Raw:      []mapper.RawProject{{Name: "api", Slug: "api"}},
+		Projects: []contentful.Project{{Slug: "api", Name: "API", Featured: true}},
+	}
+	tests := []struct {
+		flag     string
+		wantRaw  bool
+		wantName string
+	}{
+		{flag: "fetch", wantRaw: true},
+		{flag: "enrich", wantName: "API"},
+		{flag: "heuristic", wantName: "API"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.flag, func(t *testing.T) {
+			var buf strings.Builder
+			if err := printStage(&buf, stages[tt.flag], stats); err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantRaw {
+				var raw []mapper.RawProject
+				if err := json.Unmarshal([]byte(buf.String()), &raw); err != nil || len(raw) != 1 || raw[0].Slug != "api" {
+					t.Errorf("output = %s, want the raw projects", buf.String())
+				}
+				return
+			}
+			var projects []contentful.Project
+			if err := json.Unmarshal([]byte(buf.String()), &projects); err != nil || len(projects) != 1 || projects[0].Name != tt.wantName {
+				t.Errorf("output = %s, want the projects", buf.String())
+			}
+		})
+	}
+}
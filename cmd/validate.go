@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/config"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/enricher"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/github"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/httpclient"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check configuration and connectivity before a sync",
+	Long: "Loads the configuration and makes one lightweight authenticated call to GitHub, " +
+		"each Contentful target and Gemini, printing PASS or FAIL for each. Exits non-zero " +
+		"if any check fails.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		w := cmd.OutOrStdout()
+
+		cfg, err := config.Load()
+		if err != nil {
+			printCheck(w, "config", err)
+			return fmt.Errorf("validation failed")
+		}
+		printCheck(w, "config", nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		httpClient := httpclient.New(cfg.HTTPTimeout)
+		failed := 0
+
+		name := "github"
+		login, err := github.NewClient(cfg.GitHubToken, httpClient).GetAuthenticatedUser(ctx)
+		if err != nil {
+			err = explain(err, map[string]string{
+				"(401)": "GITHUB_TOKEN is invalid or expired",
+				"(403)": "GITHUB_TOKEN is rate limited or lacks access",
+			})
+		} else {
+			name += " (as " + login + ")"
+		}
+		if printCheck(w, name, err) {
+			failed++
+		}
+
+		for i, t := range cfg.Targets {
+			prefix := "CONTENTFUL_"
+			if i > 0 {
+				prefix += strings.ToUpper(t.Name) + "_"
+			}
+			client := contentful.NewClient(t.SpaceID, t.Environment, t.CMAToken, httpClient)
+			err := checkTarget(ctx, client, t)
+			if err != nil {
+				err = explain(err, map[string]string{
+					"(401)": fmt.Sprintf("%sCMA_TOKEN is invalid or expired", prefix),
+					"(403)": fmt.Sprintf("%sCMA_TOKEN lacks access to space %s", prefix, t.SpaceID),
+					"(404)": fmt.Sprintf("space %s, environment %s or the projects entry does not exist; check %sSPACE_ID and %sENTRY_ID", t.SpaceID, t.Environment, prefix, prefix),
+				})
+			}
+			if printCheck(w, "contentful ["+t.Name+"]", err) {
+				failed++
+			}
+		}
+
+		err = enricher.Ping(ctx, enricher.Options{
+			APIKey:     cfg.GeminiAPIKey,
+			BaseURL:    cfg.GeminiBaseURL,
+			Model:      cfg.GeminiModel,
+			HTTPClient: httpClient,
+		})
+		if err != nil {
+			err = explain(err, map[string]string{
+				"(400)": "GEMINI_API_KEY is invalid",
+				"(403)": "GEMINI_API_KEY lacks access to the Generative Language API",
+				"(404)": "GEMINI_MODEL does not exist",
+				"(429)": "GEMINI_API_KEY is over its quota",
+			})
+		}
+		if printCheck(w, "gemini", err) {
+			failed++
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("validation failed: %d checks failed", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
+
+// checkTarget fetches the target's projects entry.
+func checkTarget(ctx context.Context, client *contentful.Client, t config.Target) error {
+	entryID, err := client.ResolveEntryID(ctx, t.EntryID, t.SectionID)
+	if err != nil {
+		return err
+	}
+	_, err = client.GetEntry(ctx, entryID)
+	return err
+}
+
+// explain prefixes err with the hint for the first status marker its text
+// contains, keeping err as the detail.
+func explain(err error, hints map[string]string) error {
+	for marker, hint := range hints {
+		if strings.Contains(err.Error(), marker) {
+			return fmt.Errorf("%s: %w", hint, err)
+		}
+	}
+	return err
+}
+
+// printCheck writes one PASS or FAIL line and reports whether it failed.
+func printCheck(w io.Writer, name string, err error) bool {
+	if err != nil {
+		fmt.Fprintf(w, "FAIL  %-24s %v\n", name, err)
+		return true
+	}
+	fmt.Fprintf(w, "PASS  %s\n", name)
+	return false
+}
@@ -0,0 +1,47 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+// Write saves projects as indented JSON to dir/backup-<target>-<UTC timestamp>.json
+// and returns the file path. The directory is created if needed.
+func Write(dir, target string, projects []contentful.Project) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create backup dir: %w", err)
+	}
+
+	if projects == nil {
+		projects = []contentful.Project{}
+	}
+	data, err := json.MarshalIndent(projects, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal backup: %w", err)
+	}
+
+	name := fmt.Sprintf("backup-%s-%s.json", target, time.Now().UTC().Format("20060102T150405Z"))
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("write backup: %w", err)
+	}
+	return path, nil
+}
+
+// Read loads a projects snapshot written by Write.
+func Read(path string) ([]contentful.Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read backup: %w", err)
+	}
+	var projects []contentful.Project
+	if err := json.Unmarshal(data, &projects); err != nil {
+		return nil, fmt.Errorf("parse backup: %w", err)
+	}
+	return projects, nil
+}
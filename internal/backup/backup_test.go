@@ -0,0 +1,40 @@
+package backup
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+func TestWriteRead(t *testing.T) {
+	tests := []struct {
+		name     string
+		projects []contentful.Project
+		want     []contentful.Project
+	}{
+		{name: "projects", projects: []contentful.Project{{Slug: "api", Name: "API", Featured: true}}, want: []contentful.Project{{Slug: "api", Name: "API", Featured: true}}},
+		{name: "nil is saved as empty", want: []contentful.Project{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "nested", "backups")
+			path, err := Write(dir, "demo", tt.projects)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if filepath.Dir(path) != dir || !strings.HasPrefix(filepath.Base(path), "backup-demo-") {
+				t.Errorf("path = %q, want backup-demo-<timestamp>.json in %s", path, dir)
+			}
+			got, err := Read(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Read = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,10 +2,29 @@ package config
 
 import (
 	"fmt"
+	"log"
+	"net/url"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/enricher"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/transform"
 )
 
+// Target is one Contentful space/entry the synced projects are written to.
+type Target struct {
+	Name        string
+	SpaceID     string
+	Environment string
+	CMAToken    string
+	EntryID     string
+	SectionID   string
+}
+
 type Config struct {
 	GitHubUsername string
 	GitHubToken    string
@@ -14,48 +33,684 @@ type Config struct {
 	CMAToken string
 	EntryID  string
 
-	GeminiAPIKey string
+	// Environment is the Contentful environment to read and write;
+	// defaults to "master".
+	Environment string
+
+	// SectionID, when set, resolves the projects entry by its sectionId
+	// field instead of using EntryID, so one config fits every environment.
+	SectionID string
+
+	// ArchiveEntryID, when set, routes archived repos to this entry in the
+	// first target's space instead of dropping them.
+	ArchiveEntryID string
+
+	// BuildLogSummarySectionID, when set, rolls this service's pruned
+	// build-log entries into monthly totals kept in that siteSection.
+	BuildLogSummarySectionID string
+
+	// BuildLogKeep is how many of this service's build-log entries are
+	// retained, counting the new one; zero keeps them all.
+	BuildLogKeep int
+
+	// WriteStatsEntry writes aggregate portfolio numbers to the siteSection
+	// identified by StatsSectionID after each successful sync.
+	WriteStatsEntry bool
+	StatsSectionID  string
+
+	// FieldNames maps project fields to the names the Contentful content
+	// model stores them under, e.g. "shortDescription" to "summary". Only
+	// renamed fields are listed.
+	FieldNames map[string]string
+
+	// Targets lists every space to write to; the first is always the
+	// CONTENTFUL_SPACE_ID/CMA_TOKEN/ENTRY_ID triple above.
+	Targets []Target
+
+	GeminiAPIKey  string
+	GeminiBaseURL string
+	GeminiModel   string
+	StrictURLs    bool
+
+	GeminiBatchSize   int
+	GeminiConcurrency int
+
+	// MaxTechnologies caps each project's technologies after aliasing,
+	// de-duplication and the deny/allow lists; zero disables the cap.
+	MaxTechnologies int
+
+	// EnrichCachePath names a JSON file caching enrichment results per repo
+	// input; empty disables the cache.
+	EnrichCachePath string
+
+	// ReadmeSection names the README heading whose text seeds longDescription.
+	// LongDescSource is "model" (section is a hint) or "readme-section" (used as-is).
+	ReadmeSection  string
+	LongDescSource string
+
+	// CategoryRules lists plausible categories per dominant language.
+	CategoryRules enricher.CategoryRules
+
+	// GeminiRetryOn overrides which Gemini errors are retried.
+	GeminiRetryOn []string
+
+	IncludeTemplates bool
+
+	// ExcludePatterns drops repos whose name matches any of them.
+	ExcludePatterns []*regexp.Regexp
+
+	// RequireTopic, when set, keeps only repos tagged with this GitHub topic.
+	RequireTopic string
+
+	// IncludeRepos, when non-empty, is the only set of repos synced.
+	// ExcludeRepos always wins over it.
+	IncludeRepos []string
+	ExcludeRepos []string
+
+	// MinRepoSizeKB skips small repos, except those named in
+	// MinRepoSizeAllow.
+	MinRepoSizeKB    int
+	MinRepoSizeAllow []string
+
+	// MinStars skips little-starred repos, except those named in
+	// MinStarsAllow.
+	MinStars      int
+	MinStarsAllow []string
+
+	// Repos with no detected languages (docs or config only) are dropped
+	// when ExcludeLanguageless is set, and otherwise get these defaults.
+	ExcludeLanguageless    bool
+	LanguagelessCategory   string
+	LanguagelessTechnology string
+
+	// DescriptionTagRe extracts inline [key:value] tags from repo
+	// descriptions; nil disables tag parsing.
+	DescriptionTagRe *regexp.Regexp
+
+	// ReadmeMaxBytes caps each README download; ReadmeTimeout bounds it.
+	// Both apply before the prompt truncation in the enricher.
+	ReadmeMaxBytes int64
+	ReadmeTimeout  time.Duration
+
+	// ReadmeCandidates lists repo paths tried, in order, before GitHub's
+	// detected README.
+	ReadmeCandidates []string
+
+	// RepoConfigFile is the per-repo config file read for display
+	// switches, e.g. ".cms-sync.yml"; empty skips the lookup.
+	RepoConfigFile string
+
+	// FetchConcurrency is how many repos have their details fetched at once.
+	FetchConcurrency int
+
+	// fetchConcurrencySet and geminiConcurrencySet record whether
+	// FETCH_CONCURRENCY and GEMINI_CONCURRENCY were given, so
+	// ApplyConcurrency leaves them alone.
+	fetchConcurrencySet, geminiConcurrencySet bool
 
 	MaxFeatured int
 	MaxProjects int
+
+	// ForceUpdate writes and publishes every target even when its content
+	// is unchanged. ForceEnrich re-runs enrichment when SkipUnchanged would
+	// skip it, but still only writes targets whose content changed.
 	ForceUpdate bool
+	ForceEnrich bool
+
+	// FillGaps enriches only repos missing from the CMS and merges them
+	// into the existing projects.
+	FillGaps bool
+
+	// PublishOnPartial publishes degraded runs, where some repos failed
+	// enrichment and the rest were merged into the existing content. When
+	// false those writes are saved as unpublished changes.
+	PublishOnPartial bool
+
+	// WatermarkField names a projects-entry field set to the time of every
+	// write. With WatermarkOnlyNoPublish, a run whose content is unchanged
+	// saves the new watermark without publishing, so it triggers no
+	// rebuild; otherwise such runs write and publish it.
+	WatermarkField         string
+	WatermarkOnlyNoPublish bool
+
+	// PruneOrphans drops CMS projects kept by FillGaps or by a degraded
+	// merge whose repo no longer exists on GitHub.
+	PruneOrphans bool
+
+	// ManualOrder keeps the order editors set in Contentful for existing
+	// projects and appends new ones.
+	ManualOrder bool
+
+	// Strict turns suspicious-but-survivable outcomes, such as no repos
+	// left after filtering, into errors.
+	Strict bool
+
+	// NormalizeURLs trims URL fields and adds a missing scheme, clearing
+	// values that are still invalid (dropping the project under Strict).
+	NormalizeURLs bool
+
+	// StripMarkdown renders markdown in the description and highlight
+	// fields as plain text.
+	StripMarkdown bool
+
+	// SkipUnchanged exits early when no repo was pushed since the last
+	// successful sync.
+	SkipUnchanged bool
+
+	// PriorityOrder lists slugs that lead the project list in this exact order.
+	PriorityOrder []string
+
+	// FeaturedTopic marks repos carrying this GitHub topic as featured.
+	// They use up MaxFeatured slots unless FeaturedTopicOutsideBudget is set.
+	FeaturedTopic              string
+	FeaturedTopicOutsideBudget bool
+
+	// Projects featured in the CMS stay featured, so curation done in
+	// Contentful survives a sync. RecomputeFeatured turns that off and
+	// leaves featured to the heuristic alone.
+	RecomputeFeatured bool
+
+	// StaleAfter stops projects not pushed within this window from being
+	// featured. Zero disables it.
+	StaleAfter time.Duration
+
+	CaptureSourceCommit bool
+	ContentHash         bool
+
+	// DraftMaxAge marks repos created within this window that have no tags
+	// or releases as drafts. Zero disables draft detection.
+	DraftMaxAge time.Duration
+
+	FieldTransforms []transform.Rule
+
+	// DisplayDates overrides the displayDate shown for a slug, which
+	// otherwise follows PushedAt.
+	DisplayDates map[string]string
+
+	// HighlightsTotalMax caps the combined length of a project's highlights;
+	// zero disables it.
+	HighlightsTotalMax int
+	LockedFields       transform.Locks
+
+	// Locales lists the locales, besides contentful.DefaultLocale, the
+	// projects entry is translated into.
+	Locales []string
+
+	// TechGroups classifies technologies for the technologiesByGroup field;
+	// empty leaves the field unset.
+	TechGroups transform.Groups
+
+	// SectionRoutes writes projects to one or more category sections in
+	// the first target's space, in addition to the main entry. Unmapped
+	// categories go to SectionFallback, or nowhere when it is empty.
+	SectionRoutes   transform.SectionRoutes
+	SectionFallback string
+
+	// TechAliases maps technology nicknames to one canonical name before
+	// de-duplication.
+	TechAliases transform.Aliases
+
+	// TechDenylist drops technologies by name; a non-empty TechAllowlist
+	// keeps only the ones it lists. Both match case-insensitively.
+	TechDenylist  []string
+	TechAllowlist []string
+
+	PublishRPS float64
+
+	// PublishDelay is how long to wait between updating the projects entry
+	// and publishing it; zero publishes immediately.
+	PublishDelay time.Duration
+
+	// BackupDir, when set, receives a JSON snapshot of each target's
+	// current projects before they are overwritten.
+	BackupDir string
+
+	// SQLitePath, when set, is a local SQLite database that records the
+	// projects of every run, alongside Contentful.
+	SQLitePath string
+
+	HTTPTimeout time.Duration
+
+	// RandomSeed, when set, seeds every randomized component so runs with
+	// identical inputs produce identical output. Nil leaves them unseeded.
+	RandomSeed *int64
 }
 
 // Load reads configuration from environment variables.
 func Load() (*Config, error) {
+	return load(true)
+}
+
+// LoadWithoutGemini reads everything except the Gemini settings, for
+// commands that compare GitHub with Contentful without enriching.
+func LoadWithoutGemini() (*Config, error) {
+	return load(false)
+}
+
+func load(withGemini bool) (*Config, error) {
 	cfg := &Config{
 		GitHubUsername: os.Getenv("GITHUB_USERNAME"),
-		GitHubToken:   os.Getenv("GITHUB_TOKEN"),
-		SpaceID:       os.Getenv("CONTENTFUL_SPACE_ID"),
-		CMAToken:      os.Getenv("CONTENTFUL_CMA_TOKEN"),
-		EntryID:       os.Getenv("CONTENTFUL_ENTRY_ID"),
-		GeminiAPIKey:  os.Getenv("GEMINI_API_KEY"),
+		GitHubToken:    os.Getenv("GITHUB_TOKEN"),
 	}
 
 	if cfg.GitHubUsername == "" {
 		cfg.GitHubUsername = "alberto-moreno-sa"
 	}
 
-	if cfg.SpaceID == "" {
-		return nil, fmt.Errorf("CONTENTFUL_SPACE_ID is required")
+	if err := loadContentful(cfg); err != nil {
+		return nil, err
 	}
-	if cfg.CMAToken == "" {
-		return nil, fmt.Errorf("CONTENTFUL_CMA_TOKEN is required")
+	if withGemini {
+		if err := loadGemini(cfg); err != nil {
+			return nil, err
+		}
 	}
-	if cfg.EntryID == "" {
-		return nil, fmt.Errorf("CONTENTFUL_ENTRY_ID is required")
+
+	cfg.Targets = []Target{{Name: "default", SpaceID: cfg.SpaceID, Environment: cfg.Environment, CMAToken: cfg.CMAToken, EntryID: cfg.EntryID, SectionID: cfg.SectionID}}
+	extra, err := loadTargets(os.Getenv("CONTENTFUL_TARGETS"), cfg.Environment)
+	if err != nil {
+		return nil, err
 	}
-	if cfg.GeminiAPIKey == "" {
-		return nil, fmt.Errorf("GEMINI_API_KEY is required")
+	cfg.Targets = append(cfg.Targets, extra...)
+
+	cfg.IncludeTemplates = os.Getenv("INCLUDE_TEMPLATES") == "true"
+	cfg.MinRepoSizeKB = envInt("MIN_REPO_SIZE_KB", 0)
+	cfg.MinRepoSizeAllow = envList("MIN_REPO_SIZE_ALLOW")
+	cfg.MinStars = envInt("MIN_STARS", 0)
+	cfg.MinStarsAllow = envList("MIN_STARS_ALLOW")
+	for _, pattern := range envList("REPO_EXCLUDE_PATTERNS") {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("REPO_EXCLUDE_PATTERNS: %w", err)
+		}
+		cfg.ExcludePatterns = append(cfg.ExcludePatterns, re)
+	}
+	cfg.RequireTopic = strings.TrimSpace(os.Getenv("REQUIRE_TOPIC"))
+	cfg.IncludeRepos = envList("INCLUDE_REPOS")
+	cfg.ExcludeRepos = envList("EXCLUDE_REPOS")
+	cfg.ExcludeLanguageless = os.Getenv("EXCLUDE_LANGUAGELESS") == "true"
+	if v := os.Getenv("LANGUAGELESS_CATEGORY"); v != "" {
+		c, ok := enricher.CanonicalCategory(v)
+		if !ok {
+			return nil, fmt.Errorf("LANGUAGELESS_CATEGORY must be one of %s, got %q", strings.Join(enricher.AllowedCategories, ", "), v)
+		}
+		cfg.LanguagelessCategory = c
 	}
+	cfg.LanguagelessTechnology = os.Getenv("LANGUAGELESS_TECHNOLOGY")
+
+	if os.Getenv("DESCRIPTION_TAGS") == "true" {
+		pattern := os.Getenv("DESCRIPTION_TAG_PATTERN")
+		if pattern == "" {
+			pattern = `\[(\w+):([^\]]+)\]`
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("DESCRIPTION_TAG_PATTERN: %w", err)
+		}
+		if re.NumSubexp() < 2 {
+			return nil, fmt.Errorf("DESCRIPTION_TAG_PATTERN needs two groups (key and value)")
+		}
+		cfg.DescriptionTagRe = re
+	}
+
+	cfg.ReadmeMaxBytes = int64(envInt("README_FETCH_MAX_BYTES", 256*1024))
+	if cfg.ReadmeMaxBytes <= 0 {
+		return nil, fmt.Errorf("README_FETCH_MAX_BYTES must be positive, got %d", cfg.ReadmeMaxBytes)
+	}
+	cfg.ReadmeTimeout = envDuration("README_FETCH_TIMEOUT", 15*time.Second)
+	cfg.ReadmeCandidates = envList("README_CANDIDATES")
+	cfg.RepoConfigFile = os.Getenv("REPO_CONFIG_FILE")
+
+	cfg.FetchConcurrency = envInt("FETCH_CONCURRENCY", 5)
+	cfg.fetchConcurrencySet = os.Getenv("FETCH_CONCURRENCY") != ""
+	if cfg.FetchConcurrency < 1 {
+		return nil, fmt.Errorf("FETCH_CONCURRENCY must be at least 1, got %d", cfg.FetchConcurrency)
+	}
+	cfg.FetchConcurrency = clampFetchConcurrency("FETCH_CONCURRENCY", cfg.FetchConcurrency)
 
 	cfg.MaxFeatured = envInt("MAX_FEATURED", 5)
 	cfg.MaxProjects = envInt("MAX_PROJECTS", 15)
+	if err := validateLimits(cfg, os.Getenv("CONFIG_LENIENT") == "true"); err != nil {
+		return nil, err
+	}
 	cfg.ForceUpdate = os.Getenv("FORCE_UPDATE") == "true"
+	cfg.ForceEnrich = os.Getenv("FORCE_ENRICH") == "true"
+	cfg.PriorityOrder = envList("PRIORITY_ORDER")
+	cfg.FeaturedTopic = os.Getenv("FEATURED_TOPIC")
+	cfg.FeaturedTopicOutsideBudget = os.Getenv("FEATURED_TOPIC_OUTSIDE_BUDGET") == "true"
+	cfg.StaleAfter = envDuration("STALE_AFTER", 0)
+	cfg.RecomputeFeatured = os.Getenv("RECOMPUTE_FEATURED") == "true"
+	cfg.SkipUnchanged = os.Getenv("SKIP_UNCHANGED") == "true"
+	cfg.PruneOrphans = os.Getenv("PRUNE_ORPHANS") == "true"
+	cfg.NormalizeURLs = os.Getenv("NORMALIZE_URLS") == "true"
+	cfg.StripMarkdown = os.Getenv("STRIP_MARKDOWN_IN_FIELDS") == "true"
+	cfg.PublishOnPartial = os.Getenv("PUBLISH_ON_PARTIAL") != "false"
+	cfg.WatermarkField = os.Getenv("SYNC_WATERMARK_FIELD")
+	cfg.WatermarkOnlyNoPublish = os.Getenv("WATERMARK_ONLY_NO_PUBLISH") == "true"
+	if cfg.WatermarkOnlyNoPublish && cfg.WatermarkField == "" {
+		return nil, fmt.Errorf("WATERMARK_ONLY_NO_PUBLISH requires SYNC_WATERMARK_FIELD")
+	}
+	cfg.Strict = os.Getenv("STRICT") == "true"
+	cfg.ManualOrder = os.Getenv("ORDER_MODE") == "manual"
+	cfg.CaptureSourceCommit = os.Getenv("CAPTURE_SOURCE_COMMIT") == "true"
+	cfg.ContentHash = os.Getenv("CONTENT_HASH") == "true"
+	cfg.DraftMaxAge = envDuration("DRAFT_MAX_AGE", 0)
+	transforms, err := transform.Parse(os.Getenv("FIELD_TRANSFORMS"))
+	if err != nil {
+		return nil, fmt.Errorf("FIELD_TRANSFORMS: %w", err)
+	}
+	cfg.FieldTransforms = transforms
+	cfg.HighlightsTotalMax = envInt("HIGHLIGHTS_TOTAL_MAX", 0)
 
+	dates, err := transform.ParseDisplayDates(os.Getenv("DISPLAY_DATES"))
+	if err != nil {
+		return nil, fmt.Errorf("DISPLAY_DATES: %w", err)
+	}
+	cfg.DisplayDates = dates
+
+	if ref := os.Getenv("URL_REF_PARAM"); ref != "" {
+		if key, _, _ := strings.Cut(ref, "="); key == "" {
+			return nil, fmt.Errorf("URL_REF_PARAM: expected key=value, got %q", ref)
+		}
+		cfg.FieldTransforms = append(cfg.FieldTransforms,
+			transform.Rule{Field: "githubUrl", Op: "query", Arg: ref},
+			transform.Rule{Field: "liveUrl", Op: "query", Arg: ref})
+	}
+
+	locks, err := transform.ParseLocks(os.Getenv("LOCKED_FIELDS"))
+	if err != nil {
+		return nil, fmt.Errorf("LOCKED_FIELDS: %w", err)
+	}
+	cfg.LockedFields = locks
+
+	for _, locale := range envList("LOCALES") {
+		if locale != contentful.DefaultLocale {
+			cfg.Locales = append(cfg.Locales, locale)
+		}
+	}
+
+	groups, err := transform.ParseGroups(os.Getenv("TECH_GROUPS"))
+	if err != nil {
+		return nil, fmt.Errorf("TECH_GROUPS: %w", err)
+	}
+	cfg.TechGroups = groups
+	routes, err := transform.ParseSectionRoutes(os.Getenv("SECTION_ROUTES"))
+	if err != nil {
+		return nil, fmt.Errorf("SECTION_ROUTES: %w", err)
+	}
+	cfg.SectionRoutes = routes
+	cfg.SectionFallback = strings.TrimSpace(os.Getenv("SECTION_FALLBACK"))
+
+	aliases, err := transform.ParseAliases(os.Getenv("TECH_ALIASES"))
+	if err != nil {
+		return nil, fmt.Errorf("TECH_ALIASES: %w", err)
+	}
+	cfg.TechAliases = aliases
+	cfg.TechDenylist = envList("TECH_DENYLIST")
+	cfg.TechAllowlist = envList("TECH_ALLOWLIST")
+
+	return cfg, nil
+}
+
+// validateLimits rejects negative limits and a MaxFeatured larger than
+// MaxProjects. When lenient, an oversized MaxFeatured is clamped with a
+// warning instead.
+func validateLimits(cfg *Config, lenient bool) error {
+	if cfg.MaxFeatured < 0 {
+		return fmt.Errorf("MAX_FEATURED must be non-negative, got %d", cfg.MaxFeatured)
+	}
+	if cfg.MaxProjects < 0 {
+		return fmt.Errorf("MAX_PROJECTS must be non-negative, got %d", cfg.MaxProjects)
+	}
+	if cfg.MaxFeatured > cfg.MaxProjects {
+		if !lenient {
+			return fmt.Errorf("MAX_FEATURED (%d) must not exceed MAX_PROJECTS (%d)", cfg.MaxFeatured, cfg.MaxProjects)
+		}
+		log.Printf("WARNING: MAX_FEATURED (%d) exceeds MAX_PROJECTS (%d), clamping", cfg.MaxFeatured, cfg.MaxProjects)
+		cfg.MaxFeatured = cfg.MaxProjects
+	}
+	return nil
+}
+
+// LoadContentful reads only the Contentful settings, for commands that work
+// on CMS data without running the GitHub or Gemini stages.
+func LoadContentful() (*Config, error) {
+	cfg := &Config{}
+	if err := loadContentful(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func loadContentful(cfg *Config) error {
+	cfg.SpaceID = os.Getenv("CONTENTFUL_SPACE_ID")
+	cfg.CMAToken = os.Getenv("CONTENTFUL_CMA_TOKEN")
+	cfg.EntryID = os.Getenv("CONTENTFUL_ENTRY_ID")
+	cfg.SectionID = os.Getenv("CONTENTFUL_SECTION_ID")
+	cfg.Environment = os.Getenv("CONTENTFUL_ENVIRONMENT")
+	if cfg.Environment == "" {
+		cfg.Environment = "master"
+	}
+	cfg.ArchiveEntryID = os.Getenv("CONTENTFUL_ARCHIVE_ENTRY_ID")
+	cfg.BuildLogSummarySectionID = os.Getenv("CONTENTFUL_BUILD_LOG_SUMMARY_SECTION_ID")
+	cfg.BuildLogKeep = envInt("BUILD_LOG_KEEP", 3)
+	if cfg.BuildLogKeep < 0 {
+		return fmt.Errorf("BUILD_LOG_KEEP must not be negative, got %d", cfg.BuildLogKeep)
+	}
+	cfg.WriteStatsEntry = os.Getenv("WRITE_STATS_ENTRY") == "true"
+	cfg.StatsSectionID = os.Getenv("CONTENTFUL_STATS_SECTION_ID")
+	if cfg.StatsSectionID == "" {
+		cfg.StatsSectionID = "stats"
+	}
+	names, err := LoadFieldNames()
+	if err != nil {
+		return err
+	}
+	cfg.FieldNames = names
+
+	if cfg.SpaceID == "" {
+		return fmt.Errorf("CONTENTFUL_SPACE_ID is required")
+	}
+	if cfg.CMAToken == "" {
+		return fmt.Errorf("CONTENTFUL_CMA_TOKEN is required")
+	}
+	if cfg.EntryID == "" && cfg.SectionID == "" {
+		return fmt.Errorf("CONTENTFUL_ENTRY_ID or CONTENTFUL_SECTION_ID is required")
+	}
+
+	cfg.PublishRPS = envFloat("PUBLISH_RPS", 0)
+	if v := os.Getenv("PUBLISH_DELAY"); v != "" {
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil || d < 0 {
+			return fmt.Errorf("PUBLISH_DELAY must be a non-negative duration such as 2s, got %q", v)
+		}
+		cfg.PublishDelay = d
+	}
+	cfg.BackupDir = os.Getenv("BACKUP_DIR")
+	cfg.SQLitePath = os.Getenv("SQLITE_PATH")
+	cfg.HTTPTimeout = envDuration("HTTP_TIMEOUT", 60*time.Second)
+	return loadRandomSeed(cfg)
+}
+
+func loadRandomSeed(cfg *Config) error {
+	if v := os.Getenv("RANDOM_SEED"); v != "" {
+		seed, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return fmt.Errorf("RANDOM_SEED: %w", err)
+		}
+		cfg.RandomSeed = &seed
+	}
+	return nil
+}
+
+// LoadGemini reads only the Gemini settings, for commands that run the
+// enricher without touching GitHub or Contentful.
+func LoadGemini() (*Config, error) {
+	cfg := &Config{HTTPTimeout: envDuration("HTTP_TIMEOUT", 60*time.Second)}
+	if err := loadGemini(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadRandomSeed(cfg); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
+func loadGemini(cfg *Config) error {
+	cfg.GeminiAPIKey = os.Getenv("GEMINI_API_KEY")
+	if cfg.GeminiAPIKey == "" {
+		return fmt.Errorf("GEMINI_API_KEY is required")
+	}
+
+	cfg.StrictURLs = os.Getenv("STRICT_URLS") == "true"
+	cfg.GeminiBatchSize = envInt("GEMINI_BATCH_SIZE", 0)
+	cfg.MaxTechnologies = envInt("MAX_TECHNOLOGIES", 8)
+	if cfg.MaxTechnologies < 0 {
+		return fmt.Errorf("MAX_TECHNOLOGIES must not be negative, got %d", cfg.MaxTechnologies)
+	}
+	cfg.EnrichCachePath = os.Getenv("ENRICH_CACHE_PATH")
+	cfg.GeminiConcurrency = envInt("GEMINI_CONCURRENCY", 1)
+	cfg.geminiConcurrencySet = os.Getenv("GEMINI_CONCURRENCY") != ""
+	if cfg.GeminiConcurrency < 1 {
+		return fmt.Errorf("GEMINI_CONCURRENCY must be at least 1, got %d", cfg.GeminiConcurrency)
+	}
+
+	rules, err := enricher.ParseCategoryRules(os.Getenv("CATEGORY_RULES"))
+	if err != nil {
+		return fmt.Errorf("CATEGORY_RULES: %w", err)
+	}
+	cfg.CategoryRules = rules
+	cfg.GeminiRetryOn = envList("GEMINI_RETRY_ON")
+
+	cfg.ReadmeSection = os.Getenv("README_SECTION")
+	cfg.LongDescSource = os.Getenv("LONGDESC_SOURCE")
+	if cfg.LongDescSource == "" {
+		cfg.LongDescSource = "model"
+	}
+	if cfg.LongDescSource != "model" && cfg.LongDescSource != "readme-section" {
+		return fmt.Errorf("LONGDESC_SOURCE must be model or readme-section, got %q", cfg.LongDescSource)
+	}
+	cfg.GeminiBaseURL = strings.TrimSuffix(os.Getenv("GEMINI_BASE_URL"), "/")
+	if cfg.GeminiBaseURL != "" {
+		u, err := url.Parse(cfg.GeminiBaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("GEMINI_BASE_URL must be an absolute http(s) URL, got %q", cfg.GeminiBaseURL)
+		}
+	}
+
+	if model := os.Getenv("GEMINI_MODEL"); model != "" {
+		cfg.GeminiModel = strings.TrimSpace(model)
+		if !modelNameRe.MatchString(cfg.GeminiModel) {
+			return fmt.Errorf("GEMINI_MODEL must be a model name like gemini-1.5-flash, got %q", model)
+		}
+	}
+	return nil
+}
+
+// ApplyConcurrency sets n as the fan-out for every stage whose own variable
+// (FETCH_CONCURRENCY, GEMINI_CONCURRENCY) was not given. Fetches are capped
+// at the same limit as FETCH_CONCURRENCY; Gemini takes n as is, as it does
+// GEMINI_CONCURRENCY. Zero changes nothing and a negative n is an error.
+func (c *Config) ApplyConcurrency(n int) error {
+	if n < 0 {
+		return fmt.Errorf("concurrency must not be negative, got %d", n)
+	}
+	if n == 0 {
+		return nil
+	}
+	if !c.fetchConcurrencySet {
+		c.FetchConcurrency = clampFetchConcurrency("--concurrency", n)
+	}
+	if !c.geminiConcurrencySet {
+		c.GeminiConcurrency = n
+	}
+	return nil
+}
+
+// ClearConcurrency zeroes the fan-out settings, which never change what is
+// synced, so fingerprints of the config ignore them.
+func (c *Config) ClearConcurrency() {
+	c.FetchConcurrency, c.GeminiConcurrency = 0, 0
+	c.fetchConcurrencySet, c.geminiConcurrencySet = false, false
+}
+
+// clampFetchConcurrency caps n at maxFetchConcurrency, warning under name.
+func clampFetchConcurrency(name string, n int) int {
+	if n > maxFetchConcurrency {
+		log.Printf("WARNING: %s %d is above %d, using %d", name, n, maxFetchConcurrency, maxFetchConcurrency)
+		return maxFetchConcurrency
+	}
+	return n
+}
+
+// LoadFieldNames reads the project field renames of the Contentful content
+// model from CONTENTFUL_SHORT_DESCRIPTION_FIELD and
+// CONTENTFUL_LONG_DESCRIPTION_FIELD. It needs no other configuration.
+func LoadFieldNames() (map[string]string, error) {
+	names := make(map[string]string)
+	for field, key := range map[string]string{
+		"shortDescription": "CONTENTFUL_SHORT_DESCRIPTION_FIELD",
+		"longDescription":  "CONTENTFUL_LONG_DESCRIPTION_FIELD",
+	} {
+		if name := strings.TrimSpace(os.Getenv(key)); name != "" && name != field {
+			names[field] = name
+		}
+	}
+	// Renames must not land on each other's field
+	if short, long := names["shortDescription"], names["longDescription"]; short == "longDescription" || long == "shortDescription" || (short != "" && short == long) {
+		return nil, fmt.Errorf("CONTENTFUL_SHORT_DESCRIPTION_FIELD and CONTENTFUL_LONG_DESCRIPTION_FIELD must name different fields, got %q and %q", short, long)
+	}
+	return names, nil
+}
+
+// maxFetchConcurrency keeps FETCH_CONCURRENCY below what GitHub's secondary
+// rate limits tolerate.
+const maxFetchConcurrency = 50
+
+var modelNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// loadTargets reads additional targets from a comma-separated list of names.
+// Each name N is configured via CONTENTFUL_N_SPACE_ID, CONTENTFUL_N_CMA_TOKEN
+// and CONTENTFUL_N_ENTRY_ID.
+func loadTargets(names, defaultEnvironment string) ([]Target, error) {
+	var targets []Target
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		prefix := "CONTENTFUL_" + strings.ToUpper(name) + "_"
+		t := Target{
+			Name:      name,
+			SpaceID:   os.Getenv(prefix + "SPACE_ID"),
+			CMAToken:  os.Getenv(prefix + "CMA_TOKEN"),
+			EntryID:   os.Getenv(prefix + "ENTRY_ID"),
+			SectionID: os.Getenv(prefix + "SECTION_ID"),
+
+			Environment: os.Getenv(prefix + "ENVIRONMENT"),
+		}
+		if t.Environment == "" {
+			t.Environment = defaultEnvironment
+		}
+		if t.SpaceID == "" || t.CMAToken == "" || (t.EntryID == "" && t.SectionID == "") {
+			return nil, fmt.Errorf("target %q: %sSPACE_ID, %sCMA_TOKEN and %sENTRY_ID (or %sSECTION_ID) are required", name, prefix, prefix, prefix, prefix)
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// envList splits a comma-separated variable into trimmed, non-empty values.
+func envList(key string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 func envInt(key string, defaultVal int) int {
 	if v := os.Getenv(key); v != "" {
 		if n, err := strconv.Atoi(v); err == nil {
@@ -64,3 +719,21 @@ func envInt(key string, defaultVal int) int {
 	}
 	return defaultVal
 }
+
+func envFloat(key string, defaultVal float64) float64 {
+	if v := os.Getenv(key); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return defaultVal
+}
+
+func envDuration(key string, defaultVal time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return defaultVal
+}
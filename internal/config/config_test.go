@@ -0,0 +1,440 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestApplyConcurrency(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        Config
+		n          int
+		wantErr    bool
+		wantFetch  int
+		wantGemini int
+	}{
+		{
+			name:      "zero changes nothing",
+			cfg:       Config{FetchConcurrency: 5, GeminiConcurrency: 1},
+			wantFetch: 5, wantGemini: 1,
+		},
+		{
+			name:      "negative is rejected",
+			cfg:       Config{FetchConcurrency: 5, GeminiConcurrency: 1},
+			n:         -2,
+			wantErr:   true,
+			wantFetch: 5, wantGemini: 1,
+		},
+		{
+			name:      "applies to every stage",
+			cfg:       Config{FetchConcurrency: 5, GeminiConcurrency: 1},
+			n:         8,
+			wantFetch: 8, wantGemini: 8,
+		},
+		{
+			name:      "fetches are capped, Gemini is not",
+			cfg:       Config{FetchConcurrency: 5, GeminiConcurrency: 1},
+			n:         80,
+			wantFetch: maxFetchConcurrency, wantGemini: 80,
+		},
+		{
+			name:      "FETCH_CONCURRENCY wins",
+			cfg:       Config{FetchConcurrency: 3, GeminiConcurrency: 1, fetchConcurrencySet: true},
+			n:         8,
+			wantFetch: 3, wantGemini: 8,
+		},
+		{
+			name:      "GEMINI_CONCURRENCY wins",
+			cfg:       Config{FetchConcurrency: 5, GeminiConcurrency: 2, geminiConcurrencySet: true},
+			n:         8,
+			wantFetch: 8, wantGemini: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			err := cfg.ApplyConcurrency(tt.n)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if cfg.FetchConcurrency != tt.wantFetch || cfg.GeminiConcurrency != tt.wantGemini {
+				t.Errorf("fetch, gemini = %d, %d, want %d, %d",
+					cfg.FetchConcurrency, cfg.GeminiConcurrency, tt.wantFetch, tt.wantGemini)
+			}
+		})
+	}
+}
+
+func TestLoadRecordsConcurrencyVariables(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "t")
+	t.Setenv("GITHUB_USERNAME", "octo")
+	t.Setenv("CONTENTFUL_SPACE_ID", "space")
+	t.Setenv("CONTENTFUL_CMA_TOKEN", "cma")
+	t.Setenv("CONTENTFUL_ENTRY_ID", "projects")
+	t.Setenv("FETCH_CONCURRENCY", "3")
+
+	cfg, err := LoadWithoutGemini()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ApplyConcurrency(8); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.FetchConcurrency != 3 || cfg.GeminiConcurrency != 8 {
+		t.Errorf("fetch, gemini = %d, %d, want 3, 8", cfg.FetchConcurrency, cfg.GeminiConcurrency)
+	}
+}
+
+func TestLoadTargets(t *testing.T) {
+	t.Setenv("CONTENTFUL_DEMO_SPACE_ID", "demo-space")
+	t.Setenv("CONTENTFUL_DEMO_CMA_TOKEN", "demo-token")
+	t.Setenv("CONTENTFUL_DEMO_ENTRY_ID", "demo-projects")
+	t.Setenv("CONTENTFUL_STAGING_SPACE_ID", "staging-space")
+	t.Setenv("CONTENTFUL_STAGING_CMA_TOKEN", "staging-token")
+	t.Setenv("CONTENTFUL_STAGING_SECTION_ID", "projects")
+	t.Setenv("CONTENTFUL_STAGING_ENVIRONMENT", "staging")
+	t.Setenv("CONTENTFUL_BROKEN_SPACE_ID", "broken-space")
+
+	tests := []struct {
+		name    string
+		names   string
+		want    []Target
+		wantErr bool
+	}{
+		{name: "none"},
+		{
+			name:  "two targets",
+			names: "demo, staging",
+			want: []Target{
+				{Name: "demo", SpaceID: "demo-space", CMAToken: "demo-token", EntryID: "demo-projects", Environment: "master"},
+				{Name: "staging", SpaceID: "staging-space", CMAToken: "staging-token", SectionID: "projects", Environment: "staging"},
+			},
+		},
+		{name: "incomplete target", names: "demo,broken", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadTargets(tt.names, "master")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadTargets(%q) = %+v, want %+v", tt.names, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadGeminiBaseURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "unset", value: "", want: ""},
+		{name: "https", value: "https://proxy.example.com/gemini", want: "https://proxy.example.com/gemini"},
+		{name: "trailing slash is trimmed", value: "http://localhost:8080/", want: "http://localhost:8080"},
+		{name: "other scheme", value: "ftp://proxy.example.com", wantErr: true},
+		{name: "relative", value: "proxy.example.com/gemini", wantErr: true},
+		{name: "no host", value: "https://", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GEMINI_API_KEY", "key")
+			t.Setenv("GEMINI_BASE_URL", tt.value)
+
+			var cfg Config
+			err := loadGemini(&cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.GeminiBaseURL != tt.want {
+				t.Errorf("GeminiBaseURL = %q, want %q", cfg.GeminiBaseURL, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateLimits(t *testing.T) {
+	tests := []struct {
+		name         string
+		featured     int
+		projects     int
+		lenient      bool
+		wantErr      bool
+		wantFeatured int
+	}{
+		{name: "featured below projects", featured: 5, projects: 15, wantFeatured: 5},
+		{name: "featured equals projects", featured: 10, projects: 10, wantFeatured: 10},
+		{name: "zeros", wantFeatured: 0},
+		{name: "featured above projects", featured: 20, projects: 10, wantErr: true},
+		{name: "featured above projects, lenient", featured: 20, projects: 10, lenient: true, wantFeatured: 10},
+		{name: "negative featured", featured: -1, projects: 10, wantErr: true},
+		{name: "negative featured, lenient", featured: -1, projects: 10, lenient: true, wantErr: true},
+		{name: "negative projects", featured: 0, projects: -5, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{MaxFeatured: tt.featured, MaxProjects: tt.projects}
+			err := validateLimits(cfg, tt.lenient)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.MaxFeatured != tt.wantFeatured {
+				t.Errorf("MaxFeatured = %d, want %d", cfg.MaxFeatured, tt.wantFeatured)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsFeaturedAboveProjects(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "t")
+	t.Setenv("GITHUB_USERNAME", "octo")
+	t.Setenv("CONTENTFUL_SPACE_ID", "space")
+	t.Setenv("CONTENTFUL_CMA_TOKEN", "cma")
+	t.Setenv("CONTENTFUL_ENTRY_ID", "projects")
+	t.Setenv("MAX_FEATURED", "20")
+	t.Setenv("MAX_PROJECTS", "10")
+
+	if _, err := LoadWithoutGemini(); err == nil {
+		t.Fatal("err = nil, want MAX_FEATURED above MAX_PROJECTS rejected")
+	}
+	t.Setenv("CONFIG_LENIENT", "true")
+	cfg, err := LoadWithoutGemini()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MaxFeatured != 10 {
+		t.Errorf("MaxFeatured = %d, want it clamped to 10", cfg.MaxFeatured)
+	}
+}
+
+func TestLoadExcludePatterns(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "unset"},
+		{name: "several", value: "^exp-, -archive$", want: []string{"^exp-", "-archive$"}},
+		{name: "invalid regex", value: "^exp-,[unclosed", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_TOKEN", "t")
+			t.Setenv("GITHUB_USERNAME", "octo")
+			t.Setenv("CONTENTFUL_SPACE_ID", "space")
+			t.Setenv("CONTENTFUL_CMA_TOKEN", "cma")
+			t.Setenv("CONTENTFUL_ENTRY_ID", "projects")
+			t.Setenv("REPO_EXCLUDE_PATTERNS", tt.value)
+
+			cfg, err := LoadWithoutGemini()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var got []string
+			for _, re := range cfg.ExcludePatterns {
+				got = append(got, re.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExcludePatterns = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadGeminiModel(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "unset uses the default", value: "", want: ""},
+		{name: "flash", value: "gemini-1.5-flash", want: "gemini-1.5-flash"},
+		{name: "pro with whitespace", value: " gemini-1.5-pro\n", want: "gemini-1.5-pro"},
+		{name: "blank", value: "   ", wantErr: true},
+		{name: "space inside", value: "gemini 1.5", wantErr: true},
+		{name: "path", value: "models/../gemini", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GEMINI_API_KEY", "key")
+			t.Setenv("GEMINI_MODEL", tt.value)
+
+			var cfg Config
+			err := loadGemini(&cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.GeminiModel != tt.want {
+				t.Errorf("GeminiModel = %q, want %q", cfg.GeminiModel, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadRepoLists(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "t")
+	t.Setenv("GITHUB_USERNAME", "octo")
+	t.Setenv("CONTENTFUL_SPACE_ID", "space")
+	t.Setenv("CONTENTFUL_CMA_TOKEN", "cma")
+	t.Setenv("CONTENTFUL_ENTRY_ID", "projects")
+	t.Setenv("INCLUDE_REPOS", "app, api ,,blog")
+	t.Setenv("EXCLUDE_REPOS", "dotfiles")
+	t.Setenv("MIN_REPO_SIZE_ALLOW", "tiny")
+	t.Setenv("MIN_STARS_ALLOW", "pinned, app")
+
+	cfg, err := LoadWithoutGemini()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"app", "api", "blog"}; !reflect.DeepEqual(cfg.IncludeRepos, want) {
+		t.Errorf("IncludeRepos = %q, want %q", cfg.IncludeRepos, want)
+	}
+	if want := []string{"dotfiles"}; !reflect.DeepEqual(cfg.ExcludeRepos, want) {
+		t.Errorf("ExcludeRepos = %q, want %q", cfg.ExcludeRepos, want)
+	}
+	if want := []string{"tiny"}; !reflect.DeepEqual(cfg.MinRepoSizeAllow, want) {
+		t.Errorf("MinRepoSizeAllow = %q, want %q", cfg.MinRepoSizeAllow, want)
+	}
+	if want := []string{"pinned", "app"}; !reflect.DeepEqual(cfg.MinStarsAllow, want) {
+		t.Errorf("MinStarsAllow = %q, want %q", cfg.MinStarsAllow, want)
+	}
+}
+
+func TestLoadRandomSeed(t *testing.T) {
+	seed := int64(42)
+	tests := []struct {
+		name    string
+		value   string
+		want    *int64
+		wantErr bool
+	}{
+		{name: "unset"},
+		{name: "seed", value: " 42 ", want: &seed},
+		{name: "not a number", value: "forty-two", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("RANDOM_SEED", tt.value)
+
+			var cfg Config
+			err := loadRandomSeed(&cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(cfg.RandomSeed, tt.want) {
+				t.Errorf("RandomSeed = %v, want %v", cfg.RandomSeed, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFetchConcurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{name: "default", value: "", want: 5},
+		{name: "set", value: "8", want: 8},
+		{name: "serial", value: "1", want: 1},
+		{name: "zero", value: "0", wantErr: true},
+		{name: "negative", value: "-3", wantErr: true},
+		{name: "absurd value is clamped", value: "1000", want: maxFetchConcurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_TOKEN", "t")
+			t.Setenv("GITHUB_USERNAME", "octo")
+			t.Setenv("CONTENTFUL_SPACE_ID", "space")
+			t.Setenv("CONTENTFUL_CMA_TOKEN", "cma")
+			t.Setenv("CONTENTFUL_ENTRY_ID", "projects")
+			t.Setenv("FETCH_CONCURRENCY", tt.value)
+
+			cfg, err := LoadWithoutGemini()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.FetchConcurrency != tt.want {
+				t.Errorf("FetchConcurrency = %d, want %d", cfg.FetchConcurrency, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPublishDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "default", value: "", want: 0},
+		{name: "seconds", value: "2s", want: 2 * time.Second},
+		{name: "milliseconds", value: "500ms", want: 500 * time.Millisecond},
+		{name: "invalid", value: "soon", wantErr: true},
+		{name: "missing unit", value: "2", wantErr: true},
+		{name: "negative", value: "-1s", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_TOKEN", "t")
+			t.Setenv("GITHUB_USERNAME", "octo")
+			t.Setenv("CONTENTFUL_SPACE_ID", "space")
+			t.Setenv("CONTENTFUL_CMA_TOKEN", "cma")
+			t.Setenv("CONTENTFUL_ENTRY_ID", "projects")
+			t.Setenv("PUBLISH_DELAY", tt.value)
+
+			cfg, err := LoadWithoutGemini()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.PublishDelay != tt.want {
+				t.Errorf("PublishDelay = %s, want %s", cfg.PublishDelay, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadLanguagelessCategory(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "unset", value: ""},
+		{name: "allowed", value: "DevOps", want: "DevOps"},
+		{name: "respelled", value: "game dev", want: "Game Dev"},
+		{name: "alias", value: "tooling", want: "Libraries"},
+		{name: "not allowed", value: "Documentation", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_TOKEN", "t")
+			t.Setenv("GITHUB_USERNAME", "octo")
+			t.Setenv("CONTENTFUL_SPACE_ID", "space")
+			t.Setenv("CONTENTFUL_CMA_TOKEN", "cma")
+			t.Setenv("CONTENTFUL_ENTRY_ID", "projects")
+			t.Setenv("LANGUAGELESS_CATEGORY", tt.value)
+
+			cfg, err := LoadWithoutGemini()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.LanguagelessCategory != tt.want {
+				t.Errorf("LanguagelessCategory = %q, want %q", cfg.LanguagelessCategory, tt.want)
+			}
+		})
+	}
+}
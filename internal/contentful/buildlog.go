@@ -0,0 +1,199 @@
+package contentful
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	servicekit "github.com/alberto-moreno-sa/go-service-kit/contentful"
+)
+
+// BuildLogEntry is a shared build-log entry. Fields beyond the SDK's are
+// this service's own and omitted when empty, so other readers can ignore
+// them.
+type BuildLogEntry struct {
+	servicekit.BuildLogEntry
+
+	// The slugs the run added, removed and updated in the first target.
+	AddedSlugs   []string `json:"addedSlugs,omitempty"`
+	RemovedSlugs []string `json:"removedSlugs,omitempty"`
+	UpdatedSlugs []string `json:"updatedSlugs,omitempty"`
+
+	// OrderMoved and OrderDistance are the first target's order churn: how
+	// many projects changed position and by how many positions in total.
+	OrderMoved    int `json:"orderMoved,omitempty"`
+	OrderDistance int `json:"orderDistance,omitempty"`
+
+	// RateLimitUsed is how many GitHub API requests the run spent.
+	RateLimitUsed int `json:"rateLimitUsed,omitempty"`
+
+	// ConfigHash fingerprints the settings the run used, so SKIP_UNCHANGED
+	// does not skip a run whose configuration changed.
+	ConfigHash string `json:"configHash,omitempty"`
+
+	// FeaturedScheme is the scheme the run's featured flags follow; see
+	// syncer.FeaturedScheme.
+	FeaturedScheme int `json:"featuredScheme,omitempty"`
+}
+
+// BuildLogResult holds the fetched build log along with the entry metadata
+// needed to update it.
+type BuildLogResult struct {
+	Entries   []BuildLogEntry
+	EntryID   string
+	Version   int
+	RawFields map[string]interface{}
+}
+
+// GetBuildLog fetches the buildLog entry from the client's environment. The
+// SDK's version always reads master.
+func (c *Client) GetBuildLog(ctx context.Context) (*BuildLogResult, error) {
+	params := url.Values{}
+	params.Set("content_type", "buildLog")
+	params.Set("limit", "1")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.entriesURL()+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("CMA build log query failed (%d): could not read body: %w", resp.StatusCode, err)
+		}
+		return nil, fmt.Errorf("CMA build log query failed (%d): %s", resp.StatusCode, string(body))
+	}
+
+	var result servicekit.EntriesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode build log response: %w", err)
+	}
+	if len(result.Items) == 0 {
+		return &BuildLogResult{}, nil
+	}
+
+	entry := result.Items[0]
+	buildLog := &BuildLogResult{
+		EntryID:   entry.Sys.ID,
+		Version:   entry.Sys.Version,
+		RawFields: entry.Fields,
+	}
+
+	localeMap, ok := entry.Fields["logInfo"].(map[string]interface{})
+	if !ok {
+		return buildLog, nil
+	}
+	rawContent, ok := localeMap[DefaultLocale]
+	if !ok {
+		for _, v := range localeMap {
+			rawContent = v
+			break
+		}
+	}
+
+	contentBytes, err := json.Marshal(rawContent)
+	if err != nil {
+		return nil, fmt.Errorf("marshal build log content: %w", err)
+	}
+	if err := json.Unmarshal(contentBytes, &buildLog.Entries); err != nil {
+		return nil, fmt.Errorf("unmarshal build log entries: %w", err)
+	}
+	return buildLog, nil
+}
+
+// UpdateBuildLog replaces the entries of the buildLog entry in result and
+// returns its new version.
+func (c *Client) UpdateBuildLog(ctx context.Context, result *BuildLogResult, entries []BuildLogEntry) (int, error) {
+	fields := make(map[string]interface{})
+	for k, v := range result.RawFields {
+		fields[k] = v
+	}
+	fields["logInfo"] = map[string]interface{}{DefaultLocale: entries}
+
+	bodyBytes, err := json.Marshal(map[string]interface{}{"fields": fields})
+	if err != nil {
+		return 0, fmt.Errorf("marshal build log body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", c.entriesURL()+"/"+result.EntryID, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Content-Type", "application/vnd.contentful.management.v1+json")
+	req.Header.Set("X-Contentful-Version", fmt.Sprintf("%d", result.Version))
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return 0, fmt.Errorf("CMA build log update failed (%d): could not read body: %w", resp.StatusCode, err)
+		}
+		return 0, &StatusError{Op: "build log update", Status: resp.StatusCode, Body: string(respBody)}
+	}
+
+	var updated servicekit.EntryItem
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		return 0, fmt.Errorf("decode build log update response: %w", err)
+	}
+	return updated.Sys.Version, nil
+}
+
+// CreateBuildLog creates the buildLog entry in the client's environment and
+// returns its ID and version.
+func (c *Client) CreateBuildLog(ctx context.Context, entries []BuildLogEntry) (string, int, error) {
+	body := map[string]interface{}{
+		"fields": map[string]interface{}{
+			"logInfo": map[string]interface{}{DefaultLocale: entries},
+		},
+	}
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return "", 0, fmt.Errorf("marshal build log body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.entriesURL(), bytes.NewReader(bodyBytes))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Content-Type", "application/vnd.contentful.management.v1+json")
+	req.Header.Set("X-Contentful-Content-Type", "buildLog")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 201 {
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", 0, fmt.Errorf("CMA build log create failed (%d): could not read body: %w", resp.StatusCode, err)
+		}
+		return "", 0, fmt.Errorf("CMA build log create failed (%d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var created servicekit.EntryItem
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", 0, fmt.Errorf("decode build log create response: %w", err)
+	}
+	return created.Sys.ID, created.Sys.Version, nil
+}
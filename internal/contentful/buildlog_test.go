@@ -0,0 +1,49 @@
+package contentful
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	servicekit "github.com/alberto-moreno-sa/go-service-kit/contentful"
+)
+
+func TestBuildLogUsesEnvironment(t *testing.T) {
+	var paths []string
+	c := NewClient("space", "staging", "token", &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.Method+" "+req.URL.Path)
+		switch req.Method {
+		case "GET":
+			return respond(200, `{"items":[{"sys":{"id":"log","version":4},"fields":{"logInfo":{"en-US":[{"service":"other","status":"success"}]}}}]}`), nil
+		case "POST":
+			return respond(201, `{"sys":{"id":"log","version":1}}`), nil
+		}
+		return respond(200, `{"sys":{"id":"log","version":5}}`), nil
+	})})
+	ctx := context.Background()
+
+	result, err := c.GetBuildLog(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Entries) != 1 || result.Entries[0].Service != "other" || result.Version != 4 {
+		t.Fatalf("GetBuildLog() = %+v", result)
+	}
+	entries := append(result.Entries, BuildLogEntry{BuildLogEntry: servicekit.BuildLogEntry{Service: "github-cms-sync"}})
+	if _, err := c.UpdateBuildLog(ctx, result, entries); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.CreateBuildLog(ctx, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range paths {
+		if !strings.Contains(p, "/environments/staging/") {
+			t.Errorf("request %s does not target the staging environment", p)
+		}
+	}
+	if len(paths) != 3 {
+		t.Errorf("got %d requests, want 3", len(paths))
+	}
+}
@@ -8,20 +8,252 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
+	"time"
 
 	servicekit "github.com/alberto-moreno-sa/go-service-kit/contentful"
 )
 
+const (
+	maxPublishRetries  = 3
+	maxConflictRetries = 3
+)
+
+// Retry delays are variables so tests can shorten them.
+var (
+	publishRetryDelay  = 2 * time.Second
+	conflictRetryDelay = 500 * time.Millisecond
+)
+
 // Client embeds the SDK client and adds project-specific methods.
 type Client struct {
 	*servicekit.Client
+
+	// Environment is the Contentful environment every entry request
+	// targets.
+	Environment string
+
+	publishLimiter *RateLimiter
+
+	// clock is the time source WaitBeforePublish sleeps on; see SetClock.
+	clock Clock
+
+	// fieldNames renames project fields in the CMS; see SetFieldNames.
+	fieldNames map[string]string
+
+	// watermarkField is set to the write time on every projects write;
+	// see SetWatermarkField.
+	watermarkField string
+
+	resolvedMu sync.Mutex
+	resolved   map[string]string
 }
 
 // NewClient creates a new Contentful client with SDK and project support.
-func NewClient(spaceID, token string) *Client {
-	return &Client{
-		Client: servicekit.NewClient(spaceID, token),
+// An empty environment means "master". A non-nil httpClient replaces the
+// SDK's default for every request.
+func NewClient(spaceID, environment, token string, httpClient *http.Client) *Client {
+	if environment == "" {
+		environment = "master"
+	}
+	c := &Client{
+		Client:      servicekit.NewClient(spaceID, token),
+		Environment: environment,
+	}
+	if httpClient != nil {
+		c.HTTPClient = httpClient
+	}
+	return c
+}
+
+// ResolveEntryID returns the projects entry ID to use. When sectionID is set
+// it wins over entryID: the entry is looked up by its sectionId field, so the
+// same config works in every environment, and the result is cached per
+// environment for the client's lifetime. Otherwise entryID is returned as-is.
+func (c *Client) ResolveEntryID(ctx context.Context, entryID, sectionID string) (string, error) {
+	if sectionID == "" {
+		return entryID, nil
 	}
+
+	key := c.Environment + "/" + sectionID
+	c.resolvedMu.Lock()
+	defer c.resolvedMu.Unlock()
+	if id, ok := c.resolved[key]; ok {
+		return id, nil
+	}
+
+	entry, err := c.findProjectsBySectionID(ctx, sectionID)
+	if err != nil {
+		return "", fmt.Errorf("resolve section %q: %w", sectionID, err)
+	}
+	if c.resolved == nil {
+		c.resolved = make(map[string]string)
+	}
+	c.resolved[key] = entry.Sys.ID
+	return entry.Sys.ID, nil
+}
+
+// SetWatermarkField makes every projects write also set the entry field
+// name to the time of the write, as RFC 3339 in DefaultLocale. An empty name
+// writes no watermark.
+func (c *Client) SetWatermarkField(name string) {
+	c.watermarkField = name
+}
+
+// SetClock replaces the time source WaitBeforePublish sleeps on, so tests
+// can assert delays without sleeping. A nil clock restores the system one.
+func (c *Client) SetClock(clock Clock) {
+	c.clock = clock
+}
+
+// SetPublishRate paces PublishEntry calls to at most rps per second across
+// all goroutines sharing this client. A non-positive rps disables pacing.
+func (c *Client) SetPublishRate(rps float64) {
+	c.publishLimiter = NewRateLimiter(rps)
+}
+
+// PublishEntry publishes an entry, waiting on the publish rate limiter and
+// retrying with backoff when Contentful answers 429.
+func (c *Client) PublishEntry(ctx context.Context, entryID string, version int) error {
+	var lastErr error
+	for attempt := 0; attempt <= maxPublishRetries; attempt++ {
+		if attempt > 0 {
+			backoff := publishRetryDelay * time.Duration(attempt)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if err := c.publishLimiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		err := c.publishEntry(ctx, entryID, version)
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+		if !hasStatus(err, http.StatusTooManyRequests) {
+			return err
+		}
+	}
+	return fmt.Errorf("publish after %d retries: %w", maxPublishRetries, lastErr)
+}
+
+// RetryOnConflict runs fn and, while it fails with a 409 version conflict,
+// runs it again after a short backoff, up to maxConflictRetries times. attempt
+// is zero on the first call; later attempts must re-fetch the entry so the
+// update carries its current version.
+func RetryOnConflict(ctx context.Context, fn func(attempt int) error) error {
+	var lastErr error
+	for attempt := 0; attempt <= maxConflictRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(conflictRetryDelay * time.Duration(attempt)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		err := fn(attempt)
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+		if !hasStatus(err, http.StatusConflict) {
+			return err
+		}
+	}
+	return fmt.Errorf("version conflict after %d retries: %w", maxConflictRetries, lastErr)
+}
+
+// Clock is the time source WaitBeforePublish sleeps on.
+type Clock interface {
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// WaitBeforePublish pauses for d between an update and its publish so
+// Contentful has persisted the new version. It returns early, without
+// error, when ctx is done; the publish then reports the cancellation.
+func (c *Client) WaitBeforePublish(ctx context.Context, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	var clock Clock = systemClock{}
+	if c.clock != nil {
+		clock = c.clock
+	}
+	select {
+	case <-clock.After(d):
+	case <-ctx.Done():
+	}
+}
+
+// GetEntry fetches a single entry from the client's environment.
+func (c *Client) GetEntry(ctx context.Context, entryID string) (*servicekit.EntryItem, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.entriesURL()+"/"+entryID, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("CMA get entry failed (%d): could not read body: %w", resp.StatusCode, err)
+		}
+		return nil, fmt.Errorf("CMA get entry failed (%d): %s", resp.StatusCode, string(body))
+	}
+
+	var entry servicekit.EntryItem
+	if err := json.NewDecoder(resp.Body).Decode(&entry); err != nil {
+		return nil, fmt.Errorf("decode entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// publishEntry makes a single publish request in the client's environment.
+func (c *Client) publishEntry(ctx context.Context, entryID string, version int) error {
+	req, err := http.NewRequestWithContext(ctx, "PUT", c.entriesURL()+"/"+entryID+"/published", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("X-Contentful-Version", fmt.Sprintf("%d", version))
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("CMA publish failed (%d): could not read body: %w", resp.StatusCode, err)
+		}
+		return &StatusError{Op: "publish", Status: resp.StatusCode, Body: string(body)}
+	}
+	return nil
+}
+
+// entriesURL is the entries collection endpoint of the client's environment.
+func (c *Client) entriesURL() string {
+	return fmt.Sprintf("%s/spaces/%s/environments/%s/entries", servicekit.CMABaseURL, c.SpaceID, c.Environment)
 }
 
 // GetProjects fetches the projects siteSection entry.
@@ -36,7 +268,11 @@ func (c *Client) GetProjects(ctx context.Context, entryID string) (*ProjectsResu
 			return nil, fmt.Errorf("get projects entry: %w", err)
 		}
 	}
+	return c.projectsResult(entry)
+}
 
+// projectsResult decodes the projects stored in entry's content field.
+func (c *Client) projectsResult(entry *servicekit.EntryItem) (*ProjectsResult, error) {
 	contentField, ok := entry.Fields["content"]
 	if !ok {
 		return &ProjectsResult{
@@ -55,26 +291,26 @@ func (c *Client) GetProjects(ctx context.Context, entryID string) (*ProjectsResu
 		}, fmt.Errorf("content field is not locale-wrapped")
 	}
 
-	rawContent, ok := localeMap["en-US"]
-	if !ok {
-		for _, v := range localeMap {
-			rawContent = v
-			break
+	localized := make(map[string][]Project, len(localeMap))
+	for locale, rawContent := range localeMap {
+		projects, err := c.decodeProjects(rawContent)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s projects: %w", locale, err)
 		}
+		localized[locale] = projects
 	}
 
-	contentBytes, err := json.Marshal(rawContent)
-	if err != nil {
-		return nil, fmt.Errorf("marshal content: %w", err)
-	}
-
-	var projects []Project
-	if err := json.Unmarshal(contentBytes, &projects); err != nil {
-		return nil, fmt.Errorf("unmarshal projects: %w", err)
+	projects, ok := localized[DefaultLocale]
+	if !ok {
+		for _, v := range localized {
+			projects = v
+			break
+		}
 	}
 
 	return &ProjectsResult{
 		Projects:  projects,
+		Localized: localized,
 		EntryID:   entry.Sys.ID,
 		Version:   entry.Sys.Version,
 		RawFields: entry.Fields,
@@ -82,17 +318,69 @@ func (c *Client) GetProjects(ctx context.Context, entryID string) (*ProjectsResu
 }
 
 // UpdateProjects updates the projects entry using the fetch-mutate-put pattern.
+// When another writer bumped the version in between, the entry is re-fetched
+// and the write retried; result is updated to the re-fetched version and
+// fields.
 func (c *Client) UpdateProjects(ctx context.Context, result *ProjectsResult, projects []Project) (int, error) {
-	endpoint := fmt.Sprintf("%s/spaces/%s/environments/master/entries/%s",
-		servicekit.CMABaseURL, c.SpaceID, result.EntryID)
+	return c.UpdateLocalizedProjects(ctx, result, map[string][]Project{DefaultLocale: projects})
+}
+
+// UpdateLocalizedProjects is UpdateProjects writing one project list per
+// locale. The content field is replaced, so locales left out are removed.
+func (c *Client) UpdateLocalizedProjects(ctx context.Context, result *ProjectsResult, byLocale map[string][]Project) (int, error) {
+	content := make(map[string]interface{}, len(byLocale))
+	for locale, projects := range byLocale {
+		encoded, err := c.encodeProjects(projects)
+		if err != nil {
+			return 0, fmt.Errorf("encode %s projects: %w", locale, err)
+		}
+		content[locale] = encoded
+	}
+
+	syncedAt := time.Now().UTC().Format(time.RFC3339)
+	var newVersion int
+	err := RetryOnConflict(ctx, func(attempt int) error {
+		if attempt > 0 {
+			entry, err := c.GetEntry(ctx, result.EntryID)
+			if err != nil {
+				return fmt.Errorf("refetch after conflict: %w", err)
+			}
+			result.Version = entry.Sys.Version
+			result.RawFields = entry.Fields
+		}
+
+		fields := result.RawFields
+		if c.watermarkField != "" {
+			fields = make(map[string]interface{}, len(result.RawFields)+1)
+			for k, v := range result.RawFields {
+				fields[k] = v
+			}
+			fields[c.watermarkField] = map[string]interface{}{DefaultLocale: syncedAt}
+		}
+
+		var err error
+		newVersion, err = c.updateLocalizedContent(ctx, result.EntryID, result.Version, fields, content)
+		return err
+	})
+	return newVersion, err
+}
+
+// updateContent PUTs rawFields with the content field replaced by content
+// under DefaultLocale and returns the entry's new version.
+func (c *Client) updateContent(ctx context.Context, entryID string, version int, rawFields map[string]interface{}, content interface{}) (int, error) {
+	return c.updateLocalizedContent(ctx, entryID, version, rawFields, map[string]interface{}{DefaultLocale: content})
+}
+
+// updateLocalizedContent PUTs rawFields with the content field replaced by
+// the given locale map and returns the entry's new version.
+func (c *Client) updateLocalizedContent(ctx context.Context, entryID string, version int, rawFields map[string]interface{}, content map[string]interface{}) (int, error) {
+	endpoint := c.entriesURL() + "/" + entryID
 
 	fields := make(map[string]interface{})
-	for k, v := range result.RawFields {
+	for k, v := range rawFields {
 		fields[k] = v
 	}
-	fields["content"] = map[string]interface{}{
-		"en-US": projects,
-	}
+	fields["content"] = content
 
 	body := map[string]interface{}{
 		"fields": fields,
@@ -109,7 +397,7 @@ func (c *Client) UpdateProjects(ctx context.Context, result *ProjectsResult, pro
 	}
 	req.Header.Set("Authorization", "Bearer "+c.Token)
 	req.Header.Set("Content-Type", "application/vnd.contentful.management.v1+json")
-	req.Header.Set("X-Contentful-Version", fmt.Sprintf("%d", result.Version))
+	req.Header.Set("X-Contentful-Version", fmt.Sprintf("%d", version))
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -122,7 +410,7 @@ func (c *Client) UpdateProjects(ctx context.Context, result *ProjectsResult, pro
 		if err != nil {
 			return 0, fmt.Errorf("CMA update failed (%d): could not read body: %w", resp.StatusCode, err)
 		}
-		return 0, fmt.Errorf("CMA update failed (%d): %s", resp.StatusCode, string(respBody))
+		return 0, &StatusError{Op: "update", Status: resp.StatusCode, Body: string(respBody)}
 	}
 
 	var updated servicekit.EntryItem
@@ -135,7 +423,7 @@ func (c *Client) UpdateProjects(ctx context.Context, result *ProjectsResult, pro
 
 // findProjectsBySectionID queries for a siteSection entry by sectionId field.
 func (c *Client) findProjectsBySectionID(ctx context.Context, sectionID string) (*servicekit.EntryItem, error) {
-	endpoint := fmt.Sprintf("%s/spaces/%s/environments/master/entries", servicekit.CMABaseURL, c.SpaceID)
+	endpoint := c.entriesURL()
 
 	params := url.Values{}
 	params.Set("content_type", "siteSection")
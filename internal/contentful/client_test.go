@@ -0,0 +1,314 @@
+package contentful
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// roundTripFunc stands in for the CMA, since the SDK base URL is fixed.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func respond(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func newTestClient(rt roundTripFunc) *Client {
+	return NewClient("space", "", "token", &http.Client{Transport: rt})
+}
+
+func TestPublishEntryRetries(t *testing.T) {
+	defer func(d time.Duration) { publishRetryDelay = d }(publishRetryDelay)
+	publishRetryDelay = time.Millisecond
+
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantCalls int
+		wantErr   bool
+	}{
+		{"success", []int{200}, 1, false},
+		{"retried on 429", []int{429, 429, 200}, 3, false},
+		{"gives up after retries", []int{429, 429, 429, 429}, 4, true},
+		{"other status not retried", []int{500}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			c := newTestClient(func(*http.Request) (*http.Response, error) {
+				status := tt.statuses[calls]
+				calls++
+				return respond(status, "{}"), nil
+			})
+			err := c.PublishEntry(context.Background(), "entry", 1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PublishEntry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestPublishEntryPacing(t *testing.T) {
+	c := newTestClient(func(*http.Request) (*http.Response, error) {
+		return respond(200, "{}"), nil
+	})
+	c.SetPublishRate(50)
+
+	// The limiter is shared, so concurrent publishers are paced together.
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.PublishEntry(context.Background(), "entry", 1); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	// The first publish uses the burst token; the other five wait 20ms each.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("6 publishes at 50/s took %v, want at least 100ms", elapsed)
+	}
+}
+
+func TestRetryOnConflict(t *testing.T) {
+	defer func(d time.Duration) { conflictRetryDelay = d }(conflictRetryDelay)
+	conflictRetryDelay = time.Millisecond
+
+	conflict := &StatusError{Op: "update", Status: http.StatusConflict}
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{"success", []error{nil}, 1, false},
+		{"conflict then success", []error{conflict, nil}, 2, false},
+		{"wrapped conflict is retried", []error{fmt.Errorf("update projects: %w", conflict), nil}, 2, false},
+		{"gives up after retries", []error{conflict, conflict, conflict, conflict}, 4, true},
+		{"other status not retried", []error{&StatusError{Op: "update", Status: 422}}, 1, true},
+		{"409 in an untyped message not retried", []error{errors.New("entry 409 not found")}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := RetryOnConflict(context.Background(), func(attempt int) error {
+				if attempt != calls {
+					t.Errorf("attempt = %d, want %d", attempt, calls)
+				}
+				calls++
+				return tt.errs[attempt]
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RetryOnConflict() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestUpdateProjectsRefetchesOnConflict(t *testing.T) {
+	defer func(d time.Duration) { conflictRetryDelay = d }(conflictRetryDelay)
+	conflictRetryDelay = time.Millisecond
+
+	var versions []string
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		if req.Method == "GET" {
+			return respond(200, `{"sys":{"id":"projects","version":7},"fields":{}}`), nil
+		}
+		versions = append(versions, req.Header.Get("X-Contentful-Version"))
+		if len(versions) == 1 {
+			return respond(409, "VersionMismatch"), nil
+		}
+		return respond(200, `{"sys":{"id":"projects","version":8}}`), nil
+	})
+
+	result := &ProjectsResult{EntryID: "projects", Version: 5}
+	version, err := c.UpdateProjects(context.Background(), result, []Project{{Slug: "api"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 8 || result.Version != 7 {
+		t.Errorf("version = %d, result.Version = %d, want 8 and 7", version, result.Version)
+	}
+	if want := []string{"5", "7"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("PUT versions = %v, want %v", versions, want)
+	}
+}
+
+func TestUpdateProjectsWatermark(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+	}{
+		{name: "no watermark"},
+		{name: "watermark", field: "lastSyncedAt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var put struct {
+				Fields map[string]map[string]interface{} `json:"fields"`
+			}
+			c := newTestClient(func(req *http.Request) (*http.Response, error) {
+				if err := json.NewDecoder(req.Body).Decode(&put); err != nil {
+					t.Fatal(err)
+				}
+				return respond(200, `{"sys":{"id":"projects","version":2}}`), nil
+			})
+			c.SetWatermarkField(tt.field)
+
+			result := &ProjectsResult{EntryID: "projects", Version: 1, RawFields: map[string]interface{}{
+				"title": map[string]interface{}{DefaultLocale: "Projects"},
+			}}
+			before := time.Now().Add(-time.Second)
+			if _, err := c.UpdateProjects(context.Background(), result, []Project{{Slug: "api"}}); err != nil {
+				t.Fatal(err)
+			}
+			if put.Fields["title"][DefaultLocale] != "Projects" {
+				t.Errorf("title = %v, want it kept", put.Fields["title"])
+			}
+			if len(result.RawFields) != 1 {
+				t.Errorf("RawFields = %v, want it untouched", result.RawFields)
+			}
+			if tt.field == "" {
+				if len(put.Fields) != 2 {
+					t.Errorf("fields = %v, want title and content only", put.Fields)
+				}
+				return
+			}
+			ts, err := time.Parse(time.RFC3339, fmt.Sprint(put.Fields[tt.field][DefaultLocale]))
+			if err != nil || ts.Before(before) {
+				t.Errorf("%s = %v, want the write time", tt.field, put.Fields[tt.field])
+			}
+		})
+	}
+}
+
+func TestNewClientUsesInjectedHTTPClient(t *testing.T) {
+	tests := []struct {
+		name     string
+		call     func(c *Client) error
+		wantPath string
+	}{
+		{
+			name: "get projects",
+			call: func(c *Client) error {
+				_, err := c.GetProjects(context.Background(), "projects")
+				return err
+			},
+			wantPath: "/spaces/space/environments/master/entries/projects",
+		},
+		{
+			name: "get build log",
+			call: func(c *Client) error {
+				_, err := c.GetBuildLog(context.Background())
+				return err
+			},
+			wantPath: "/spaces/space/environments/master/entries",
+		},
+		{
+			name: "publish",
+			call: func(c *Client) error {
+				return c.PublishEntry(context.Background(), "projects", 1)
+			},
+			wantPath: "/spaces/space/environments/master/entries/projects/published",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			c := newTestClient(func(req *http.Request) (*http.Response, error) {
+				paths = append(paths, req.URL.Path)
+				if got := req.Header.Get("Authorization"); got != "Bearer token" {
+					t.Errorf("Authorization = %q", got)
+				}
+				return respond(200, `{"sys":{"id":"projects","version":2},"fields":{},"items":[]}`), nil
+			})
+			tt.call(c)
+			if len(paths) != 1 || paths[0] != tt.wantPath {
+				t.Errorf("injected client saw %v, want one request to %s", paths, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestNewClientDefaultHTTPClient(t *testing.T) {
+	injected := &http.Client{}
+	if c := NewClient("space", "", "token", injected); c.HTTPClient != injected {
+		t.Error("HTTPClient is not the injected client")
+	}
+	if c := NewClient("space", "", "token", nil); c.HTTPClient == nil || c.HTTPClient == injected {
+		t.Errorf("HTTPClient = %v, want the SDK default", c.HTTPClient)
+	}
+}
+
+// fakeClock records requested delays and fires immediately unless blocked.
+type fakeClock struct {
+	waits   []time.Duration
+	blocked bool
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	ch := make(chan time.Time, 1)
+	if !c.blocked {
+		ch <- time.Time{}
+	}
+	return ch
+}
+
+func TestWaitBeforePublish(t *testing.T) {
+	tests := []struct {
+		name      string
+		delay     time.Duration
+		blocked   bool
+		cancel    bool
+		wantWaits []time.Duration
+	}{
+		{"zero delay skips the clock", 0, false, false, nil},
+		{"negative delay skips the clock", -time.Second, false, false, nil},
+		{"waits the configured delay", 3 * time.Second, false, false, []time.Duration{3 * time.Second}},
+		{"cancelled context returns early", time.Hour, true, true, []time.Duration{time.Hour}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClock{blocked: tt.blocked}
+			c := NewClient("space", "", "token", nil)
+			c.SetClock(fake)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.cancel {
+				cancel()
+			}
+			defer cancel()
+
+			c.WaitBeforePublish(ctx, tt.delay)
+			if !reflect.DeepEqual(fake.waits, tt.wantWaits) {
+				t.Errorf("waits = %v, want %v", fake.waits, tt.wantWaits)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package contentful
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// ProjectDiff lists the slugs that differ between two project lists.
+type ProjectDiff struct {
+	Added   []string
+	Removed []string
+	Updated []string
+}
+
+// Empty reports whether the diff has no changes.
+func (d ProjectDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Updated) == 0
+}
+
+// DiffProjects compares the current CMS projects with the next set by slug.
+// A project counts as updated when its content hash or featured flag changed.
+// Each slug list is sorted.
+func DiffProjects(previous, next []Project) ProjectDiff {
+	prev := make(map[string]Project, len(previous))
+	for _, p := range previous {
+		prev[p.Slug] = p
+	}
+
+	var d ProjectDiff
+	seen := make(map[string]bool, len(next))
+	for _, p := range next {
+		seen[p.Slug] = true
+		old, ok := prev[p.Slug]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, p.Slug)
+		case old.ComputeContentHash() != p.ComputeContentHash() || old.Featured != p.Featured:
+			d.Updated = append(d.Updated, p.Slug)
+		}
+	}
+	for _, p := range previous {
+		if !seen[p.Slug] {
+			d.Removed = append(d.Removed, p.Slug)
+		}
+	}
+
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Strings(d.Updated)
+	return d
+}
+
+// ProjectsEqual reports whether a and b serialize to the same CMS content,
+// so fields that are never written (PushedAt) are ignored. Nil and empty
+// lists are equal.
+func ProjectsEqual(a, b []Project) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	if len(a) == 0 {
+		return true
+	}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
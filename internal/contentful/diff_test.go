@@ -0,0 +1,52 @@
+package contentful
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProjectsEqual(t *testing.T) {
+	base := []Project{
+		{Slug: "api", Name: "API", Featured: true, Technologies: []string{"Go"}},
+		{Slug: "cli", Name: "CLI"},
+	}
+	clone := func() []Project {
+		out := make([]Project, len(base))
+		copy(out, base)
+		out[0].Technologies = []string{"Go"}
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		change func(p []Project) []Project
+		want   bool
+	}{
+		{name: "identical", change: func(p []Project) []Project { return p }, want: true},
+		{name: "pushedAt is ignored", change: func(p []Project) []Project {
+			p[0].PushedAt = time.Now()
+			return p
+		}, want: true},
+		{name: "field change", change: func(p []Project) []Project {
+			p[1].Name = "CLI tool"
+			return p
+		}},
+		{name: "featured flag", change: func(p []Project) []Project {
+			p[0].Featured = false
+			return p
+		}},
+		{name: "reordered", change: func(p []Project) []Project { return []Project{p[1], p[0]} }},
+		{name: "project removed", change: func(p []Project) []Project { return p[:1] }},
+		{name: "empty and nil", change: func(p []Project) []Project { return nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ProjectsEqual(base, tt.change(clone())); got != tt.want {
+				t.Errorf("ProjectsEqual = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if !ProjectsEqual(nil, []Project{}) {
+		t.Error("ProjectsEqual(nil, empty) = false, want true")
+	}
+}
@@ -0,0 +1,23 @@
+package contentful
+
+import (
+	"errors"
+	"fmt"
+)
+
+// StatusError is a CMA response with an unexpected status code.
+type StatusError struct {
+	Op     string
+	Status int
+	Body   string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("CMA %s failed (%d): %s", e.Op, e.Status, e.Body)
+}
+
+// hasStatus reports whether err is or wraps a StatusError with status.
+func hasStatus(err error, status int) bool {
+	var se *StatusError
+	return errors.As(err, &se) && se.Status == status
+}
@@ -0,0 +1,70 @@
+package contentful
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SetFieldNames stores project fields under other names in the CMS. names
+// maps a field's JSON name on Project, e.g. "shortDescription", to the name
+// the content model uses; fields not listed keep their own name.
+func (c *Client) SetFieldNames(names map[string]string) {
+	c.fieldNames = names
+}
+
+// encodeProjects returns projects as the CMS stores them, with fields
+// renamed per c.fieldNames.
+func (c *Client) encodeProjects(projects []Project) (interface{}, error) {
+	if len(c.fieldNames) == 0 {
+		return projects, nil
+	}
+	b, err := json.Marshal(projects)
+	if err != nil {
+		return nil, err
+	}
+	var raw []map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	for _, p := range raw {
+		renameKeys(p, c.fieldNames)
+	}
+	return raw, nil
+}
+
+// decodeProjects is the inverse of encodeProjects for one locale's raw
+// content.
+func (c *Client) decodeProjects(rawContent interface{}) ([]Project, error) {
+	if len(c.fieldNames) > 0 {
+		if items, ok := rawContent.([]interface{}); ok {
+			inverse := make(map[string]string, len(c.fieldNames))
+			for field, name := range c.fieldNames {
+				inverse[name] = field
+			}
+			for _, item := range items {
+				if p, ok := item.(map[string]interface{}); ok {
+					renameKeys(p, inverse)
+				}
+			}
+		}
+	}
+	b, err := json.Marshal(rawContent)
+	if err != nil {
+		return nil, fmt.Errorf("marshal content: %w", err)
+	}
+	var projects []Project
+	if err := json.Unmarshal(b, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// renameKeys moves each key of m listed in names to its new name.
+func renameKeys(m map[string]interface{}, names map[string]string) {
+	for from, to := range names {
+		if v, ok := m[from]; ok && from != to {
+			delete(m, from)
+			m[to] = v
+		}
+	}
+}
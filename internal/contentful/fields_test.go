@@ -0,0 +1,85 @@
+package contentful
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestFieldNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		names    map[string]string
+		stored   string
+		wantKeys []string
+	}{
+		{
+			name:     "default names",
+			stored:   `{"slug":"api","shortDescription":"Short","longDescription":"Long"}`,
+			wantKeys: []string{"shortDescription", "longDescription"},
+		},
+		{
+			name:     "renamed",
+			names:    map[string]string{"shortDescription": "summary", "longDescription": "description"},
+			stored:   `{"slug":"api","summary":"Short","description":"Long"}`,
+			wantKeys: []string{"summary", "description"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var put map[string]interface{}
+			c := newTestClient(func(req *http.Request) (*http.Response, error) {
+				if req.Method == "PUT" {
+					body, _ := io.ReadAll(req.Body)
+					if err := json.Unmarshal(body, &put); err != nil {
+						t.Fatal(err)
+					}
+					return respond(200, `{"sys":{"id":"projects","version":2}}`), nil
+				}
+				return respond(200, `{"sys":{"id":"projects","version":1},"fields":{"content":{"en-US":[`+tt.stored+`]}}}`), nil
+			})
+			c.SetFieldNames(tt.names)
+
+			result, err := c.GetProjects(context.Background(), "projects")
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []Project{{Slug: "api", ShortDescription: "Short", LongDescription: "Long"}}
+			if !reflect.DeepEqual(result.Projects, want) {
+				t.Fatalf("projects = %+v, want %+v", result.Projects, want)
+			}
+
+			if _, err := c.UpdateProjects(context.Background(), result, result.Projects); err != nil {
+				t.Fatal(err)
+			}
+			written := put["fields"].(map[string]interface{})["content"].(map[string]interface{})[DefaultLocale].([]interface{})[0].(map[string]interface{})
+			for _, key := range tt.wantKeys {
+				if written[key] == nil {
+					t.Errorf("written project lacks %q: %v", key, written)
+				}
+			}
+			for field, name := range tt.names {
+				if _, ok := written[field]; ok && field != name {
+					t.Errorf("written project still has %q: %v", field, written)
+				}
+			}
+		})
+	}
+}
+
+func TestProjectSchemaFieldNames(t *testing.T) {
+	schema := ProjectSchema(map[string]string{"shortDescription": "summary"})
+	properties := schema["properties"].(map[string]interface{})
+	if _, ok := properties["summary"]; !ok {
+		t.Error("schema lacks the renamed summary property")
+	}
+	if _, ok := properties["shortDescription"]; ok {
+		t.Error("schema still has shortDescription")
+	}
+	if _, ok := properties["longDescription"]; !ok {
+		t.Error("schema lost longDescription")
+	}
+}
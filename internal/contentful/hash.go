@@ -0,0 +1,51 @@
+package contentful
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// hashedFields is the subset of Project that feeds ContentHash. It holds
+// everything the frontend renders; ranking output (featured, order) and
+// provenance (sourceCommit, contentHash itself) are left out so they never
+// register as content changes.
+type hashedFields struct {
+	Name                string              `json:"name"`
+	Slug                string              `json:"slug"`
+	ShortDescription    string              `json:"shortDescription"`
+	LongDescription     string              `json:"longDescription"`
+	GithubURL           string              `json:"githubUrl"`
+	LiveURL             string              `json:"liveUrl"`
+	Technologies        []string            `json:"technologies"`
+	TechnologiesByGroup map[string][]string `json:"technologiesByGroup"`
+	Highlights          []string            `json:"highlights"`
+	Gradient            string              `json:"gradient"`
+	Category            string              `json:"category"`
+	DisplayDate         string              `json:"displayDate"`
+	Display             *Display            `json:"display"`
+}
+
+// ComputeContentHash returns a hex SHA-256 of the project's content fields.
+func (p Project) ComputeContentHash() string {
+	b, err := json.Marshal(hashedFields{
+		Name:                p.Name,
+		Slug:                p.Slug,
+		ShortDescription:    p.ShortDescription,
+		LongDescription:     p.LongDescription,
+		GithubURL:           p.GithubURL,
+		LiveURL:             p.LiveURL,
+		Technologies:        p.Technologies,
+		TechnologiesByGroup: p.TechnologiesByGroup,
+		Highlights:          p.Highlights,
+		Gradient:            p.Gradient,
+		Category:            p.Category,
+		DisplayDate:         p.DisplayDate,
+		Display:             p.Display,
+	})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,50 @@
+package contentful
+
+import "testing"
+
+func TestComputeContentHash(t *testing.T) {
+	base := Project{
+		Name:         "Tool",
+		Slug:         "tool",
+		GithubURL:    "https://github.com/octo/tool",
+		Technologies: []string{"Go"},
+		Highlights:   []string{"Fast"},
+	}
+
+	tests := []struct {
+		name        string
+		change      func(p *Project)
+		wantChanged bool
+	}{
+		{name: "identical copy", change: func(p *Project) {
+			p.Technologies = []string{"Go"}
+			p.Highlights = []string{"Fast"}
+		}},
+		{name: "stored hash", change: func(p *Project) { p.ContentHash = "stale" }},
+		{name: "name", change: func(p *Project) { p.Name = "Other" }, wantChanged: true},
+		{name: "technologies", change: func(p *Project) { p.Technologies = []string{"Go", "Rust"} }, wantChanged: true},
+		{name: "highlights", change: func(p *Project) { p.Highlights = nil }, wantChanged: true},
+		{name: "category", change: func(p *Project) { p.Category = "cli" }, wantChanged: true},
+		{name: "live url", change: func(p *Project) { p.LiveURL = "https://tool.dev" }, wantChanged: true},
+		{name: "display date", change: func(p *Project) { p.DisplayDate = "2026-01-02" }, wantChanged: true},
+		{name: "display", change: func(p *Project) { p.Display = &Display{ShowDemo: true} }, wantChanged: true},
+		{name: "technology groups", change: func(p *Project) { p.TechnologiesByGroup = map[string][]string{"Backend": {"Go"}} }, wantChanged: true},
+		{name: "description", change: func(p *Project) { p.LongDescription = "New" }, wantChanged: true},
+		{name: "featured", change: func(p *Project) { p.Featured = true }},
+		{name: "order", change: func(p *Project) { p.Order = 3 }},
+		{name: "source commit", change: func(p *Project) { p.SourceCommit = "abc123" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := base
+			tt.change(&p)
+			got, want := p.ComputeContentHash(), base.ComputeContentHash()
+			if len(got) != 64 {
+				t.Fatalf("hash = %q, want 64 hex characters", got)
+			}
+			if changed := got != want; changed != tt.wantChanged {
+				t.Errorf("hash changed = %v, want %v", changed, tt.wantChanged)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package contentful
+
+import (
+	"reflect"
+	"strings"
+)
+
+// ProjectSchema returns a JSON Schema for one Project as written to the
+// content field, derived from its struct tags so it cannot drift from the
+// type. Fields tagged omitempty are optional; fields tagged "-" are left
+// out. Top-level fields are renamed as by Client.SetFieldNames.
+func ProjectSchema(names map[string]string) map[string]interface{} {
+	schema := structSchema(reflect.TypeOf(Project{}))
+	properties := schema["properties"].(map[string]interface{})
+	required := schema["required"].([]string)
+	for field, name := range names {
+		if prop, ok := properties[field]; ok {
+			delete(properties, field)
+			properties[name] = prop
+		}
+		for i := range required {
+			if required[i] == field {
+				required[i] = name
+			}
+		}
+	}
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "Project"
+	return schema
+}
+
+// structSchema describes a struct type as a closed JSON Schema object.
+func structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = schemaType(f.Type)
+		if !strings.Contains(","+opts+",", ",omitempty,") {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// schemaType maps a Go type to its JSON Schema type description.
+func schemaType(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaType(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaType(t.Elem())}
+	case reflect.Ptr:
+		return schemaType(t.Elem())
+	case reflect.Struct:
+		return structSchema(t)
+	}
+	return map[string]interface{}{}
+}
@@ -0,0 +1,92 @@
+package contentful
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestProjectSchemaIncludesAllFields(t *testing.T) {
+	// Every key a fully populated project marshals to must be described.
+	p := Project{
+		Name: "n", Slug: "s", ShortDescription: "sd", LongDescription: "ld",
+		GithubURL: "g", LiveURL: "l", Technologies: []string{"Go"},
+		TechnologiesByGroup: map[string][]string{"Backend": {"Go"}},
+		Highlights:          []string{"h"}, Featured: true, Gradient: "from-red-500 to-rose-600",
+		Category: "Web", Order: 1, Draft: true, SourceCommit: "abc", ContentHash: "hash",
+		DisplayDate: "2026", Display: &Display{ShowDemo: true}, PushedAt: time.Now(),
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written map[string]interface{}
+	if err := json.Unmarshal(b, &written); err != nil {
+		t.Fatal(err)
+	}
+
+	properties := ProjectSchema(nil)["properties"].(map[string]interface{})
+	for key := range written {
+		if _, ok := properties[key]; !ok {
+			t.Errorf("schema lacks %q", key)
+		}
+	}
+	if len(properties) != len(written) {
+		t.Errorf("schema has %d properties, project writes %d keys", len(properties), len(written))
+	}
+}
+
+func TestProjectSchemaProperties(t *testing.T) {
+	schema := ProjectSchema(nil)
+	properties := schema["properties"].(map[string]interface{})
+	required := schema["required"].([]string)
+
+	tests := []struct {
+		field    string
+		want     map[string]interface{}
+		required bool
+	}{
+		{field: "name", want: map[string]interface{}{"type": "string"}, required: true},
+		{field: "technologies", want: map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}, required: true},
+		{field: "featured", want: map[string]interface{}{"type": "boolean"}, required: true},
+		{field: "order", want: map[string]interface{}{"type": "integer"}},
+		{
+			field: "technologiesByGroup",
+			want: map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{
+				"type": "array", "items": map[string]interface{}{"type": "string"},
+			}},
+		},
+		{
+			field: "display",
+			want: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"showDemo":         map[string]interface{}{"type": "boolean"},
+					"showTechnologies": map[string]interface{}{"type": "boolean"},
+					"showHighlights":   map[string]interface{}{"type": "boolean"},
+				},
+				"required":             []string{"showDemo", "showTechnologies", "showHighlights"},
+				"additionalProperties": false,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got := properties[tt.field]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s = %v, want %v", tt.field, got, tt.want)
+			}
+			if got := slices.Contains(required, tt.field); got != tt.required {
+				t.Errorf("%s required = %v, want %v", tt.field, got, tt.required)
+			}
+		})
+	}
+
+	if _, ok := properties["PushedAt"]; ok {
+		t.Error("schema describes PushedAt, which is never written")
+	}
+	if schema["additionalProperties"] != false {
+		t.Error("schema allows additional properties")
+	}
+}
@@ -0,0 +1,74 @@
+package contentful
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	servicekit "github.com/alberto-moreno-sa/go-service-kit/contentful"
+)
+
+// snapshotPageSize is how many snapshots are requested per page.
+const snapshotPageSize = 100
+
+// GetProjectsAtVersion fetches the projects entry as it was at version, from
+// Contentful's snapshot history. Only published versions have snapshots.
+func (c *Client) GetProjectsAtVersion(ctx context.Context, entryID string, version int) (*ProjectsResult, error) {
+	for skip := 0; ; skip += snapshotPageSize {
+		page, total, err := c.getSnapshots(ctx, entryID, skip)
+		if err != nil {
+			return nil, err
+		}
+		for i := range page {
+			if page[i].Snapshot.Sys.Version == version {
+				result, err := c.projectsResult(&page[i].Snapshot)
+				if err != nil {
+					return nil, fmt.Errorf("snapshot of version %d: %w", version, err)
+				}
+				return result, nil
+			}
+		}
+		if len(page) == 0 || skip+len(page) >= total {
+			return nil, fmt.Errorf("no published snapshot of %s at version %d", entryID, version)
+		}
+	}
+}
+
+type snapshotItem struct {
+	Snapshot servicekit.EntryItem `json:"snapshot"`
+}
+
+// getSnapshots fetches one page of entryID's snapshots and the total count.
+func (c *Client) getSnapshots(ctx context.Context, entryID string, skip int) ([]snapshotItem, int, error) {
+	url := fmt.Sprintf("%s/%s/snapshots?limit=%d&skip=%d", c.entriesURL(), entryID, snapshotPageSize, skip)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, 0, fmt.Errorf("CMA get snapshots failed (%d): could not read body: %w", resp.StatusCode, err)
+		}
+		return nil, 0, fmt.Errorf("CMA get snapshots failed (%d): %s", resp.StatusCode, string(body))
+	}
+
+	var page struct {
+		Total int            `json:"total"`
+		Items []snapshotItem `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, 0, fmt.Errorf("decode snapshots: %w", err)
+	}
+	return page.Items, page.Total, nil
+}
@@ -0,0 +1,65 @@
+package contentful
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// snapshotPage renders a snapshots response holding one snapshot per
+// version, each with a single project whose slug names the version.
+func snapshotPage(total int, versions ...int) string {
+	var items []string
+	for _, v := range versions {
+		items = append(items, fmt.Sprintf(`{"snapshot":{"sys":{"id":"projects","version":%d},"fields":{"content":{"en-US":[{"slug":"v%d"}]}}}}`, v, v))
+	}
+	return fmt.Sprintf(`{"total":%d,"items":[%s]}`, total, strings.Join(items, ","))
+}
+
+func TestGetProjectsAtVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   int
+		wantSlug  string
+		wantPages int
+		wantErr   bool
+	}{
+		{name: "first page", version: 3, wantSlug: "v3", wantPages: 1},
+		{name: "second page", version: 7, wantSlug: "v7", wantPages: 2},
+		{name: "missing version", version: 5, wantPages: 2, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := 0
+			c := newTestClient(func(req *http.Request) (*http.Response, error) {
+				pages++
+				if !strings.HasSuffix(req.URL.Path, "/entries/projects/snapshots") {
+					t.Errorf("requested %s, want the projects snapshots", req.URL.Path)
+				}
+				if req.URL.Query().Get("skip") == "0" {
+					return respond(200, snapshotPage(snapshotPageSize+1, 1, 3)), nil
+				}
+				return respond(200, snapshotPage(snapshotPageSize+1, 7)), nil
+			})
+
+			result, err := c.GetProjectsAtVersion(context.Background(), "projects", tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if pages != tt.wantPages {
+				t.Errorf("fetched %d pages, want %d", pages, tt.wantPages)
+			}
+			if err != nil {
+				return
+			}
+			if len(result.Projects) != 1 || result.Projects[0].Slug != tt.wantSlug {
+				t.Errorf("projects = %+v, want slug %s", result.Projects, tt.wantSlug)
+			}
+			if result.Version != tt.version {
+				t.Errorf("Version = %d, want %d", result.Version, tt.version)
+			}
+		})
+	}
+}
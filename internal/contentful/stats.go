@@ -0,0 +1,91 @@
+package contentful
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// PortfolioStats are the aggregate numbers written to the stats entry.
+type PortfolioStats struct {
+	ProjectCount    int      `json:"projectCount"`
+	TechnologyCount int      `json:"technologyCount"`
+	LanguageCount   int      `json:"languageCount"`
+	Languages       []string `json:"languages"`
+	LastSync        string   `json:"lastSync"`
+}
+
+// WriteStats stores stats in the content field of the siteSection entry
+// identified by sectionID and publishes it. Like GetProjects, sectionID may
+// also be an entry ID. The entry is created when none exists.
+func (c *Client) WriteStats(ctx context.Context, sectionID string, stats PortfolioStats) error {
+	entryID, version, fields, err := c.sectionEntry(ctx, sectionID)
+	if err != nil {
+		return err
+	}
+
+	newVersion, err := c.updateContent(ctx, entryID, version, fields, stats)
+	if err != nil {
+		return err
+	}
+	if err := c.PublishEntry(ctx, entryID, newVersion); err != nil {
+		return fmt.Errorf("publish stats: %w", err)
+	}
+	return nil
+}
+
+// sectionEntry returns the ID, version and fields of a siteSection entry,
+// creating it when neither an entry with that ID nor one with that sectionId
+// exists.
+func (c *Client) sectionEntry(ctx context.Context, sectionID string) (string, int, map[string]interface{}, error) {
+	if entry, err := c.GetEntry(ctx, sectionID); err == nil {
+		return entry.Sys.ID, entry.Sys.Version, entry.Fields, nil
+	}
+	if entry, err := c.findProjectsBySectionID(ctx, sectionID); err == nil {
+		return entry.Sys.ID, entry.Sys.Version, entry.Fields, nil
+	}
+
+	fields := map[string]interface{}{
+		"sectionId": map[string]interface{}{DefaultLocale: sectionID},
+	}
+	bodyBytes, err := json.Marshal(map[string]interface{}{"fields": fields})
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("marshal body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.entriesURL(), bytes.NewReader(bodyBytes))
+	if err != nil {
+		return "", 0, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Content-Type", "application/vnd.contentful.management.v1+json")
+	req.Header.Set("X-Contentful-Content-Type", "siteSection")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 201 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", 0, nil, fmt.Errorf("CMA create section failed (%d): could not read body: %w", resp.StatusCode, err)
+		}
+		return "", 0, nil, fmt.Errorf("CMA create section failed (%d): %s", resp.StatusCode, string(body))
+	}
+
+	var created struct {
+		Sys struct {
+			ID      string `json:"id"`
+			Version int    `json:"version"`
+		} `json:"sys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", 0, nil, fmt.Errorf("decode create response: %w", err)
+	}
+	return created.Sys.ID, created.Sys.Version, fields, nil
+}
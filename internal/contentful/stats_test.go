@@ -0,0 +1,83 @@
+package contentful
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteStats(t *testing.T) {
+	tests := []struct {
+		name      string
+		exists    string // "id", "section" or "" when the entry must be created
+		wantCalls []string
+	}{
+		{
+			name:      "entry by ID",
+			exists:    "id",
+			wantCalls: []string{"GET /entries/stats", "PUT /entries/stats", "PUT /entries/stats/published"},
+		},
+		{
+			name:      "entry by sectionId",
+			exists:    "section",
+			wantCalls: []string{"GET /entries/stats", "GET /entries", "PUT /entries/found", "PUT /entries/found/published"},
+		},
+		{
+			name:      "entry is created",
+			wantCalls: []string{"GET /entries/stats", "GET /entries", "POST /entries", "PUT /entries/created", "PUT /entries/created/published"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			var written PortfolioStats
+			c := newTestClient(func(req *http.Request) (*http.Response, error) {
+				path := strings.TrimPrefix(req.URL.Path, "/spaces/space/environments/master")
+				calls = append(calls, req.Method+" "+path)
+				switch {
+				case req.Method == "GET" && path == "/entries/stats":
+					if tt.exists == "id" {
+						return respond(200, `{"sys":{"id":"stats","version":4},"fields":{}}`), nil
+					}
+					return respond(404, "not found"), nil
+				case req.Method == "GET":
+					if tt.exists == "section" {
+						return respond(200, `{"items":[{"sys":{"id":"found","version":4},"fields":{}}]}`), nil
+					}
+					return respond(200, `{"items":[]}`), nil
+				case req.Method == "POST":
+					if got := req.Header.Get("X-Contentful-Content-Type"); got != "siteSection" {
+						t.Errorf("created content type = %q, want siteSection", got)
+					}
+					return respond(201, `{"sys":{"id":"created","version":1}}`), nil
+				case strings.HasSuffix(path, "/published"):
+					return respond(200, `{}`), nil
+				}
+				var body struct {
+					Fields struct {
+						Content map[string]PortfolioStats `json:"content"`
+					} `json:"fields"`
+				}
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+				written = body.Fields.Content[DefaultLocale]
+				return respond(200, `{"sys":{"version":5}}`), nil
+			})
+
+			stats := PortfolioStats{ProjectCount: 3, TechnologyCount: 4, LanguageCount: 2, Languages: []string{"Go", "TypeScript"}, LastSync: "2026-10-16T00:00:00Z"}
+			if err := c.WriteStats(context.Background(), "stats", stats); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if !reflect.DeepEqual(written, stats) {
+				t.Errorf("written = %+v, want %+v", written, stats)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package contentful
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// BuildLogSummary rolls up one month of build-log entries that were pruned
+// from the shared log.
+type BuildLogSummary struct {
+	Month       string  `json:"month"` // YYYY-MM
+	Runs        int     `json:"runs"`
+	Successes   int     `json:"successes"`
+	Added       int     `json:"added"`
+	Removed     int     `json:"removed"`
+	SuccessRate float64 `json:"successRate"`
+}
+
+// RollUpBuildLog adds entries to the monthly summaries, keyed by the month
+// of each entry's timestamp, and returns them oldest month first. Entries
+// with an unparsable timestamp are skipped.
+func RollUpBuildLog(summaries []BuildLogSummary, entries []BuildLogEntry) []BuildLogSummary {
+	byMonth := make(map[string]*BuildLogSummary, len(summaries))
+	for i := range summaries {
+		s := summaries[i]
+		byMonth[s.Month] = &s
+	}
+
+	for _, e := range entries {
+		ts, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err != nil {
+			continue
+		}
+		month := ts.UTC().Format("2006-01")
+		s, ok := byMonth[month]
+		if !ok {
+			s = &BuildLogSummary{Month: month}
+			byMonth[month] = s
+		}
+		s.Runs++
+		if e.Status == "success" || e.Status == "no-changes" {
+			s.Successes++
+		}
+		s.Added += e.NewAdded
+		s.Removed += len(e.RemovedSlugs)
+	}
+
+	out := make([]BuildLogSummary, 0, len(byMonth))
+	for _, s := range byMonth {
+		if s.Runs > 0 {
+			s.SuccessRate = float64(s.Successes) / float64(s.Runs)
+		}
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Month < out[j].Month
+	})
+	return out
+}
+
+// CompactBuildLog rolls entries into the summaries kept in the content field
+// of the siteSection identified by sectionID, creating the entry when
+// missing, and publishes it.
+func (c *Client) CompactBuildLog(ctx context.Context, sectionID string, entries []BuildLogEntry) error {
+	return RetryOnConflict(ctx, func(int) error {
+		entryID, version, fields, err := c.sectionEntry(ctx, sectionID)
+		if err != nil {
+			return err
+		}
+
+		existing, err := sectionContent[[]BuildLogSummary](fields)
+		if err != nil {
+			return fmt.Errorf("read summary: %w", err)
+		}
+
+		newVersion, err := c.updateContent(ctx, entryID, version, fields, RollUpBuildLog(existing, entries))
+		if err != nil {
+			return err
+		}
+		if err := c.PublishEntry(ctx, entryID, newVersion); err != nil {
+			return fmt.Errorf("publish summary: %w", err)
+		}
+		return nil
+	})
+}
+
+// sectionContent decodes the en-US value of a content field into T. A
+// missing field yields T's zero value.
+func sectionContent[T any](fields map[string]interface{}) (T, error) {
+	var v T
+	localeMap, ok := fields["content"].(map[string]interface{})
+	if !ok {
+		return v, nil
+	}
+	raw, ok := localeMap[DefaultLocale]
+	if !ok {
+		return v, nil
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return v, err
+	}
+	err = json.Unmarshal(b, &v)
+	return v, err
+}
@@ -0,0 +1,138 @@
+package contentful
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// summaryEntry builds a pruned build-log entry for the roll-up tests.
+func summaryEntry(ts, status string, added int, removed ...string) BuildLogEntry {
+	var e BuildLogEntry
+	e.Timestamp = ts
+	e.Status = status
+	e.NewAdded = added
+	e.RemovedSlugs = removed
+	return e
+}
+
+func TestRollUpBuildLog(t *testing.T) {
+	tests := []struct {
+		name      string
+		summaries []BuildLogSummary
+		entries   []BuildLogEntry
+		want      []BuildLogSummary
+	}{
+		{
+			name: "nothing to roll up",
+			want: []BuildLogSummary{},
+		},
+		{
+			name: "one month",
+			entries: []BuildLogEntry{
+				summaryEntry("2026-09-01T10:00:00Z", "success", 2, "old"),
+				summaryEntry("2026-09-15T10:00:00Z", "no-changes", 0),
+				summaryEntry("2026-09-20T10:00:00Z", "error", 0),
+				summaryEntry("2026-09-30T10:00:00Z", "success", 1, "a", "b"),
+			},
+			want: []BuildLogSummary{
+				{Month: "2026-09", Runs: 4, Successes: 3, Added: 3, Removed: 3, SuccessRate: 0.75},
+			},
+		},
+		{
+			name: "months sorted oldest first",
+			entries: []BuildLogEntry{
+				summaryEntry("2026-10-02T10:00:00Z", "success", 1),
+				summaryEntry("2026-08-02T10:00:00Z", "error", 0),
+				summaryEntry("2026-09-02T10:00:00Z", "success", 0, "gone"),
+			},
+			want: []BuildLogSummary{
+				{Month: "2026-08", Runs: 1, SuccessRate: 0},
+				{Month: "2026-09", Runs: 1, Successes: 1, Removed: 1, SuccessRate: 1},
+				{Month: "2026-10", Runs: 1, Successes: 1, Added: 1, SuccessRate: 1},
+			},
+		},
+		{
+			name: "month taken in UTC",
+			entries: []BuildLogEntry{
+				summaryEntry("2026-10-01T01:00:00+02:00", "success", 1),
+			},
+			want: []BuildLogSummary{
+				{Month: "2026-09", Runs: 1, Successes: 1, Added: 1, SuccessRate: 1},
+			},
+		},
+		{
+			name: "unparsable timestamp skipped",
+			entries: []BuildLogEntry{
+				summaryEntry("yesterday", "success", 5),
+				summaryEntry("2026-09-02T10:00:00Z", "error", 0),
+			},
+			want: []BuildLogSummary{
+				{Month: "2026-09", Runs: 1, SuccessRate: 0},
+			},
+		},
+		{
+			name: "merged into existing summaries",
+			summaries: []BuildLogSummary{
+				{Month: "2026-08", Runs: 2, Successes: 2, Added: 4, Removed: 1, SuccessRate: 1},
+				{Month: "2026-09", Runs: 1, Successes: 1, Added: 1, SuccessRate: 1},
+			},
+			entries: []BuildLogEntry{
+				summaryEntry("2026-09-20T10:00:00Z", "error", 0),
+				summaryEntry("2026-09-21T10:00:00Z", "success", 2, "x"),
+				summaryEntry("2026-09-22T10:00:00Z", "error", 0),
+			},
+			want: []BuildLogSummary{
+				{Month: "2026-08", Runs: 2, Successes: 2, Added: 4, Removed: 1, SuccessRate: 1},
+				{Month: "2026-09", Runs: 4, Successes: 2, Added: 3, Removed: 1, SuccessRate: 0.5},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RollUpBuildLog(tt.summaries, tt.entries); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RollUpBuildLog() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompactBuildLog(t *testing.T) {
+	var written []BuildLogSummary
+	var published bool
+	c := newTestClient(func(req *http.Request) (*http.Response, error) {
+		path := strings.TrimPrefix(req.URL.Path, "/spaces/space/environments/master")
+		switch {
+		case req.Method == "GET":
+			return respond(200, `{"sys":{"id":"summary","version":4},"fields":{"content":{"en-US":[{"month":"2026-08","runs":1,"successes":1,"added":2,"removed":0,"successRate":1}]}}}`), nil
+		case strings.HasSuffix(path, "/published"):
+			published = true
+			return respond(200, `{}`), nil
+		}
+		var body struct {
+			Fields struct {
+				Content map[string][]BuildLogSummary `json:"content"`
+			} `json:"fields"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		written = body.Fields.Content[DefaultLocale]
+		return respond(200, `{"sys":{"version":5}}`), nil
+	})
+
+	pruned := []BuildLogEntry{summaryEntry("2026-08-20T10:00:00Z", "error", 0, "old")}
+	if err := c.CompactBuildLog(context.Background(), "summary", pruned); err != nil {
+		t.Fatal(err)
+	}
+	want := []BuildLogSummary{{Month: "2026-08", Runs: 2, Successes: 1, Added: 2, Removed: 1, SuccessRate: 0.5}}
+	if !reflect.DeepEqual(written, want) {
+		t.Errorf("written = %+v, want %+v", written, want)
+	}
+	if !published {
+		t.Error("summary entry was not published")
+	}
+}
@@ -4,23 +4,46 @@ import "time"
 
 // Project represents a project entry for the CMS.
 type Project struct {
-	Name            string   `json:"name"`
-	Slug            string   `json:"slug"`
-	ShortDescription string   `json:"shortDescription"`
-	LongDescription  string   `json:"longDescription"`
-	GithubURL       string   `json:"githubUrl"`
-	Technologies    []string `json:"technologies"`
-	Highlights      []string `json:"highlights"`
-	Featured        bool     `json:"featured"`
-	Gradient        string   `json:"gradient"`
-	Category        string   `json:"category"`
-	PushedAt        time.Time `json:"-"`
+	Name                string              `json:"name"`
+	Slug                string              `json:"slug"`
+	ShortDescription    string              `json:"shortDescription"`
+	LongDescription     string              `json:"longDescription"`
+	GithubURL           string              `json:"githubUrl"`
+	LiveURL             string              `json:"liveUrl"`
+	Technologies        []string            `json:"technologies"`
+	TechnologiesByGroup map[string][]string `json:"technologiesByGroup,omitempty"`
+	Highlights          []string            `json:"highlights"`
+	Featured            bool                `json:"featured"`
+	Gradient            string              `json:"gradient"`
+	Category            string              `json:"category"`
+	Order               int                 `json:"order,omitempty"`
+	Draft               bool                `json:"draft,omitempty"`
+	SourceCommit        string              `json:"sourceCommit,omitempty"`
+	ContentHash         string              `json:"contentHash,omitempty"`
+	DisplayDate         string              `json:"displayDate,omitempty"`
+	Display             *Display            `json:"display,omitempty"`
+	PushedAt            time.Time           `json:"-"`
+}
+
+// DefaultLocale is the locale projects are enriched in and read from.
+const DefaultLocale = "en-US"
+
+// Display switches optional sections of a project's card on or off.
+type Display struct {
+	ShowDemo         bool `json:"showDemo"`
+	ShowTechnologies bool `json:"showTechnologies"`
+	ShowHighlights   bool `json:"showHighlights"`
 }
 
 // ProjectsResult holds the fetched projects along with entry metadata
 // needed for the fetch-mutate-put update pattern.
 type ProjectsResult struct {
-	Projects  []Project
+	Projects []Project
+
+	// Localized holds the projects stored under every locale of the
+	// content field, including DefaultLocale.
+	Localized map[string][]Project
+
 	EntryID   string
 	Version   int
 	RawFields map[string]interface{}
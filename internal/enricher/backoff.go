@@ -0,0 +1,121 @@
+package enricher
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+const maxRetryDelay = 60 * time.Second
+
+// retryDelay is a variable so tests can shorten it.
+var retryDelay = 10 * time.Second
+
+var (
+	jitterMu   sync.Mutex
+	jitterRand = rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), 0))
+)
+
+// seedJitter makes the random part of backoffDuration reproducible.
+func seedJitter(seed int64) {
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	jitterRand = rand.New(rand.NewPCG(uint64(seed), 0))
+}
+
+// backoffDuration returns how long to wait before retry attempt (1 for the
+// first retry). The delay starts at retryDelay and doubles each attempt up
+// to maxRetryDelay; half of it is fixed and half random, so runs that failed
+// together do not retry in lockstep.
+func backoffDuration(attempt int) time.Duration {
+	if attempt < 1 {
+		return 0
+	}
+	d := maxRetryDelay
+	if attempt <= 16 {
+		if exp := retryDelay << (attempt - 1); exp < maxRetryDelay {
+			d = exp
+		}
+	}
+
+	jitterMu.Lock()
+	r := jitterRand.Float64()
+	jitterMu.Unlock()
+	return d/2 + time.Duration(r*float64(d/2))
+}
+
+// maxRetryHint caps a server-supplied retry delay so a bad hint cannot stall
+// the run.
+const maxRetryHint = 5 * time.Minute
+
+// retryDelayRe finds the RetryInfo delay Gemini embeds in error details,
+// e.g. "retryDelay": "37s", which the SDK passes through in its messages.
+var retryDelayRe = regexp.MustCompile(`"?retryDelay"?\s*:\s*"(\d+(?:\.\d+)?)s"`)
+
+// requestError is a failed Gemini request sent by generateContentGenAI.
+type requestError struct {
+	Status     int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *requestError) Error() string {
+	return fmt.Sprintf("gemini request failed (%d): %s", e.Status, e.Body)
+}
+
+// retryHint returns the wait the server asked for in err, from a Retry-After
+// header or a RetryInfo retryDelay, or zero when there is none.
+func retryHint(err error) time.Duration {
+	var hint time.Duration
+	var reqErr *requestError
+	var apiErr genai.APIError
+	if errors.As(err, &reqErr) && reqErr.RetryAfter > 0 {
+		hint = reqErr.RetryAfter
+	} else if errors.As(err, &apiErr) && detailsRetryDelay(apiErr.Details) > 0 {
+		hint = detailsRetryDelay(apiErr.Details)
+	} else if m := retryDelayRe.FindStringSubmatch(err.Error()); m != nil {
+		if secs, perr := strconv.ParseFloat(m[1], 64); perr == nil {
+			hint = time.Duration(secs * float64(time.Second))
+		}
+	}
+	return min(hint, maxRetryHint)
+}
+
+// detailsRetryDelay returns the retryDelay of a RetryInfo entry in the
+// details of a Gemini API error, e.g. "37s", or zero when there is none.
+func detailsRetryDelay(details []map[string]any) time.Duration {
+	for _, d := range details {
+		if v, ok := d["retryDelay"].(string); ok {
+			if delay, err := time.ParseDuration(v); err == nil && delay > 0 {
+				return delay
+			}
+		}
+	}
+	return 0
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date; anything else yields zero.
+func parseRetryAfter(v string) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
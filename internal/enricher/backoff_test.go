@@ -0,0 +1,146 @@
+package enricher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestBackoffDuration(t *testing.T) {
+	seedJitter(1)
+	tests := []struct {
+		attempt int
+		base    time.Duration
+	}{
+		{attempt: 0, base: 0},
+		{attempt: 1, base: 10 * time.Second},
+		{attempt: 2, base: 20 * time.Second},
+		{attempt: 3, base: 40 * time.Second},
+		{attempt: 4, base: maxRetryDelay},
+		{attempt: 10, base: maxRetryDelay},
+		{attempt: 64, base: maxRetryDelay},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint("attempt ", tt.attempt), func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				got := backoffDuration(tt.attempt)
+				if got < tt.base/2 || got > tt.base {
+					t.Fatalf("backoffDuration(%d) = %s, want within [%s, %s]", tt.attempt, got, tt.base/2, tt.base)
+				}
+			}
+		})
+	}
+}
+
+func TestBackoffDurationJitter(t *testing.T) {
+	seedJitter(1)
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 20; i++ {
+		seen[backoffDuration(3)] = true
+	}
+	if len(seen) < 10 {
+		t.Errorf("got %d distinct delays in 20 draws, want jitter", len(seen))
+	}
+}
+
+func TestGenerateWithRetryHonorsCancel(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = time.Hour
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := generateWithRetry(ctx, Options{APIKey: "key", BaseURL: srv.URL, HTTPClient: srv.Client()}, "system", "user")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the context deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("returned after %s, want the wait cut short", elapsed)
+	}
+}
+
+func TestRetryHint(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want time.Duration
+	}{
+		{name: "no hint", err: errors.New("gemini request failed (429): quota"), want: 0},
+		{name: "Retry-After header", err: &requestError{Status: 429, RetryAfter: 7 * time.Second}, want: 7 * time.Second},
+		{name: "wrapped Retry-After", err: fmt.Errorf("gemini: %w", &requestError{Status: 503, RetryAfter: 2 * time.Second}), want: 2 * time.Second},
+		{name: "RetryInfo in the body", err: &requestError{Status: 429, Body: `{"details":[{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay":"37s"}]}`}, want: 37 * time.Second},
+		{name: "fractional RetryInfo in an SDK message", err: errors.New(`Error 429, RESOURCE_EXHAUSTED: retryDelay: "1.5s"`), want: 1500 * time.Millisecond},
+		{name: "header wins over the body", err: &requestError{Status: 429, Body: `"retryDelay": "37s"`, RetryAfter: 3 * time.Second}, want: 3 * time.Second},
+		{name: "genai error details", err: fmt.Errorf("gemini generate: %w", genai.APIError{Code: 429, Details: []map[string]any{{"retryDelay": "12s"}}}), want: 12 * time.Second},
+		{name: "capped", err: &requestError{Status: 429, RetryAfter: time.Hour}, want: maxRetryHint},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryHint(tt.err); got != tt.want {
+				t.Errorf("retryHint = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		min   time.Duration
+		max   time.Duration
+	}{
+		{name: "empty"},
+		{name: "seconds", value: " 12 ", min: 12 * time.Second, max: 12 * time.Second},
+		{name: "zero", value: "0"},
+		{name: "garbage", value: "soon"},
+		{name: "HTTP date", value: time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat), min: 25 * time.Second, max: 30 * time.Second},
+		{name: "past HTTP date", value: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value); got < tt.min || got > tt.max {
+				t.Errorf("parseRetryAfter(%q) = %s, want within [%s, %s]", tt.value, got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestGenerateWithRetryUsesHint(t *testing.T) {
+	// With the default delay the retry would wait at least five seconds
+	reqs := 0
+	var gap time.Duration
+	var last time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqs++
+		if reqs > 1 {
+			gap = time.Since(last)
+		}
+		last = time.Now()
+		if reqs == 1 {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "quota", http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"[]"}]}}]}`))
+	}))
+	defer srv.Close()
+
+	if _, err := generateWithRetry(context.Background(), Options{APIKey: "key", BaseURL: srv.URL, HTTPClient: srv.Client()}, "system", "user"); err != nil {
+		t.Fatal(err)
+	}
+	if reqs != 2 || gap < time.Second || gap > 3*time.Second {
+		t.Errorf("made %d requests %s apart, want 2 about a second apart", reqs, gap)
+	}
+}
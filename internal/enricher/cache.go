@@ -0,0 +1,108 @@
+package enricher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/mapper"
+)
+
+// Cache stores enrichment results on disk keyed by slug and a hash of
+// everything sent to Gemini for the repo and the settings that shape the
+// answer, so unchanged repos are not re-enriched. Each slug keeps only its
+// latest entry. A nil *Cache is valid and never hits.
+type Cache struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]enrichedData
+}
+
+// LoadCache reads the cache file at path. A missing file yields an empty
+// cache that Save will create.
+func LoadCache(path string) (*Cache, error) {
+	c := &Cache{path: path, entries: make(map[string]enrichedData)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read enrich cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, fmt.Errorf("parse enrich cache: %w", err)
+	}
+	return c, nil
+}
+
+// Save writes the cache back to its file.
+func (c *Cache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("marshal enrich cache: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0o644); err != nil {
+		return fmt.Errorf("write enrich cache: %w", err)
+	}
+	return nil
+}
+
+func (c *Cache) get(opts Options, raw mapper.RawProject) (*enrichedData, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.entries[cacheKey(opts, raw)]
+	if !ok {
+		return nil, false
+	}
+	return &data, true
+}
+
+// put records data for raw, evicting the slug's entries for older input or
+// settings.
+func (c *Cache) put(opts Options, raw mapper.RawProject, data enrichedData) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if strings.HasPrefix(key, raw.Slug+":") {
+			delete(c.entries, key)
+		}
+	}
+	c.entries[cacheKey(opts, raw)] = data
+}
+
+// cacheKey is the slug plus a SHA-256 of the prompts sent for raw and of the
+// model, seed and validation settings that decide what is kept.
+func cacheKey(opts Options, raw mapper.RawProject) string {
+	seed := "none"
+	if opts.Seed != nil {
+		seed = fmt.Sprint(*opts.Seed)
+	}
+	h := sha256.New()
+	for _, part := range []string{
+		systemPrompt, buildBatchPrompt([]mapper.RawProject{raw}),
+		opts.Model, seed, fmt.Sprint(opts.StrictURLs),
+		fmt.Sprint(opts.StrictCategories), fmt.Sprint(opts.CategoryRules),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return raw.Slug + ":" + hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,87 @@
+package enricher
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/mapper"
+)
+
+func TestCacheSecondRunSkipsGemini(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	projects := rawProjects("a", "b")
+
+	for run, wantCalls := range []int{1, 0} {
+		cache, err := LoadCache(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gen := &scriptedGenerator{}
+		got, err := Enrich(context.Background(), Options{Generator: gen, Cache: cache}, projects)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 {
+			t.Errorf("run %d: got %d projects, want 2", run+1, len(got))
+		}
+		if len(gen.calls) != wantCalls {
+			t.Errorf("run %d: made %d Gemini calls, want %d", run+1, len(gen.calls), wantCalls)
+		}
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	raw := rawProjects("a")[0]
+	seed := int64(7)
+	base := Options{Model: "gemini-1.5-flash"}
+
+	tests := []struct {
+		name     string
+		opts     Options
+		change   func(r *mapper.RawProject)
+		wantSame bool
+	}{
+		{name: "identical", opts: base, wantSame: true},
+		{name: "model", opts: Options{Model: "gemini-1.5-pro"}},
+		{name: "seed", opts: Options{Model: base.Model, Seed: &seed}},
+		{name: "strict urls", opts: Options{Model: base.Model, StrictURLs: true}},
+		{name: "category rules", opts: Options{Model: base.Model, CategoryRules: CategoryRules{"Go": {"Backend"}}}},
+		{name: "readme", opts: base, change: func(r *mapper.RawProject) { r.ReadmeRaw += "More.\n" }},
+		{name: "technology hints", opts: base, change: func(r *mapper.RawProject) { r.TechHints = []string{"Cobra"} }},
+		{name: "topics are not sent", opts: base, change: func(r *mapper.RawProject) { r.Topics = []string{"cli"} }, wantSame: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := raw
+			if tt.change != nil {
+				tt.change(&r)
+			}
+			if same := cacheKey(tt.opts, r) == cacheKey(base, raw); same != tt.wantSame {
+				t.Errorf("same key = %v, want %v", same, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestCacheEvictsStaleEntries(t *testing.T) {
+	cache, err := LoadCache(filepath.Join(t.TempDir(), "cache.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := rawProjects("a")[0], rawProjects("ab")[0]
+	cache.put(Options{}, a, enrichedData{Name: "old"})
+	cache.put(Options{}, b, enrichedData{Name: "other"})
+	a.ReadmeRaw += "Changed.\n"
+	cache.put(Options{}, a, enrichedData{Name: "new"})
+
+	if len(cache.entries) != 2 {
+		t.Errorf("cache holds %d entries, want 2", len(cache.entries))
+	}
+	if data, ok := cache.get(Options{}, a); !ok || data.Name != "new" {
+		t.Errorf("get(a) = %v, %v, want the new entry", data, ok)
+	}
+	if _, ok := cache.get(Options{}, b); !ok {
+		t.Error("entry of a slug sharing a's prefix was evicted")
+	}
+}
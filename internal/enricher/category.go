@@ -0,0 +1,137 @@
+package enricher
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/mapper"
+)
+
+// AllowedCategories are the categories the system prompt offers Gemini.
+var AllowedCategories = []string{"Web", "Backend", "Full-Stack", "Libraries", "DevOps", "Game Dev", "Mobile"}
+
+// fallbackCategory replaces a category that neither matches nor aliases an
+// allowed one.
+const fallbackCategory = "Backend"
+
+// categoryAliases maps common off-list answers, lowercased, to the allowed
+// category they mean.
+var categoryAliases = map[string]string{
+	"frontend":       "Web",
+	"front-end":      "Web",
+	"website":        "Web",
+	"api":            "Backend",
+	"server":         "Backend",
+	"fullstack":      "Full-Stack",
+	"full stack":     "Full-Stack",
+	"library":        "Libraries",
+	"tooling":        "Libraries",
+	"tools":          "Libraries",
+	"cli":            "Libraries",
+	"infrastructure": "DevOps",
+	"infra":          "DevOps",
+	"game":           "Game Dev",
+	"games":          "Game Dev",
+	"gamedev":        "Game Dev",
+	"android":        "Mobile",
+	"ios":            "Mobile",
+}
+
+// CanonicalCategory returns category in its AllowedCategories spelling or
+// the category an alias points to. It reports false when category is
+// neither.
+func CanonicalCategory(category string) (string, bool) {
+	c := strings.TrimSpace(category)
+	for _, a := range AllowedCategories {
+		if strings.EqualFold(a, c) {
+			return a, true
+		}
+	}
+	a, ok := categoryAliases[strings.ToLower(c)]
+	return a, ok
+}
+
+// allowedCategory returns category in its AllowedCategories spelling, the
+// category an alias points to, or fallbackCategory with a warning.
+func allowedCategory(name, category string) string {
+	if a, ok := CanonicalCategory(category); ok {
+		return a
+	}
+	log.Printf("WARNING: %s got unknown category %q, using %s", name, category, fallbackCategory)
+	return fallbackCategory
+}
+
+// withAllowedOverrides returns projects with each category override in its
+// AllowedCategories spelling. Overrides that match no allowed category are
+// dropped with a warning, so Gemini's category stands.
+func withAllowedOverrides(projects []mapper.RawProject) []mapper.RawProject {
+	out := slices.Clone(projects)
+	for i, raw := range out {
+		if raw.CategoryOverride == "" {
+			continue
+		}
+		c, ok := CanonicalCategory(raw.CategoryOverride)
+		if !ok {
+			log.Printf("WARNING: %s has unknown category override %q, ignoring it", raw.Name, raw.CategoryOverride)
+		}
+		out[i].CategoryOverride = c
+	}
+	return out
+}
+
+// CategoryRules maps a dominant language to the categories that are
+// plausible for it. Languages without a rule accept any category.
+type CategoryRules map[string][]string
+
+// ParseCategoryRules reads a semicolon-separated list of
+// language:category|category entries, e.g. "Go:Backend|Libraries|DevOps;Swift:Mobile".
+func ParseCategoryRules(spec string) (CategoryRules, error) {
+	rules := CategoryRules{}
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		lang, cats, ok := strings.Cut(part, ":")
+		if !ok || strings.TrimSpace(lang) == "" {
+			return nil, fmt.Errorf("rule %q: expected language:category|category", part)
+		}
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		for _, c := range strings.Split(cats, "|") {
+			if c = strings.TrimSpace(c); c != "" {
+				rules[lang] = append(rules[lang], c)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// plausibleCategory reports whether category fits raw's dominant language.
+// Projects with a category override or no languages always pass.
+func plausibleCategory(rules CategoryRules, raw mapper.RawProject, category string) bool {
+	if len(rules) == 0 || raw.CategoryOverride != "" || len(raw.Languages) == 0 {
+		return true
+	}
+	allowed, ok := rules[strings.ToLower(raw.Languages[0])]
+	if !ok {
+		return true
+	}
+	for _, c := range allowed {
+		if strings.EqualFold(c, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// correctCategory replaces an implausible category with the first one the
+// rules allow for raw's dominant language.
+func correctCategory(rules CategoryRules, raw mapper.RawProject, data *enrichedData) {
+	allowed := rules[strings.ToLower(raw.Languages[0])]
+	if len(allowed) > 0 {
+		data.Category = allowed[0]
+	}
+}
@@ -0,0 +1,124 @@
+package enricher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAllowedCategory(t *testing.T) {
+	tests := []struct {
+		category string
+		want     string
+		warns    bool
+	}{
+		{category: "Web", want: "Web"},
+		{category: "game dev", want: "Game Dev"},
+		{category: " FULL-STACK ", want: "Full-Stack"},
+		{category: "Tooling", want: "Libraries"},
+		{category: "Frontend", want: "Web"},
+		{category: "fullstack", want: "Full-Stack"},
+		{category: "iOS", want: "Mobile"},
+		{category: "Infrastructure", want: "DevOps"},
+		{category: "Data Science", want: fallbackCategory, warns: true},
+		{category: "", want: fallbackCategory, warns: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.category, func(t *testing.T) {
+			var buf bytes.Buffer
+			log.SetOutput(&buf)
+			defer log.SetOutput(os.Stderr)
+
+			if got := allowedCategory("repo", tt.category); got != tt.want {
+				t.Errorf("allowedCategory(%q) = %q, want %q", tt.category, got, tt.want)
+			}
+			if warned := strings.Contains(buf.String(), "unknown category"); warned != tt.warns {
+				t.Errorf("warned = %v, want %v (log %q)", warned, tt.warns, buf.String())
+			}
+		})
+	}
+}
+
+func TestCategoryAliasesAreAllowed(t *testing.T) {
+	for alias, category := range categoryAliases {
+		if alias != strings.ToLower(alias) {
+			t.Errorf("alias %q is not lowercase", alias)
+		}
+		if got := allowedCategory("repo", category); got != category {
+			t.Errorf("alias %q maps to %q, which is not in AllowedCategories", alias, category)
+		}
+	}
+}
+
+func TestSystemPromptListsAllowedCategories(t *testing.T) {
+	list, err := json.Marshal(AllowedCategories)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.ReplaceAll(string(list), `","`, `", "`)
+	if !strings.Contains(systemPrompt, want) {
+		t.Errorf("system prompt does not offer exactly %s", want)
+	}
+}
+
+func TestEnrichNormalizesCategory(t *testing.T) {
+	tests := []struct {
+		category string
+		want     string
+	}{
+		{category: "DevOps", want: "DevOps"},
+		{category: "mobile", want: "Mobile"},
+		{category: "Tooling", want: "Libraries"},
+		{category: "Data Science", want: "Backend"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.category, func(t *testing.T) {
+			gen := &scriptedGenerator{reply: func([]string) (string, error) {
+				return fmt.Sprintf(`[{"name":"a","shortDescription":"A CLI.","technologies":["Go"],"category":%q,"gradient":"from-cyan-500 to-blue-600"}]`, tt.category), nil
+			}}
+			projects, err := Enrich(context.Background(), Options{Generator: gen}, rawProjects("a"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(projects) != 1 || projects[0].Category != tt.want {
+				t.Fatalf("projects = %+v, want category %q", projects, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnrichCategoryOverride(t *testing.T) {
+	tests := []struct {
+		override string
+		want     string
+	}{
+		{override: "Mobile", want: "Mobile"},
+		{override: "web", want: "Web"},
+		{override: "cli", want: "Libraries"},
+		{override: "Documentation", want: "DevOps"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.override, func(t *testing.T) {
+			gen := &scriptedGenerator{reply: func([]string) (string, error) {
+				return `[{"name":"a","shortDescription":"A CLI.","technologies":["Go"],"category":"DevOps","gradient":"from-cyan-500 to-blue-600"}]`, nil
+			}}
+			raws := rawProjects("a")
+			raws[0].CategoryOverride = tt.override
+			projects, err := Enrich(context.Background(), Options{Generator: gen}, raws)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(projects) != 1 || projects[0].Category != tt.want {
+				t.Fatalf("projects = %+v, want category %q", projects, tt.want)
+			}
+			if raws[0].CategoryOverride != tt.override {
+				t.Errorf("input override changed to %q", raws[0].CategoryOverride)
+			}
+		})
+	}
+}
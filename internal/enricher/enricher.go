@@ -3,12 +3,13 @@ package enricher
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/alberto-moreno-sa/go-service-kit/gemini"
 	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
 	"github.com/alberto-moreno-sa/github-cms-sync/internal/mapper"
 )
@@ -22,9 +23,12 @@ For EACH repository, generate a JSON object with:
 1. "name": a human-readable project name derived from the repo name (e.g. "financial-dashboard" → "Financial Dashboard", "go-service-kit" → "Go Service Kit", "alberthiggs.com" → "alberthiggs.com")
 2. "shortDescription": 1 brief phrase, max 200 chars. A concise summary of what the project is.
 3. "longDescription": 2-3 sentences. What it does, key technical decisions, and impact.
+   If the repository has an "overview", base the longDescription on it and stay faithful to it.
+   If it has a "description", treat it as the author's own summary.
 4. "technologies": array of specific technologies (frameworks, libraries, databases).
    Use the languages list AND the README to identify: React, FastAPI, PostgreSQL, Docker, etc.
    Do NOT list generic terms like "JavaScript" if a framework like "React" is more specific.
+   Always include every entry of "technologyHints" when present.
 5. "highlights": array of 3-5 bullet points. Focus on technical achievements, not features.
    Each highlight should be concise (under 60 chars).
 6. "category": one of ["Web", "Backend", "Full-Stack", "Libraries", "DevOps", "Game Dev", "Mobile"]
@@ -43,86 +47,402 @@ type enrichedData struct {
 	Name             string   `json:"name"`
 	ShortDescription string   `json:"shortDescription"`
 	LongDescription  string   `json:"longDescription"`
-	Technologies    []string `json:"technologies"`
-	Highlights      []string `json:"highlights"`
-	Category        string   `json:"category"`
-	Gradient        string   `json:"gradient"`
+	Technologies     []string `json:"technologies"`
+	Highlights       []string `json:"highlights"`
+	Category         string   `json:"category"`
+	Gradient         string   `json:"gradient"`
 }
 
-const (
-	maxRetries = 3
-	retryDelay = 20 * time.Second
-)
+const maxRetries = 3
+
+// DefaultRetryOn lists the error substrings retried when Options.RetryOn is
+// empty: rate limits, 5xx responses and per-call timeouts.
+var DefaultRetryOn = []string{
+	"429", "RESOURCE_EXHAUSTED",
+	"500", "502", "503", "504", "INTERNAL", "UNAVAILABLE",
+	"deadline exceeded", "Client.Timeout",
+}
+
+// Enrich sends projects to Gemini, in a single batch unless opts.BatchSize
+// splits them into chunks, and returns enriched projects in input order.
+// Projects with a stub README are built from repo metadata instead. Projects
+// a failed or short batch left out are retried one at a time; those that
+// still fail are skipped with a warning. It only errors when no project
+// could be enriched at all.
+func Enrich(ctx context.Context, opts Options, projects []mapper.RawProject) ([]contentful.Project, error) {
+	if opts.Seed != nil {
+		seedJitter(*opts.Seed)
+	}
+	projects = withAllowedOverrides(projects)
+
+	var toGenerate []mapper.RawProject
+	for _, raw := range projects {
+		if !mapper.IsStubREADME(raw.ReadmeRaw) {
+			toGenerate = append(toGenerate, raw)
+		}
+	}
+
+	var generated []*enrichedData
+	if len(toGenerate) > 0 {
+		generated = make([]*enrichedData, len(toGenerate))
+		var uncached []mapper.RawProject
+		var uncachedIdx []int
+		for i, raw := range toGenerate {
+			if data, ok := opts.Cache.get(opts, raw); ok && !opts.RefreshCache {
+				generated[i] = data
+				continue
+			}
+			uncached = append(uncached, raw)
+			uncachedIdx = append(uncachedIdx, i)
+		}
+		if hits := len(toGenerate) - len(uncached); hits > 0 {
+			log.Printf("  %d projects unchanged since they were cached, skipping Gemini", hits)
+		}
+
+		var batchErr error
+		if len(uncached) > 0 {
+			var fresh []*enrichedData
+			fresh, batchErr = generateChunks(ctx, opts, uncached)
+			if batchErr != nil {
+				log.Printf("WARNING: batch enrichment failed, falling back to one project at a time: %v", batchErr)
+			}
+			for j, i := range uncachedIdx {
+				generated[i] = fresh[j]
+			}
+		}
+
+		missing := 0
+		aborted := false
+		for i, raw := range toGenerate {
+			if generated[i] != nil {
+				continue
+			}
+			if aborted || ctx.Err() != nil {
+				missing++
+				continue
+			}
+			data, err := enrichOne(ctx, opts, raw)
+			if err != nil {
+				log.Printf("WARNING: enriching %s on its own failed: %v", raw.Name, err)
+				missing++
+				if abortsFallback(ctx, err, opts.RetryOn) {
+					log.Printf("WARNING: not retrying the remaining projects one at a time")
+					aborted = true
+				}
+				continue
+			}
+			generated[i] = data
+		}
+		if missing == len(toGenerate) {
+			if batchErr != nil {
+				return nil, batchErr
+			}
+			return nil, fmt.Errorf("gemini returned no data for any project")
+		}
+	}
+
+	// Projects are placed by input index, so regenerated ones land back
+	// where they were
+	placed := make([]*contentful.Project, len(projects))
+	var regenerate []mapper.RawProject
+	var regenerateIdx []int
+	next := 0
+	for i, raw := range projects {
+		if mapper.IsStubREADME(raw.ReadmeRaw) {
+			log.Printf("  %s has a stub README, using repo metadata", raw.Name)
+			p := toProject(opts, raw, fromMetadata(raw))
+			placed[i] = &p
+			continue
+		}
+		data := generated[next]
+		next++
+		if data == nil {
+			log.Printf("WARNING: Gemini did not return data for %s, skipping", raw.Name)
+			continue
+		}
+		if bad := checkURLs(data, raw.GitHubURL, !opts.StrictURLs); len(bad) > 0 {
+			if opts.StrictURLs {
+				log.Printf("WARNING: %s has invented URLs %v, regenerating", raw.Name, bad)
+				regenerate = append(regenerate, raw)
+				regenerateIdx = append(regenerateIdx, i)
+				continue
+			}
+			log.Printf("WARNING: stripped invented URLs from %s: %v", raw.Name, bad)
+		}
+		if !plausibleCategory(opts.CategoryRules, raw, data.Category) {
+			if opts.StrictCategories {
+				log.Printf("WARNING: %s got implausible category %q for %s, regenerating", raw.Name, data.Category, raw.Languages[0])
+				regenerate = append(regenerate, raw)
+				regenerateIdx = append(regenerateIdx, i)
+				continue
+			}
+			log.Printf("WARNING: %s got implausible category %q for %s, correcting", raw.Name, data.Category, raw.Languages[0])
+			correctCategory(opts.CategoryRules, raw, data)
+		}
+		opts.Cache.put(opts, raw, *data)
+		p := toProject(opts, raw, *data)
+		placed[i] = &p
+	}
+
+	if len(regenerate) > 0 {
+		for j, p := range regenerateStrict(ctx, opts, regenerate) {
+			placed[regenerateIdx[j]] = p
+		}
+	}
+	if err := opts.Cache.Save(); err != nil {
+		log.Printf("WARNING: %v", err)
+	}
+
+	var result []contentful.Project
+	for _, p := range placed {
+		if p != nil {
+			result = append(result, *p)
+		}
+	}
+	log.Printf("  Gemini returned data for %d/%d projects", len(result), len(projects))
+	return result, nil
+}
+
+// regenerateStrict re-enriches projects that failed URL or category
+// validation once, dropping any that still reference URLs outside their own
+// repo and correcting any category that is still implausible. The result is
+// aligned with projects; an entry is nil when its project was dropped.
+func regenerateStrict(ctx context.Context, opts Options, projects []mapper.RawProject) []*contentful.Project {
+	dataList, err := generateBatch(ctx, opts, projects)
+	if err != nil {
+		log.Printf("WARNING: regeneration failed, skipping %d projects: %v", len(projects), err)
+		return nil
+	}
+
+	result := make([]*contentful.Project, len(projects))
+	for i, raw := range projects {
+		if i >= len(dataList) {
+			log.Printf("WARNING: Gemini did not regenerate %s, skipping", raw.Name)
+			continue
+		}
+		data := dataList[i]
+		if bad := checkURLs(&data, raw.GitHubURL, false); len(bad) > 0 {
+			log.Printf("WARNING: %s still has invented URLs %v, skipping", raw.Name, bad)
+			continue
+		}
+		if !plausibleCategory(opts.CategoryRules, raw, data.Category) {
+			log.Printf("WARNING: %s still has implausible category %q, correcting", raw.Name, data.Category)
+			correctCategory(opts.CategoryRules, raw, &data)
+		}
+		opts.Cache.put(opts, raw, data)
+		p := toProject(opts, raw, data)
+		result[i] = &p
+	}
+	return result
+}
 
-// Enrich sends all projects to Gemini in a single request and returns enriched projects.
-func Enrich(ctx context.Context, apiKey string, projects []mapper.RawProject) ([]contentful.Project, error) {
-	log.Printf("  Sending %d projects to Gemini in a single batch...", len(projects))
+// generateChunks splits projects into opts.BatchSize chunks and runs up to
+// opts.Concurrency of them at once. The result is aligned with projects; an
+// entry is nil when its chunk failed or Gemini returned fewer items than the
+// chunk held. The returned error is the first chunk error, if any.
+func generateChunks(ctx context.Context, opts Options, projects []mapper.RawProject) ([]*enrichedData, error) {
+	size := opts.BatchSize
+	if size <= 0 || size > len(projects) {
+		size = len(projects)
+	}
+	workers := opts.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
 
+	var chunks [][]mapper.RawProject
+	for start := 0; start < len(projects); start += size {
+		end := min(start+size, len(projects))
+		chunks = append(chunks, projects[start:end])
+	}
+	if len(chunks) == 1 {
+		log.Printf("  Sending %d projects to Gemini in a single batch...", len(projects))
+	} else {
+		log.Printf("  Sending %d projects to Gemini in %d chunks (%d at a time)...", len(projects), len(chunks), workers)
+	}
+
+	chunkData := make([][]enrichedData, len(chunks))
+	chunkErrs := make([]error, len(chunks))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk []mapper.RawProject) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			chunkData[i], chunkErrs[i] = generateBatch(ctx, opts, chunk)
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	// Match by position: Gemini returns items in the same order as the input
+	var firstErr error
+	dataList := make([]*enrichedData, 0, len(projects))
+	for i, chunk := range chunks {
+		if chunkErrs[i] != nil && firstErr == nil {
+			firstErr = chunkErrs[i]
+		}
+		for j := range chunk {
+			if j < len(chunkData[i]) {
+				dataList = append(dataList, &chunkData[i][j])
+			} else {
+				dataList = append(dataList, nil)
+			}
+		}
+	}
+	return dataList, firstErr
+}
+
+// enrichOne sends a single project to Gemini, for projects a batch left out.
+func enrichOne(ctx context.Context, opts Options, project mapper.RawProject) (*enrichedData, error) {
+	dataList, err := generateBatch(ctx, opts, []mapper.RawProject{project})
+	if err != nil {
+		return nil, err
+	}
+	if len(dataList) == 0 {
+		return nil, errNoData
+	}
+	return &dataList[0], nil
+}
+
+var errNoData = errors.New("gemini returned no data")
+
+// abortsFallback reports whether a failed enrichOne call dooms the projects
+// still waiting for theirs: the run was cancelled, or Gemini rejected the
+// request outright (bad key, bad model). A reply that is empty or does not
+// parse only concerns the one project.
+func abortsFallback(ctx context.Context, err error, retryOn []string) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.Is(err, errNoData) || errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return false
+	}
+	return !retryable(err, retryOn)
+}
+
+// generateBatch sends projects to Gemini with retry on rate limits and
+// parses the JSON array response.
+func generateBatch(ctx context.Context, opts Options, projects []mapper.RawProject) ([]enrichedData, error) {
 	userPrompt := buildBatchPrompt(projects)
+	if opts.OnBatch != nil {
+		opts.OnBatch(len(projects))
+	}
+
+	response, err := generateWithRetry(ctx, opts, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataList []enrichedData
+	if err := json.Unmarshal([]byte(response), &dataList); err != nil {
+		return nil, fmt.Errorf("parse gemini response: %w", err)
+	}
+	for i := range dataList {
+		dataList[i].Category = allowedCategory(dataList[i].Name, dataList[i].Category)
+	}
+	return dataList, nil
+}
 
+// generateWithRetry sends one prompt, retrying transient errors with
+// backoff, and returns the response with any markdown fences stripped.
+func generateWithRetry(ctx context.Context, opts Options, system, user string) (string, error) {
 	var response string
 	var lastErr error
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		if attempt > 0 {
-			backoff := retryDelay * time.Duration(attempt)
+			backoff := backoffDuration(attempt)
+			if hint := retryHint(lastErr); hint > 0 {
+				backoff = hint
+			}
 			log.Printf("  Retry %d/%d (waiting %s)...", attempt, maxRetries, backoff)
 			select {
 			case <-time.After(backoff):
 			case <-ctx.Done():
-				return nil, ctx.Err()
+				return "", ctx.Err()
 			}
 		}
 
 		var err error
-		response, err = gemini.GenerateContent(ctx, apiKey, systemPrompt, userPrompt)
+		response, err = generateContent(ctx, opts, system, user)
 		if err == nil {
 			break
 		}
 
 		lastErr = err
-		if !strings.Contains(err.Error(), "429") && !strings.Contains(err.Error(), "RESOURCE_EXHAUSTED") {
-			return nil, fmt.Errorf("gemini: %w", err)
+		if ctx.Err() != nil || !retryable(err, opts.RetryOn) {
+			return "", fmt.Errorf("gemini: %w", err)
 		}
-		log.Printf("  Rate limited, will retry...")
+		log.Printf("  Transient error, will retry: %v", err)
 	}
 
 	if response == "" && lastErr != nil {
-		return nil, fmt.Errorf("gemini after %d retries: %w", maxRetries, lastErr)
+		return "", fmt.Errorf("gemini after %d retries: %w", maxRetries, lastErr)
 	}
+	return stripMarkdownFences(response), nil
+}
 
-	response = stripMarkdownFences(response)
+// retryable reports whether err's text contains any of conditions, or of
+// DefaultRetryOn when conditions is empty.
+func retryable(err error, conditions []string) bool {
+	if len(conditions) == 0 {
+		conditions = DefaultRetryOn
+	}
+	msg := err.Error()
+	for _, c := range conditions {
+		if strings.Contains(msg, c) {
+			return true
+		}
+	}
+	return false
+}
 
-	var dataList []enrichedData
-	if err := json.Unmarshal([]byte(response), &dataList); err != nil {
-		return nil, fmt.Errorf("parse gemini response: %w", err)
+func toProject(opts Options, raw mapper.RawProject, data enrichedData) contentful.Project {
+	if opts.LongDescFromSection && raw.Overview != "" {
+		data.LongDescription = raw.Overview
+	}
+	if raw.CategoryOverride != "" {
+		data.Category = raw.CategoryOverride
 	}
+	data.Technologies = withHints(data.Technologies, raw.TechHints)
+	data.Gradient = gradientFor(data.Gradient, data.Category)
 
-	// Match by position: Gemini returns items in the same order as the input
-	var result []contentful.Project
-	for i, raw := range projects {
-		if i >= len(dataList) {
-			log.Printf("WARNING: Gemini did not return data for %s, skipping", raw.Name)
-			continue
-		}
-		data := dataList[i]
-		result = append(result, contentful.Project{
-			Name:             data.Name,
-			Slug:             raw.Slug,
-			ShortDescription: data.ShortDescription,
-			LongDescription:  data.LongDescription,
-			GithubURL:       raw.GitHubURL,
-			Technologies:    data.Technologies,
-			Highlights:      data.Highlights,
-			Featured:        false,
-			Gradient:        data.Gradient,
-			Category:        data.Category,
-			PushedAt:        raw.PushedAt,
-		})
+	display := mapper.DefaultDisplay()
+	if raw.Display != nil {
+		display = *raw.Display
 	}
 
-	log.Printf("  Gemini returned data for %d/%d projects", len(result), len(projects))
-	return result, nil
+	return contentful.Project{
+		Name:             data.Name,
+		Slug:             raw.Slug,
+		ShortDescription: data.ShortDescription,
+		LongDescription:  data.LongDescription,
+		GithubURL:        raw.GitHubURL,
+		LiveURL:          raw.LiveURL,
+		Technologies:     data.Technologies,
+		Highlights:       data.Highlights,
+		Featured:         false,
+		Gradient:         data.Gradient,
+		Category:         data.Category,
+		SourceCommit:     raw.SourceCommit,
+		Draft:            raw.Draft,
+		PushedAt:         raw.PushedAt,
+		Display: &contentful.Display{
+			ShowDemo:         display.ShowDemo,
+			ShowTechnologies: display.ShowTechnologies,
+			ShowHighlights:   display.ShowHighlights,
+		},
+	}
+}
+
+// Prompts returns the system prompt and the batch user prompt Enrich would
+// send for projects, including README truncation.
+func Prompts(projects []mapper.RawProject) (system, user string) {
+	return systemPrompt, buildBatchPrompt(projects)
 }
 
 func buildBatchPrompt(projects []mapper.RawProject) string {
@@ -130,6 +450,10 @@ func buildBatchPrompt(projects []mapper.RawProject) string {
 		Name      string `json:"name"`
 		Languages string `json:"languages"`
 		Readme    string `json:"readme"`
+		Overview  string `json:"overview,omitempty"`
+
+		Description     string   `json:"description,omitempty"`
+		TechnologyHints []string `json:"technologyHints,omitempty"`
 	}
 
 	entries := make([]repoEntry, len(projects))
@@ -142,6 +466,10 @@ func buildBatchPrompt(projects []mapper.RawProject) string {
 			Name:      p.Name,
 			Languages: strings.Join(p.Languages, ", "),
 			Readme:    readme,
+			Overview:  p.Overview,
+
+			Description:     p.Description,
+			TechnologyHints: p.TechHints,
 		}
 	}
 
@@ -152,6 +480,24 @@ func buildBatchPrompt(projects []mapper.RawProject) string {
 	return string(jsonBytes)
 }
 
+// withHints appends any hinted technology the model left out, matching
+// case-insensitively.
+func withHints(technologies, hints []string) []string {
+	for _, h := range hints {
+		found := false
+		for _, t := range technologies {
+			if strings.EqualFold(t, h) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			technologies = append(technologies, h)
+		}
+	}
+	return technologies
+}
+
 func stripMarkdownFences(s string) string {
 	s = strings.TrimSpace(s)
 	if strings.HasPrefix(s, "```json") {
@@ -0,0 +1,475 @@
+package enricher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/mapper"
+)
+
+// scriptedGenerator answers each enrichment prompt with one item per repo,
+// unless reply returns a canned response or error for the prompt's repos.
+type scriptedGenerator struct {
+	reply func(names []string) (string, error)
+
+	mu    sync.Mutex
+	calls [][]string
+}
+
+func (g *scriptedGenerator) GenerateContent(ctx context.Context, system, user string) (string, error) {
+	var repos []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(user), &repos); err != nil {
+		return "", err
+	}
+	var names []string
+	for _, r := range repos {
+		names = append(names, r.Name)
+	}
+	g.mu.Lock()
+	g.calls = append(g.calls, names)
+	g.mu.Unlock()
+
+	if g.reply != nil {
+		if out, err := g.reply(names); out != "" || err != nil {
+			return out, err
+		}
+	}
+	var out []enrichedData
+	for _, name := range names {
+		out = append(out, enrichedData{
+			Name:             name,
+			ShortDescription: "About " + name,
+			LongDescription:  name + " does things.",
+			Technologies:     []string{"Go"},
+			Category:         "Backend",
+			Gradient:         "from-cyan-500 to-blue-600",
+		})
+	}
+	data, err := json.Marshal(out)
+	return string(data), err
+}
+
+func rawProjects(names ...string) []mapper.RawProject {
+	var out []mapper.RawProject
+	for _, name := range names {
+		out = append(out, mapper.RawProject{
+			Name:      name,
+			Slug:      name,
+			GitHubURL: "https://github.com/octo/" + name,
+			Languages: []string{"Go"},
+			ReadmeRaw: "# " + name + "\n\nA small tool.\n",
+		})
+	}
+	return out
+}
+
+func TestEnrichFallback(t *testing.T) {
+	tests := []struct {
+		name      string
+		reply     func(names []string) (string, error)
+		cancel    bool
+		wantSlugs []string
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "batch succeeds",
+			wantSlugs: []string{"a", "b", "c"},
+			wantCalls: 1,
+		},
+		{
+			name: "one bad reply is retried alone and skipped",
+			reply: func(names []string) (string, error) {
+				for _, n := range names {
+					if n == "a" {
+						return "not json", nil
+					}
+				}
+				return "", nil
+			},
+			wantSlugs: []string{"b", "c"},
+			wantCalls: 4,
+		},
+		{
+			name: "rejected request stops the fallback",
+			reply: func(names []string) (string, error) {
+				return "", errors.New("403 PERMISSION_DENIED")
+			},
+			wantCalls: 2,
+			wantErr:   true,
+		},
+		{
+			name:      "cancelled run stops the fallback",
+			cancel:    true,
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			gen := &scriptedGenerator{reply: tt.reply}
+			if tt.cancel {
+				gen.reply = func(names []string) (string, error) {
+					cancel()
+					return "", context.Canceled
+				}
+			}
+
+			projects, err := Enrich(ctx, Options{Generator: gen}, rawProjects("a", "b", "c"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			var slugs []string
+			for _, p := range projects {
+				slugs = append(slugs, p.Slug)
+			}
+			if fmt.Sprint(slugs) != fmt.Sprint(tt.wantSlugs) {
+				t.Errorf("slugs = %v, want %v", slugs, tt.wantSlugs)
+			}
+			if len(gen.calls) != tt.wantCalls {
+				t.Errorf("made %d calls %v, want %d", len(gen.calls), gen.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestEnrichGeneratorReplies(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = time.Millisecond
+
+	item := func(name string) string {
+		return `{"name":"` + name + `","shortDescription":"About ` + name + `","technologies":["Go"],"category":"Backend","gradient":"from-cyan-500 to-blue-600"}`
+	}
+	tests := []struct {
+		name      string
+		replies   []string // one per call; "429", or running out, fails the call with a rate limit
+		wantSlugs []string
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "canned JSON",
+			replies:   []string{"[" + item("a") + "," + item("b") + "]"},
+			wantSlugs: []string{"a", "b"},
+			wantCalls: 1,
+		},
+		{
+			name:      "fenced JSON",
+			replies:   []string{"```json\n[" + item("a") + "," + item("b") + "]\n```"},
+			wantSlugs: []string{"a", "b"},
+			wantCalls: 1,
+		},
+		{
+			name:      "429 is retried",
+			replies:   []string{"429", "429", "[" + item("a") + "," + item("b") + "]"},
+			wantSlugs: []string{"a", "b"},
+			wantCalls: 3,
+		},
+		{
+			name:      "429 until retries run out",
+			replies:   []string{"429"},
+			wantCalls: 3 * (maxRetries + 1), // the batch, then each project alone
+			wantErr:   true,
+		},
+		{
+			name:      "malformed batch falls back to one at a time",
+			replies:   []string{"{not json", "[" + item("a") + "]", "[" + item("b") + "]"},
+			wantSlugs: []string{"a", "b"},
+			wantCalls: 3,
+		},
+		{
+			name:      "short batch fetches the missing project alone",
+			replies:   []string{"[" + item("a") + "]", "[" + item("b") + "]"},
+			wantSlugs: []string{"a", "b"},
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			gen := &scriptedGenerator{reply: func([]string) (string, error) {
+				reply := "429"
+				if calls < len(tt.replies) {
+					reply = tt.replies[calls]
+				}
+				calls++
+				if reply == "429" {
+					return "", errors.New("Error 429, RESOURCE_EXHAUSTED")
+				}
+				return reply, nil
+			}}
+
+			projects, err := Enrich(context.Background(), Options{Generator: gen}, rawProjects("a", "b"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			var slugs []string
+			for _, p := range projects {
+				slugs = append(slugs, p.Slug)
+			}
+			if fmt.Sprint(slugs) != fmt.Sprint(tt.wantSlugs) {
+				t.Errorf("slugs = %v, want %v", slugs, tt.wantSlugs)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("made %d calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestEnrichDescriptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		reply     string
+		wantShort string
+		wantLong  string
+	}{
+		{
+			name:      "both from Gemini",
+			reply:     `[{"name":"a","shortDescription":"A CLI.","longDescription":"A CLI that syncs things.","technologies":["Go"],"category":"Backend","gradient":"from-cyan-500 to-blue-600"}]`,
+			wantShort: "A CLI.",
+			wantLong:  "A CLI that syncs things.",
+		},
+		{
+			name:      "short only",
+			reply:     `[{"name":"a","shortDescription":"A CLI.","technologies":["Go"],"category":"Backend","gradient":"from-cyan-500 to-blue-600"}]`,
+			wantShort: "A CLI.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := &scriptedGenerator{reply: func([]string) (string, error) { return tt.reply, nil }}
+			projects, err := Enrich(context.Background(), Options{Generator: gen}, rawProjects("a"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(projects) != 1 {
+				t.Fatalf("got %d projects, want 1", len(projects))
+			}
+			if p := projects[0]; p.ShortDescription != tt.wantShort || p.LongDescription != tt.wantLong {
+				t.Errorf("descriptions = %q, %q, want %q, %q", p.ShortDescription, p.LongDescription, tt.wantShort, tt.wantLong)
+			}
+		})
+	}
+}
+
+func TestEnrichDisplay(t *testing.T) {
+	tests := []struct {
+		name    string
+		display *mapper.Display
+		readme  string
+		want    contentful.Display
+	}{
+		{name: "no repo config shows everything", want: contentful.Display{ShowDemo: true, ShowTechnologies: true, ShowHighlights: true}},
+		{name: "demo hidden", display: &mapper.Display{ShowTechnologies: true, ShowHighlights: true}, want: contentful.Display{ShowTechnologies: true, ShowHighlights: true}},
+		{name: "everything hidden", display: &mapper.Display{}, want: contentful.Display{}},
+		{name: "stub README", display: &mapper.Display{ShowDemo: true}, readme: "# a", want: contentful.Display{ShowDemo: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := rawProjects("a")
+			raw[0].Display = tt.display
+			if tt.readme != "" {
+				raw[0].ReadmeRaw = tt.readme
+			}
+
+			projects, err := Enrich(context.Background(), Options{Generator: &scriptedGenerator{}}, raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(projects) != 1 || projects[0].Display == nil {
+				t.Fatalf("projects = %+v, want one with a display", projects)
+			}
+			if *projects[0].Display != tt.want {
+				t.Errorf("Display = %+v, want %+v", *projects[0].Display, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnrichChunkOrdering(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e", "f", "g"}
+	tests := []struct {
+		name        string
+		batchSize   int
+		concurrency int
+		wantCalls   int
+	}{
+		{name: "sequential", batchSize: 2, concurrency: 1, wantCalls: 4},
+		{name: "two in flight", batchSize: 2, concurrency: 2, wantCalls: 4},
+		{name: "all in flight", batchSize: 1, concurrency: 7, wantCalls: 7},
+		{name: "more workers than chunks", batchSize: 3, concurrency: 8, wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			inFlight, peak := 0, 0
+			gen := &scriptedGenerator{reply: func(chunk []string) (string, error) {
+				mu.Lock()
+				inFlight++
+				peak = max(peak, inFlight)
+				mu.Unlock()
+				// Earlier chunks answer last, so completion order is the
+				// reverse of input order whenever chunks overlap.
+				time.Sleep(time.Duration(len(names)-slices.Index(names, chunk[0])) * 5 * time.Millisecond)
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+				return "", nil
+			}}
+
+			opts := Options{Generator: gen, BatchSize: tt.batchSize, Concurrency: tt.concurrency}
+			projects, err := Enrich(context.Background(), opts, rawProjects(names...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var slugs []string
+			for _, p := range projects {
+				slugs = append(slugs, p.Slug)
+				if p.ShortDescription != "About "+p.Slug {
+					t.Errorf("%s got %q, want its own data", p.Slug, p.ShortDescription)
+				}
+			}
+			if !slices.Equal(slugs, names) {
+				t.Errorf("slugs = %v, want input order %v", slugs, names)
+			}
+			if len(gen.calls) != tt.wantCalls {
+				t.Errorf("made %d calls, want %d", len(gen.calls), tt.wantCalls)
+			}
+			if peak > tt.concurrency {
+				t.Errorf("peak in flight = %d, want at most %d", peak, tt.concurrency)
+			}
+			if want := min(tt.concurrency, tt.wantCalls); tt.concurrency > 1 && peak < 2 {
+				t.Errorf("peak in flight = %d, want chunks to overlap (up to %d)", peak, want)
+			}
+		})
+	}
+}
+
+func TestGenerateWithRetry(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = time.Millisecond
+
+	tests := []struct {
+		name     string
+		statuses []int
+		retryOn  []string
+		wantErr  bool
+		wantReqs int
+	}{
+		{name: "503 then success", statuses: []int{503, 200}, wantReqs: 2},
+		{name: "429 then 500 then success", statuses: []int{429, 500, 200}, wantReqs: 3},
+		{name: "400 fails fast", statuses: []int{400, 200}, wantErr: true, wantReqs: 1},
+		{name: "404 fails fast", statuses: []int{404, 200}, wantErr: true, wantReqs: 1},
+		{name: "503 until retries run out", statuses: []int{503, 503, 503, 503, 200}, wantErr: true, wantReqs: maxRetries + 1},
+		{name: "configured conditions replace the defaults", statuses: []int{503, 200}, retryOn: []string{"429"}, wantErr: true, wantReqs: 1},
+		{name: "configured condition retries a 4xx", statuses: []int{409, 200}, retryOn: []string{"409"}, wantReqs: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqs := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[reqs]
+				reqs++
+				if status != http.StatusOK {
+					http.Error(w, http.StatusText(status), status)
+					return
+				}
+				w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"[]"}]}}]}`))
+			}))
+			defer srv.Close()
+
+			opts := Options{APIKey: "key", BaseURL: srv.URL, HTTPClient: srv.Client(), RetryOn: tt.retryOn}
+			got, err := generateWithRetry(context.Background(), opts, "system", "user")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != "[]" {
+				t.Errorf("response = %q, want []", got)
+			}
+			if reqs != tt.wantReqs {
+				t.Errorf("made %d requests, want %d", reqs, tt.wantReqs)
+			}
+		})
+	}
+}
+
+func TestSeededRunsAreIdentical(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = time.Millisecond
+
+	// run enriches the same projects against a server that fails the first
+	// request, so the retry jitter is drawn, and returns the request bodies
+	// and output.
+	run := func(seed int64) ([]string, string, []time.Duration) {
+		var bodies []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body bytes.Buffer
+			body.ReadFrom(r.Body)
+			bodies = append(bodies, body.String())
+			if len(bodies) == 1 {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"[{\"name\":\"a\",\"shortDescription\":\"A CLI.\",\"technologies\":[\"Go\"],\"category\":\"Backend\"},{\"name\":\"b\",\"shortDescription\":\"A web app.\",\"technologies\":[\"TypeScript\"],\"category\":\"Frontend\"}]"}]}}]}`))
+		}))
+		defer srv.Close()
+
+		opts := Options{APIKey: "key", BaseURL: srv.URL, HTTPClient: srv.Client(), Seed: &seed}
+		projects, err := Enrich(context.Background(), opts, rawProjects("a", "b"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := json.Marshal(projects)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var jitter []time.Duration
+		for attempt := 1; attempt <= 3; attempt++ {
+			jitter = append(jitter, backoffDuration(attempt))
+		}
+		return bodies, string(out), jitter
+	}
+
+	tests := []struct {
+		name      string
+		seeds     [2]int64
+		wantEqual bool
+	}{
+		{name: "same seed", seeds: [2]int64{42, 42}, wantEqual: true},
+		{name: "different seeds", seeds: [2]int64{42, 43}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodies1, out1, jitter1 := run(tt.seeds[0])
+			bodies2, out2, jitter2 := run(tt.seeds[1])
+			if out1 != out2 {
+				t.Errorf("outputs differ:\n%s\n%s", out1, out2)
+			}
+			if got := slices.Equal(bodies1, bodies2); got != tt.wantEqual {
+				t.Errorf("identical requests = %v, want %v", got, tt.wantEqual)
+			}
+			if got := slices.Equal(jitter1, jitter2); got != tt.wantEqual {
+				t.Errorf("identical backoff %v and %v = %v, want %v", jitter1, jitter2, got, tt.wantEqual)
+			}
+			if !strings.Contains(bodies1[0], fmt.Sprintf(`"seed":%d`, tt.seeds[0])) {
+				t.Errorf("request %s does not carry the seed", bodies1[0])
+			}
+		})
+	}
+}
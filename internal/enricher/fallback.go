@@ -0,0 +1,78 @@
+package enricher
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/mapper"
+)
+
+const fallbackGradient = "from-slate-500 to-gray-600"
+
+// languageCategories maps a repo's dominant language to the category used
+// when a project is built from metadata alone.
+var languageCategories = map[string]string{
+	"TypeScript": "Web",
+	"JavaScript": "Web",
+	"HTML":       "Web",
+	"CSS":        "Web",
+	"Vue":        "Web",
+	"Svelte":     "Web",
+	"Go":         "Backend",
+	"Python":     "Backend",
+	"Java":       "Backend",
+	"Ruby":       "Backend",
+	"PHP":        "Backend",
+	"Rust":       "Backend",
+	"Swift":      "Mobile",
+	"Kotlin":     "Mobile",
+	"Dart":       "Mobile",
+	"C#":         "Game Dev",
+	"GDScript":   "Game Dev",
+	"Shell":      "DevOps",
+	"Dockerfile": "DevOps",
+	"HCL":        "DevOps",
+}
+
+// fromMetadata builds the enriched fields for a repo whose README has no
+// usable content, from its name, description and languages alone, so no
+// model call is spent on it.
+func fromMetadata(raw mapper.RawProject) enrichedData {
+	data := enrichedData{
+		Name:             humanizeName(raw.Name),
+		ShortDescription: raw.Description,
+		LongDescription:  raw.Description,
+		Technologies:     raw.Languages,
+		Highlights:       []string{},
+		Category:         "Libraries",
+		Gradient:         fallbackGradient,
+	}
+	if len(raw.Languages) > 0 {
+		if c, ok := languageCategories[raw.Languages[0]]; ok {
+			data.Category = c
+		}
+		if data.ShortDescription == "" {
+			data.ShortDescription = "A " + raw.Languages[0] + " project."
+		}
+	}
+	if data.Technologies == nil {
+		data.Technologies = []string{}
+	}
+	return data
+}
+
+// humanizeName turns a hyphenated or underscored repo name into title case
+// ("financial-dashboard" → "Financial Dashboard"). Names containing a dot,
+// such as domains, are kept as they are.
+func humanizeName(name string) string {
+	if strings.Contains(name, ".") {
+		return name
+	}
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' })
+	for i, w := range words {
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		words[i] = string(r)
+	}
+	return strings.Join(words, " ")
+}
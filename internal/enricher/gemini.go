@@ -0,0 +1,192 @@
+package enricher
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alberto-moreno-sa/go-service-kit/gemini"
+	"google.golang.org/genai"
+)
+
+// defaultModel is the model the kit wrapper uses, so requests configured
+// past it (base URL, seed) get the same model unless Model says otherwise.
+const defaultModel = "gemini-2.5-flash"
+
+// Generator sends a system and user prompt to a model and returns its text
+// response. Enrich retries, parses and matches whatever it returns.
+type Generator interface {
+	GenerateContent(ctx context.Context, system, user string) (string, error)
+}
+
+// Options configures how Enrich talks to Gemini.
+type Options struct {
+	APIKey string
+
+	// Generator, when set, replaces the Gemini call, e.g. with a fake
+	// returning canned responses. When nil, requests go to Gemini as
+	// configured by the fields below.
+	Generator Generator
+
+	// BaseURL overrides the Gemini API endpoint (a proxy, regional endpoint,
+	// or local mock). When empty the public endpoint is used.
+	BaseURL string
+
+	// Model selects the Gemini model, e.g. "gemini-1.5-pro". When empty
+	// defaultModel is used.
+	Model string
+
+	// Seed, when set, is sent as the sampling seed (its low 32 bits, as the
+	// API takes) with temperature 0 so identical prompts get identical
+	// answers, and seeds the retry backoff jitter.
+	Seed *int64
+
+	// HTTPClient sends requests that do not go through the kit wrapper;
+	// nil means http.DefaultClient.
+	HTTPClient *http.Client
+
+	// StrictURLs regenerates projects whose text links to GitHub URLs other
+	// than their own repo instead of stripping those URLs.
+	StrictURLs bool
+
+	// RetryOn lists error substrings (status codes or messages) that make
+	// a Gemini call retry with backoff; anything else fails fast. Empty
+	// means DefaultRetryOn.
+	RetryOn []string
+
+	// OnBatch, when set, is called with the batch size before each Gemini
+	// request, including retries of failed URL checks. It may be called
+	// concurrently.
+	OnBatch func(n int)
+
+	// CategoryRules lists the categories plausible for each dominant
+	// language. An implausible category is corrected to the first allowed
+	// one, or regenerated once first when StrictCategories is set.
+	CategoryRules    CategoryRules
+	StrictCategories bool
+
+	// Cache, when set, supplies results for repos enriched before with the
+	// same input and records new ones. RefreshCache skips the lookups but
+	// still records fresh results.
+	Cache        *Cache
+	RefreshCache bool
+
+	// LongDescFromSection uses RawProject.Overview verbatim as the long
+	// description when present, instead of only passing it as a hint.
+	LongDescFromSection bool
+
+	// BatchSize caps how many projects go into one Gemini request; zero
+	// sends everything in a single batch. Concurrency is how many batches
+	// are in flight at once (default 1).
+	BatchSize   int
+	Concurrency int
+}
+
+// geminiGenerator is the production Generator: the kit wrapper, or a genai
+// client of our own when a base URL, model or seed is configured, which the
+// wrapper has no options for.
+type geminiGenerator struct {
+	opts Options
+}
+
+func (g geminiGenerator) GenerateContent(ctx context.Context, system, user string) (string, error) {
+	if g.opts.BaseURL == "" && g.opts.Model == "" && g.opts.Seed == nil {
+		return gemini.GenerateContent(ctx, g.opts.APIKey, system, user)
+	}
+	return generateContentGenAI(ctx, g.opts, system, user)
+}
+
+// generateContent sends a single prompt through opts.Generator, or Gemini
+// when it is nil, and returns the text response.
+func generateContent(ctx context.Context, opts Options, system, user string) (string, error) {
+	if opts.Generator != nil {
+		return opts.Generator.GenerateContent(ctx, system, user)
+	}
+	return geminiGenerator{opts: opts}.GenerateContent(ctx, system, user)
+}
+
+// Ping sends a one-word prompt to check that the API key and model work.
+func Ping(ctx context.Context, opts Options) error {
+	response, err := generateContent(ctx, opts, "Reply with the single word OK.", "ping")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(response) == "" {
+		return fmt.Errorf("gemini returned an empty response")
+	}
+	return nil
+}
+
+// generateContentGenAI calls Gemini through a genai client configured from
+// opts, as the kit wrapper does with its fixed settings. A failed request
+// becomes a *requestError carrying any Retry-After the server sent.
+func generateContentGenAI(ctx context.Context, opts Options, system, user string) (string, error) {
+	httpClient := http.DefaultClient
+	if opts.HTTPClient != nil {
+		httpClient = opts.HTTPClient
+	}
+	recorder := &retryAfterRecorder{next: httpClient.Transport}
+	if recorder.next == nil {
+		recorder.next = http.DefaultTransport
+	}
+	recording := *httpClient
+	recording.Transport = recorder
+
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:      opts.APIKey,
+		Backend:     genai.BackendGeminiAPI,
+		HTTPClient:  &recording,
+		HTTPOptions: genai.HTTPOptions{BaseURL: opts.BaseURL},
+	})
+	if err != nil {
+		return "", fmt.Errorf("gemini client: %w", err)
+	}
+
+	model := opts.Model
+	if model == "" {
+		model = defaultModel
+	}
+	config := &genai.GenerateContentConfig{
+		SystemInstruction: &genai.Content{Parts: []*genai.Part{{Text: system}}},
+	}
+	if opts.Seed != nil {
+		config.Seed = genai.Ptr(int32(*opts.Seed))
+		config.Temperature = genai.Ptr[float32](0)
+	}
+
+	result, err := client.Models.GenerateContent(ctx, model, genai.Text(user), config)
+	if err != nil {
+		var apiErr genai.APIError
+		if errors.As(err, &apiErr) {
+			return "", &requestError{
+				Status:     apiErr.Code,
+				Body:       apiErr.Message,
+				RetryAfter: cmp.Or(recorder.retryAfter, detailsRetryDelay(apiErr.Details)),
+			}
+		}
+		return "", fmt.Errorf("gemini generate: %w", err)
+	}
+	if len(result.Candidates) == 0 {
+		return "", fmt.Errorf("gemini returned no candidates")
+	}
+	return strings.TrimSpace(result.Text()), nil
+}
+
+// retryAfterRecorder keeps the Retry-After of the last failed response,
+// which genai drops when it turns the response into an APIError.
+type retryAfterRecorder struct {
+	next       http.RoundTripper
+	retryAfter time.Duration
+}
+
+func (r *retryAfterRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err == nil && resp.StatusCode >= 300 {
+		r.retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	}
+	return resp, err
+}
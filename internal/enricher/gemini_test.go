@@ -0,0 +1,123 @@
+package enricher
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGenerateContentBaseURL(t *testing.T) {
+	seed := int64(7)
+	tests := []struct {
+		name     string
+		path     string
+		model    string
+		seed     *int64
+		wantPath string
+	}{
+		{name: "default model", path: "/proxy", wantPath: "/proxy/v1beta/models/gemini-2.5-flash:generateContent"},
+		{name: "trailing slash", path: "/proxy/", wantPath: "/proxy/v1beta/models/" + defaultModel + ":generateContent"},
+		{name: "model override", model: "gemini-1.5-pro", wantPath: "/v1beta/models/gemini-1.5-pro:generateContent"},
+		{name: "seeded", seed: &seed, wantPath: "/v1beta/models/" + defaultModel + ":generateContent"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath, gotKey string
+			type content struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			}
+			var gotBody struct {
+				SystemInstruction content   `json:"systemInstruction"`
+				Contents          []content `json:"contents"`
+				GenerationConfig  *struct {
+					Seed        *int64   `json:"seed"`
+					Temperature *float64 `json:"temperature"`
+				} `json:"generationConfig"`
+			}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath, gotKey = r.URL.Path, r.Header.Get("x-goog-api-key")
+				if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+					t.Error(err)
+				}
+				w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"OK"}]}}]}`))
+			}))
+			defer srv.Close()
+
+			opts := Options{APIKey: "key", BaseURL: srv.URL + tt.path, Model: tt.model, Seed: tt.seed, HTTPClient: srv.Client()}
+			got, err := generateContent(context.Background(), opts, "system prompt", "user prompt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != "OK" {
+				t.Errorf("response = %q, want OK", got)
+			}
+			if gotPath != tt.wantPath || gotKey != "key" {
+				t.Errorf("request to %q with key %q, want %q with key", gotPath, gotKey, tt.wantPath)
+			}
+			if gotBody.SystemInstruction.Parts[0].Text != "system prompt" || gotBody.Contents[0].Parts[0].Text != "user prompt" {
+				t.Errorf("body = %+v", gotBody)
+			}
+			if gc := gotBody.GenerationConfig; tt.seed != nil {
+				if gc == nil || gc.Seed == nil || *gc.Seed != *tt.seed || gc.Temperature == nil || *gc.Temperature != 0 {
+					t.Errorf("generationConfig = %+v, want seed %d at temperature 0", gc, *tt.seed)
+				}
+			} else if gc != nil && (gc.Seed != nil || gc.Temperature != nil) {
+				t.Errorf("generationConfig = %+v, want no seed or temperature", gc)
+			}
+		})
+	}
+}
+
+func TestGenerateContentBaseURLError(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		body       string
+		wantBody   string
+		wantHint   time.Duration
+	}{
+		{name: "plain body with Retry-After", retryAfter: "3", body: "quota", wantBody: "quota", wantHint: 3 * time.Second},
+		{
+			name:     "RetryInfo in the details",
+			body:     `{"error":{"code":429,"message":"Quota exceeded","status":"RESOURCE_EXHAUSTED","details":[{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay":"37s"}]}}`,
+			wantBody: "Quota exceeded",
+			wantHint: 37 * time.Second,
+		},
+		{
+			name:       "Retry-After wins over RetryInfo",
+			retryAfter: "2",
+			body:       `{"error":{"code":429,"message":"Quota exceeded","details":[{"retryDelay":"37s"}]}}`,
+			wantBody:   "Quota exceeded",
+			wantHint:   2 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			err := Ping(context.Background(), Options{APIKey: "key", BaseURL: srv.URL, HTTPClient: srv.Client()})
+			reqErr, ok := err.(*requestError)
+			if !ok {
+				t.Fatalf("err = %v, want a *requestError", err)
+			}
+			if reqErr.Status != http.StatusTooManyRequests || reqErr.Body != tt.wantBody {
+				t.Errorf("err = %+v, want status 429 and body %q", reqErr, tt.wantBody)
+			}
+			if got := retryHint(err); got != tt.wantHint {
+				t.Errorf("retryHint = %s, want %s", got, tt.wantHint)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package enricher
+
+import (
+	"regexp"
+	"strings"
+)
+
+// categoryGradients is the default gradient for each allowed category, used
+// when Gemini's gradient is unusable.
+var categoryGradients = map[string]string{
+	"Web":        "from-purple-500 to-indigo-600",
+	"Backend":    "from-emerald-500 to-teal-600",
+	"Full-Stack": "from-amber-500 to-orange-600",
+	"Libraries":  "from-slate-500 to-gray-600",
+	"DevOps":     "from-cyan-500 to-blue-600",
+	"Game Dev":   "from-red-500 to-rose-600",
+	"Mobile":     "from-pink-500 to-fuchsia-600",
+}
+
+var gradientRe = regexp.MustCompile(`^from-([a-z]+)-\d{2,3} to-([a-z]+)-\d{2,3}$`)
+
+// validateGradient strips quotes, backticks and extra whitespace from s and
+// reports whether what is left is a from-{color}-N to-{color}-N gradient. A
+// valid gradient is returned with its shades normalized to 500 and 600.
+func validateGradient(s string) (string, bool) {
+	s = strings.ToLower(strings.Join(strings.Fields(strings.Trim(s, "`\"' \t\n")), " "))
+	m := gradientRe.FindStringSubmatch(s)
+	if m == nil {
+		return "", false
+	}
+	return "from-" + m[1] + "-500 to-" + m[2] + "-600", true
+}
+
+// gradientFor returns gradient when valid, otherwise the default for
+// category, or fallbackGradient for an unknown category.
+func gradientFor(gradient, category string) string {
+	if g, ok := validateGradient(gradient); ok {
+		return g
+	}
+	if g, ok := categoryGradients[category]; ok {
+		return g
+	}
+	return fallbackGradient
+}
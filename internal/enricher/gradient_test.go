@@ -0,0 +1,94 @@
+package enricher
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestValidateGradient(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{in: "from-cyan-500 to-blue-600", want: "from-cyan-500 to-blue-600", wantOK: true},
+		{in: "`from-cyan-500 to-blue-600`", want: "from-cyan-500 to-blue-600", wantOK: true},
+		{in: `"from-cyan-500 to-blue-600"`, want: "from-cyan-500 to-blue-600", wantOK: true},
+		{in: "  from-Cyan-500   to-Blue-600\n", want: "from-cyan-500 to-blue-600", wantOK: true},
+		{in: "from-cyan-400 to-blue-700", want: "from-cyan-500 to-blue-600", wantOK: true},
+		{in: "bg-gradient-to-r from-cyan-500 to-blue-600"},
+		{in: "from-cyan-500"},
+		{in: "from-cyan to-blue"},
+		{in: "from-cyan-5000 to-blue-600"},
+		{in: "#00ffff"},
+		{in: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, ok := validateGradient(tt.in)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("validateGradient(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGradientFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		gradient string
+		category string
+		want     string
+	}{
+		{name: "valid gradient kept", gradient: "from-red-500 to-rose-600", category: "Web", want: "from-red-500 to-rose-600"},
+		{name: "invalid uses category default", gradient: "red", category: "Web", want: "from-purple-500 to-indigo-600"},
+		{name: "empty uses category default", category: "Game Dev", want: "from-red-500 to-rose-600"},
+		{name: "unknown category", gradient: "red", category: "Other", want: fallbackGradient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gradientFor(tt.gradient, tt.category); got != tt.want {
+				t.Errorf("gradientFor(%q, %q) = %q, want %q", tt.gradient, tt.category, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCategoryGradientsValid(t *testing.T) {
+	for _, c := range AllowedCategories {
+		g, ok := categoryGradients[c]
+		if !ok {
+			t.Errorf("no default gradient for %s", c)
+			continue
+		}
+		if got, valid := validateGradient(g); !valid || got != g {
+			t.Errorf("default gradient for %s = %q, which is not normalized", c, g)
+		}
+	}
+}
+
+func TestEnrichSanitizesGradient(t *testing.T) {
+	tests := []struct {
+		gradient string
+		want     string
+	}{
+		{gradient: "from-cyan-500 to-blue-600", want: "from-cyan-500 to-blue-600"},
+		{gradient: "`from-amber-500 to-orange-600`", want: "from-amber-500 to-orange-600"},
+		{gradient: "linear-gradient(red, blue)", want: "from-cyan-500 to-blue-600"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.gradient, func(t *testing.T) {
+			gen := &scriptedGenerator{reply: func([]string) (string, error) {
+				return fmt.Sprintf(`[{"name":"a","shortDescription":"A CLI.","technologies":["Go"],"category":"DevOps","gradient":%q}]`, tt.gradient), nil
+			}}
+			projects, err := Enrich(context.Background(), Options{Generator: gen}, rawProjects("a"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(projects) != 1 || projects[0].Gradient != tt.want {
+				t.Fatalf("projects = %+v, want gradient %q", projects, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package enricher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+const translatePrompt = `You are translating a software engineer's portfolio website into %s.
+You will receive a JSON array of projects. For EACH project, translate
+"shortDescription", "longDescription" and every entry of "highlights".
+Keep "slug" unchanged, keep technology and product names untranslated, and
+keep each highlight under 60 characters.
+
+Return ONLY a valid JSON array with one object per project, with the keys
+"slug", "shortDescription", "longDescription" and "highlights". No markdown,
+no explanation.`
+
+// Translation is one project's text in another locale.
+type Translation struct {
+	Slug             string   `json:"slug"`
+	ShortDescription string   `json:"shortDescription"`
+	LongDescription  string   `json:"longDescription"`
+	Highlights       []string `json:"highlights"`
+}
+
+// TranslationOf returns p's translatable text.
+func TranslationOf(p contentful.Project) Translation {
+	return Translation{
+		Slug:             p.Slug,
+		ShortDescription: p.ShortDescription,
+		LongDescription:  p.LongDescription,
+		Highlights:       p.Highlights,
+	}
+}
+
+// Apply returns p with its text replaced by the translation.
+func (t Translation) Apply(p contentful.Project) contentful.Project {
+	p.ShortDescription = t.ShortDescription
+	p.LongDescription = t.LongDescription
+	p.Highlights = t.Highlights
+	return p
+}
+
+// Translate asks Gemini for the text of projects in locale, e.g. "es-ES",
+// and returns the translations by slug. Projects are sent in opts.BatchSize
+// chunks, one after another. Projects the response leaves out, returns with
+// empty text, or whose chunk failed are missing from the map; it only
+// errors when every chunk failed.
+func Translate(ctx context.Context, opts Options, projects []contentful.Project, locale string) (map[string]Translation, error) {
+	size := opts.BatchSize
+	if size <= 0 || size > len(projects) {
+		size = len(projects)
+	}
+
+	bySlug := make(map[string]Translation, len(projects))
+	var firstErr error
+	chunks, failed := 0, 0
+	for start := 0; start < len(projects); start += size {
+		chunks++
+		chunk := projects[start:min(start+size, len(projects))]
+		translated, err := translateChunk(ctx, opts, chunk, locale)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+			if ctx.Err() != nil {
+				break
+			}
+			log.Printf("WARNING: translating %d projects to %s failed: %v", len(chunk), locale, err)
+			continue
+		}
+		for _, t := range translated {
+			if t.ShortDescription == "" || t.LongDescription == "" {
+				continue
+			}
+			bySlug[t.Slug] = t
+		}
+	}
+	if failed == chunks {
+		return nil, firstErr
+	}
+	return bySlug, nil
+}
+
+// translateChunk sends one chunk of projects to Gemini for translation.
+func translateChunk(ctx context.Context, opts Options, projects []contentful.Project, locale string) ([]Translation, error) {
+	input := make([]Translation, len(projects))
+	for i, p := range projects {
+		input[i] = TranslationOf(p)
+	}
+	user, err := json.MarshalIndent(input, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal projects: %w", err)
+	}
+	if opts.OnBatch != nil {
+		opts.OnBatch(len(projects))
+	}
+
+	response, err := generateWithRetry(ctx, opts, fmt.Sprintf(translatePrompt, locale), string(user))
+	if err != nil {
+		return nil, err
+	}
+
+	var translated []Translation
+	if err := json.Unmarshal([]byte(response), &translated); err != nil {
+		return nil, fmt.Errorf("parse gemini response: %w", err)
+	}
+	return translated, nil
+}
@@ -0,0 +1,75 @@
+package enricher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+type generatorFunc func(ctx context.Context, system, user string) (string, error)
+
+func (f generatorFunc) GenerateContent(ctx context.Context, system, user string) (string, error) {
+	return f(ctx, system, user)
+}
+
+func TestTranslate(t *testing.T) {
+	projects := []contentful.Project{
+		{Slug: "a", ShortDescription: "A", LongDescription: "A long"},
+		{Slug: "b", ShortDescription: "B", LongDescription: "B long"},
+		{Slug: "c", ShortDescription: "C", LongDescription: "C long"},
+	}
+
+	tests := []struct {
+		name      string
+		batchSize int
+		fail      string
+		wantCalls int
+		wantSlugs []string
+		wantErr   bool
+	}{
+		{name: "single batch", wantCalls: 1, wantSlugs: []string{"a", "b", "c"}},
+		{name: "chunks", batchSize: 2, wantCalls: 2, wantSlugs: []string{"a", "b", "c"}},
+		{name: "failed chunk is left out", batchSize: 2, fail: "c", wantCalls: 2, wantSlugs: []string{"a", "b"}},
+		{name: "every chunk failed", fail: "a", wantCalls: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			gen := generatorFunc(func(ctx context.Context, system, user string) (string, error) {
+				calls++
+				var in []Translation
+				if err := json.Unmarshal([]byte(user), &in); err != nil {
+					return "", err
+				}
+				for i := range in {
+					if in[i].Slug == tt.fail {
+						return "", errors.New("400 invalid request")
+					}
+					in[i].ShortDescription = "es " + in[i].ShortDescription
+				}
+				out, err := json.Marshal(in)
+				return string(out), err
+			})
+
+			got, err := Translate(context.Background(), Options{Generator: gen, BatchSize: tt.batchSize}, projects, "es-ES")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("made %d calls, want %d", calls, tt.wantCalls)
+			}
+			if len(got) != len(tt.wantSlugs) {
+				t.Errorf("got %d translations, want %d", len(got), len(tt.wantSlugs))
+			}
+			for _, slug := range tt.wantSlugs {
+				if !strings.HasPrefix(got[slug].ShortDescription, "es ") {
+					t.Errorf("%s = %q, want a translation", slug, got[slug].ShortDescription)
+				}
+			}
+		})
+	}
+}
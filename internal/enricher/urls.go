@@ -0,0 +1,82 @@
+package enricher
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	urlRe          = regexp.MustCompile(`https?://[^\s<>()\[\]"'` + "`" + `]+`)
+	markdownLinkRe = regexp.MustCompile(`\[([^\]]*)\]\(\s*(https?://[^)\s]+)\s*\)`)
+	multiSpaceRe   = regexp.MustCompile(`[ \t]{2,}`)
+	strippedURLRe  = regexp.MustCompile(`[ \t]*\x00`)
+)
+
+// checkURLs finds github.com URLs in the generated text that point outside
+// the project's own repo. When strip is set they are removed in place
+// (markdown links keep their text). Non-GitHub URLs are left alone.
+func checkURLs(data *enrichedData, repoURL string, strip bool) []string {
+	var bad []string
+	check := func(s string) string {
+		for _, u := range urlRe.FindAllString(s, -1) {
+			u = strings.TrimRight(u, ".,;:!?")
+			if isForeignGitHubURL(u, repoURL) {
+				bad = append(bad, u)
+			}
+		}
+		if !strip || len(bad) == 0 {
+			return s
+		}
+		return stripForeignURLs(s, repoURL)
+	}
+
+	data.ShortDescription = check(data.ShortDescription)
+	data.LongDescription = check(data.LongDescription)
+	for i, h := range data.Highlights {
+		data.Highlights[i] = check(h)
+	}
+	return bad
+}
+
+func stripForeignURLs(s, repoURL string) string {
+	s = markdownLinkRe.ReplaceAllStringFunc(s, func(m string) string {
+		parts := markdownLinkRe.FindStringSubmatch(m)
+		if isForeignGitHubURL(parts[2], repoURL) {
+			return parts[1]
+		}
+		return m
+	})
+	s = urlRe.ReplaceAllStringFunc(s, func(u string) string {
+		trimmed := strings.TrimRight(u, ".,;:!?")
+		if isForeignGitHubURL(trimmed, repoURL) {
+			// Marks the gap so trailing punctuation closes up on the word
+			// before the URL.
+			return "\x00" + u[len(trimmed):]
+		}
+		return u
+	})
+	s = strippedURLRe.ReplaceAllString(s, "")
+	return strings.TrimSpace(multiSpaceRe.ReplaceAllString(s, " "))
+}
+
+// isForeignGitHubURL reports whether raw is a github.com URL that is not the
+// repo at repoURL or a path below it.
+func isForeignGitHubURL(raw, repoURL string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Host)
+	if host != "github.com" && host != "www.github.com" {
+		return false
+	}
+
+	repo, err := url.Parse(repoURL)
+	if err != nil || repo.Path == "" {
+		return true
+	}
+	repoPath := strings.ToLower(strings.TrimSuffix(repo.Path, "/"))
+	p := strings.ToLower(strings.TrimSuffix(u.Path, "/"))
+	return p != repoPath && !strings.HasPrefix(p, repoPath+"/")
+}
@@ -0,0 +1,167 @@
+package enricher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestCheckURLs(t *testing.T) {
+	const repo = "https://github.com/octo/tool"
+	tests := []struct {
+		name     string
+		text     string
+		strip    bool
+		wantBad  []string
+		wantText string
+	}{
+		{name: "own repo", text: "See https://github.com/octo/tool.", wantText: "See https://github.com/octo/tool."},
+		{name: "own repo path, any case", text: "Docs at https://GitHub.com/Octo/Tool/tree/main/docs", wantText: "Docs at https://GitHub.com/Octo/Tool/tree/main/docs"},
+		{name: "non-GitHub URL", text: "Live at https://tool.dev", wantText: "Live at https://tool.dev"},
+		{
+			name:     "planted repo is flagged",
+			text:     "Fork of https://github.com/octo/other.",
+			wantBad:  []string{"https://github.com/octo/other"},
+			wantText: "Fork of https://github.com/octo/other.",
+		},
+		{
+			name:     "planted repo is stripped",
+			text:     "Fork of https://github.com/octo/other.",
+			strip:    true,
+			wantBad:  []string{"https://github.com/octo/other"},
+			wantText: "Fork of.",
+		},
+		{
+			name:     "mid-sentence URL leaves one space",
+			text:     "Wraps https://github.com/acme/engine for speed",
+			strip:    true,
+			wantBad:  []string{"https://github.com/acme/engine"},
+			wantText: "Wraps for speed",
+		},
+		{
+			name:     "repo name prefix is foreign",
+			text:     "Uses https://www.github.com/octo/toolkit",
+			strip:    true,
+			wantBad:  []string{"https://www.github.com/octo/toolkit"},
+			wantText: "Uses",
+		},
+		{
+			name:     "markdown link keeps its text",
+			text:     "Built on [the engine](https://github.com/acme/engine) and [docs](https://tool.dev).",
+			strip:    true,
+			wantBad:  []string{"https://github.com/acme/engine"},
+			wantText: "Built on the engine and [docs](https://tool.dev).",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := enrichedData{ShortDescription: tt.text, LongDescription: tt.text, Highlights: []string{tt.text}}
+			bad := checkURLs(&data, repo, tt.strip)
+
+			var wantBad []string
+			for i := 0; i < 3; i++ {
+				wantBad = append(wantBad, tt.wantBad...)
+			}
+			if !reflect.DeepEqual(bad, wantBad) {
+				t.Errorf("bad = %v, want %v", bad, wantBad)
+			}
+			for _, got := range []string{data.ShortDescription, data.LongDescription, data.Highlights[0]} {
+				if got != tt.wantText {
+					t.Errorf("text = %q, want %q", got, tt.wantText)
+				}
+			}
+		})
+	}
+}
+
+func TestEnrichStrictURLs(t *testing.T) {
+	planted := func(name string) string {
+		return fmt.Sprintf(`[{"name":%q,"shortDescription":"A CLI.","longDescription":"Based on https://github.com/fake/%s.","highlights":["See https://github.com/fake/repo"],"technologies":["Go"],"category":"Backend","gradient":"from-cyan-500 to-blue-600"}]`, name, name)
+	}
+	tests := []struct {
+		name      string
+		strict    bool
+		always    bool
+		wantSlugs []string
+		wantCalls int
+		wantLong  string
+	}{
+		{name: "stripped with a warning", wantSlugs: []string{"a"}, wantCalls: 1, wantLong: "Based on."},
+		{name: "strict regenerates", strict: true, wantSlugs: []string{"a"}, wantCalls: 2, wantLong: "a does things."},
+		{name: "strict drops a repeat offender", strict: true, always: true, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			gen := &scriptedGenerator{reply: func(names []string) (string, error) {
+				calls++
+				if calls == 1 || tt.always {
+					return planted(names[0]), nil
+				}
+				return "", nil
+			}}
+			projects, err := Enrich(context.Background(), Options{Generator: gen, StrictURLs: tt.strict}, rawProjects("a"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var slugs []string
+			for _, p := range projects {
+				slugs = append(slugs, p.Slug)
+			}
+			if fmt.Sprint(slugs) != fmt.Sprint(tt.wantSlugs) {
+				t.Errorf("slugs = %v, want %v", slugs, tt.wantSlugs)
+			}
+			if len(gen.calls) != tt.wantCalls {
+				t.Errorf("made %d calls, want %d", len(gen.calls), tt.wantCalls)
+			}
+			if len(projects) == 1 {
+				if projects[0].LongDescription != tt.wantLong {
+					t.Errorf("longDescription = %q, want %q", projects[0].LongDescription, tt.wantLong)
+				}
+				for _, h := range projects[0].Highlights {
+					if h != "See" {
+						t.Errorf("highlight = %q, want the URL gone", h)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEnrichStrictURLsKeepsInputOrder(t *testing.T) {
+	calls := 0
+	gen := &scriptedGenerator{reply: func(names []string) (string, error) {
+		calls++
+		if calls > 1 {
+			return "", nil
+		}
+		var out []enrichedData
+		for _, name := range names {
+			out = append(out, enrichedData{
+				Name: name, ShortDescription: "About " + name, LongDescription: "Based on https://github.com/fake/" + name + ".",
+				Technologies: []string{"Go"}, Category: "Backend", Gradient: "from-cyan-500 to-blue-600",
+			})
+			if name != "a" {
+				out[len(out)-1].LongDescription = name + " does things."
+			}
+		}
+		data, err := json.Marshal(out)
+		return string(data), err
+	}}
+	projects, err := Enrich(context.Background(), Options{Generator: gen, StrictURLs: true}, rawProjects("a", "b", "c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var slugs []string
+	for _, p := range projects {
+		slugs = append(slugs, p.Slug)
+	}
+	if want := []string{"a", "b", "c"}; fmt.Sprint(slugs) != fmt.Sprint(want) {
+		t.Errorf("slugs = %v, want the regenerated project in its input position: %v", slugs, want)
+	}
+	if len(gen.calls) != 2 || fmt.Sprint(gen.calls[1]) != "[a]" {
+		t.Errorf("calls = %v, want the batch and then a alone", gen.calls)
+	}
+}
@@ -0,0 +1,305 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	githubapi "github.com/alberto-moreno-sa/go-service-kit/github"
+)
+
+const apiBaseURL = "https://api.github.com"
+
+// Client embeds the SDK client and adds sync-specific methods.
+type Client struct {
+	*githubapi.Client
+
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a new GitHub client with SDK and commit lookup support.
+// httpClient is used for requests made outside the SDK; nil falls back to a
+// client with a 30s timeout.
+func NewClient(token string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Client{
+		Client:     githubapi.NewClient(token),
+		token:      token,
+		httpClient: httpClient,
+	}
+}
+
+// Repo is the SDK repo plus the fields the sync filters on that the SDK does
+// not decode.
+type Repo struct {
+	githubapi.Repo
+
+	IsTemplate      bool      `json:"is_template"`
+	CreatedAt       time.Time `json:"created_at"`
+	StargazersCount int       `json:"stargazers_count"`
+}
+
+// ListRepos returns the user's public repositories, like the SDK's
+// ListRepos, decoded into Repo so the extra fields are kept.
+func (c *Client) ListRepos(ctx context.Context, username string) ([]Repo, error) {
+	endpoint := fmt.Sprintf("%s/users/%s/repos?type=public&sort=updated&per_page=100", apiBaseURL, username)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("GitHub list repos failed (%d): could not read body: %w", resp.StatusCode, err)
+		}
+		return nil, fmt.Errorf("GitHub list repos failed (%d): %s", resp.StatusCode, body)
+	}
+
+	var repos []Repo
+	if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
+		return nil, fmt.Errorf("decode repos: %w", err)
+	}
+	return repos, nil
+}
+
+// GetHeadSHA returns the commit SHA at the head of the repo's default branch.
+func (c *Client) GetHeadSHA(ctx context.Context, owner, repo string) (string, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/commits/HEAD", apiBaseURL, owner, repo)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github.sha")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read commit response: %w", err)
+	}
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("GitHub commit lookup failed (%d): %s", resp.StatusCode, string(body))
+	}
+
+	return strings.TrimSpace(string(body)), nil
+}
+
+// GetREADME returns the repo's README as raw text, reading at most maxBytes
+// of the body so an oversized README cannot stall the caller. Anything past
+// the cap is dropped. A repo without a README yields an empty string, not an
+// error.
+func (c *Client) GetREADME(ctx context.Context, owner, repo string, maxBytes int64) (string, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/readme", apiBaseURL, owner, repo)
+	body, status, err := c.getRaw(ctx, endpoint, maxBytes)
+	if err != nil {
+		return "", err
+	}
+	switch status {
+	case 200:
+		return body, nil
+	case 404:
+		return "", nil
+	}
+	return "", fmt.Errorf("GitHub readme lookup failed (%d): %s", status, body)
+}
+
+// GetFile returns the raw contents of path on the repo's default branch,
+// capped at maxBytes like GetREADME. found is false when the file does not
+// exist.
+func (c *Client) GetFile(ctx context.Context, owner, repo, path string, maxBytes int64) (content string, found bool, err error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/contents/%s", apiBaseURL, owner, repo, strings.TrimPrefix(path, "/"))
+	body, status, err := c.getRaw(ctx, endpoint, maxBytes)
+	if err != nil {
+		return "", false, err
+	}
+	switch status {
+	case 200:
+		return body, true, nil
+	case 404:
+		return "", false, nil
+	}
+	return "", false, fmt.Errorf("GitHub file lookup failed (%d): %s", status, body)
+}
+
+// getRaw GETs endpoint with the raw media type and returns at most maxBytes
+// of the body along with the status code.
+func (c *Client) getRaw(ctx context.Context, endpoint string, maxBytes int64) (string, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.raw")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes))
+	if err != nil {
+		return "", 0, fmt.Errorf("read response: %w", err)
+	}
+	return string(body), resp.StatusCode, nil
+}
+
+// RateLimit is a snapshot of the core REST API rate limit.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// Used returns how many requests were spent between an earlier snapshot and
+// r. It is zero when the window reset in between, since the two counts are
+// no longer comparable.
+func (r RateLimit) Used(since RateLimit) int {
+	if !r.Reset.Equal(since.Reset) || since.Remaining < r.Remaining {
+		return 0
+	}
+	return since.Remaining - r.Remaining
+}
+
+// GetRateLimit reads the X-RateLimit-* headers from GET /rate_limit, which
+// does not count against the limit itself.
+func (c *Client) GetRateLimit(ctx context.Context) (RateLimit, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", apiBaseURL+"/rate_limit", nil)
+	if err != nil {
+		return RateLimit{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return RateLimit{}, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != 200 {
+		return RateLimit{}, fmt.Errorf("GitHub rate limit lookup failed (%d)", resp.StatusCode)
+	}
+	return parseRateLimit(resp.Header)
+}
+
+func parseRateLimit(h http.Header) (RateLimit, error) {
+	limit, err := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return RateLimit{}, fmt.Errorf("X-RateLimit-Limit: %w", err)
+	}
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimit{}, fmt.Errorf("X-RateLimit-Remaining: %w", err)
+	}
+	reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return RateLimit{}, fmt.Errorf("X-RateLimit-Reset: %w", err)
+	}
+	return RateLimit{Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0)}, nil
+}
+
+// GetAuthenticatedUser returns the login the token authenticates as, via
+// GET /user.
+func (c *Client) GetAuthenticatedUser(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", apiBaseURL+"/user", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", fmt.Errorf("GitHub user lookup failed (%d): could not read body: %w", resp.StatusCode, err)
+		}
+		return "", fmt.Errorf("GitHub user lookup failed (%d): %s", resp.StatusCode, body)
+	}
+
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return "", fmt.Errorf("decode user: %w", err)
+	}
+	return user.Login, nil
+}
+
+// HasTags reports whether the repo has at least one git tag. Every GitHub
+// release is backed by a tag, so this also covers releases.
+func (c *Client) HasTags(ctx context.Context, owner, repo string) (bool, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/tags?per_page=1", apiBaseURL, owner, repo)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return false, fmt.Errorf("GitHub tags lookup failed (%d): could not read body: %w", resp.StatusCode, err)
+		}
+		return false, fmt.Errorf("GitHub tags lookup failed (%d): %s", resp.StatusCode, string(body))
+	}
+
+	var tags []struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return false, fmt.Errorf("decode tags: %w", err)
+	}
+	return len(tags) > 0, nil
+}
@@ -0,0 +1,114 @@
+package github
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// roundTripFunc stands in for the GitHub API.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func respond(status int, header http.Header, body string) *http.Response {
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+func rateHeaders(limit, remaining int, reset int64) http.Header {
+	h := make(http.Header)
+	h.Set("X-RateLimit-Limit", strconv.Itoa(limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+	return h
+}
+
+func TestRateLimitUsed(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after http.Header
+		want          int
+	}{
+		{"spent within window", rateHeaders(5000, 4990, 1700000000), rateHeaders(5000, 4870, 1700000000), 120},
+		{"nothing spent", rateHeaders(5000, 4990, 1700000000), rateHeaders(5000, 4990, 1700000000), 0},
+		{"window reset", rateHeaders(5000, 10, 1700000000), rateHeaders(5000, 4990, 1700003600), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			c := NewClient("token", &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path != "/rate_limit" {
+					t.Errorf("unexpected request to %s", req.URL.Path)
+				}
+				calls++
+				if calls == 1 {
+					return respond(200, tt.before, "{}"), nil
+				}
+				return respond(200, tt.after, "{}"), nil
+			})})
+
+			before, err := c.GetRateLimit(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			after, err := c.GetRateLimit(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := after.Used(before); got != tt.want {
+				t.Errorf("Used() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRateLimitMissingHeaders(t *testing.T) {
+	c := NewClient("", &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return respond(200, nil, "{}"), nil
+	})})
+	if _, err := c.GetRateLimit(context.Background()); err == nil {
+		t.Error("GetRateLimit() without headers should fail")
+	}
+}
+
+func TestGetREADME(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		maxBytes int64
+		want     string
+		wantErr  bool
+	}{
+		{"found", 200, "# app\n\nDoes things.", 1024, "# app\n\nDoes things.", false},
+		{"capped at maxBytes", 200, "# app\n\nDoes things.", 5, "# app", false},
+		{"no README", 404, `{"message":"Not Found"}`, 1024, "", false},
+		{"server error", 502, "bad gateway", 1024, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient("", &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path != "/repos/octo/app/readme" {
+					t.Errorf("unexpected request to %s", req.URL.Path)
+				}
+				return respond(tt.status, nil, tt.body), nil
+			})})
+
+			got, err := c.GetREADME(context.Background(), "octo", "app", tt.maxBytes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetREADME() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetREADME() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,25 +1,167 @@
 package heuristic
 
 import (
+	"log"
 	"sort"
+	"time"
 
 	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
 )
 
-// ApplyFeatured sorts projects by PushedAt descending, marks the top maxFeatured
-// as featured, the next ones as not featured, and discards the rest beyond maxTotal.
-func ApplyFeatured(projects []contentful.Project, maxFeatured, maxTotal int) []contentful.Project {
-	sort.Slice(projects, func(i, j int) bool {
+// FeaturedOptions controls ApplyFeatured.
+type FeaturedOptions struct {
+	MaxFeatured int
+	MaxTotal    int
+
+	// Priority lists slugs placed first, in exactly this order.
+	Priority []string
+
+	// Forced holds slugs that are always featured, such as repos carrying
+	// the featured topic. They rank right after Priority. Unless
+	// ForcedOutsideBudget is set they count against MaxFeatured.
+	Forced              map[string]bool
+	ForcedOutsideBudget bool
+
+	// Pinned holds slugs featured in the CMS. They stay featured,
+	// rank after Priority and Forced slugs and take their slots after
+	// them, always within MaxFeatured; only the remaining slots are filled
+	// automatically. If there are more pinned slugs than fit, all of them
+	// stay featured.
+	Pinned map[string]bool
+
+	// StaleAfter, when positive, keeps projects last pushed longer ago than
+	// this from being featured, passing their slot to the next active one.
+	// Forced and pinned projects and projects without a PushedAt are exempt.
+	StaleAfter time.Duration
+}
+
+// ApplyFeatured sorts projects by PushedAt descending, marks the top
+// opts.MaxFeatured as featured, the next ones as not featured, and discards
+// the rest beyond opts.MaxTotal. Slugs listed in opts.Priority are moved to
+// the front in exactly that order before featuring and truncation; unknown
+// slugs are ignored with a warning. Forced and then pinned slugs follow them
+// and are always featured.
+func ApplyFeatured(projects []contentful.Project, opts FeaturedOptions) []contentful.Project {
+	priority := opts.Priority
+	rank := make(map[string]int, len(priority))
+	for i, slug := range priority {
+		if _, dup := rank[slug]; !dup {
+			rank[slug] = i
+		}
+	}
+	found := make(map[string]bool, len(rank))
+	for _, p := range projects {
+		if _, ok := rank[p.Slug]; ok {
+			found[p.Slug] = true
+		}
+	}
+	for _, slug := range priority {
+		if !found[slug] {
+			log.Printf("WARNING: priority slug %q not found, ignoring", slug)
+		}
+	}
+
+	sort.SliceStable(projects, func(i, j int) bool {
+		ri, iPrio := rank[projects[i].Slug]
+		rj, jPrio := rank[projects[j].Slug]
+		if iPrio || jPrio {
+			if iPrio && jPrio {
+				return ri < rj
+			}
+			return iPrio
+		}
+		if fi, fj := opts.Forced[projects[i].Slug], opts.Forced[projects[j].Slug]; fi != fj {
+			return fi
+		}
+		if pi, pj := opts.Pinned[projects[i].Slug], opts.Pinned[projects[j].Slug]; pi != pj {
+			return pi
+		}
 		return projects[i].PushedAt.After(projects[j].PushedAt)
 	})
 
-	if len(projects) > maxTotal {
-		projects = projects[:maxTotal]
+	if len(projects) > opts.MaxTotal {
+		projects = projects[:opts.MaxTotal]
 	}
 
+	// Topic-forced slots are reserved first, then priority slugs take
+	// theirs, then pinned slugs; automatic picks only fill what is left
+	featured := 0
+	for _, p := range projects {
+		if opts.forcedInBudget(p.Slug) {
+			featured++
+		}
+	}
 	for i := range projects {
-		projects[i].Featured = i < maxFeatured
+		if _, ok := rank[projects[i].Slug]; !ok || opts.held(projects[i].Slug) {
+			continue
+		}
+		projects[i].Featured = !opts.stale(projects[i]) && featured < opts.MaxFeatured
+		if projects[i].Featured {
+			featured++
+		}
+	}
+	for _, p := range projects {
+		if opts.Pinned[p.Slug] && !opts.forcedInBudget(p.Slug) {
+			featured++
+		}
+	}
+	if featured > opts.MaxFeatured {
+		log.Printf("WARNING: keeping every pinned and topic-featured project brings featured to %d, above MAX_FEATURED (%d)", featured, opts.MaxFeatured)
+	}
+	for i := range projects {
+		if opts.held(projects[i].Slug) {
+			projects[i].Featured = true
+			continue
+		}
+		if _, ok := rank[projects[i].Slug]; ok {
+			continue
+		}
+		if opts.stale(projects[i]) || featured >= opts.MaxFeatured {
+			projects[i].Featured = false
+			continue
+		}
+		projects[i].Featured = true
+		featured++
 	}
-
 	return projects
 }
+
+// held reports whether slug is featured regardless of ranking.
+func (opts FeaturedOptions) held(slug string) bool {
+	return opts.Forced[slug] || opts.Pinned[slug]
+}
+
+// forcedInBudget reports whether slug is forced and uses up a MaxFeatured
+// slot.
+func (opts FeaturedOptions) forcedInBudget(slug string) bool {
+	return opts.Forced[slug] && !opts.ForcedOutsideBudget
+}
+
+func (opts FeaturedOptions) stale(p contentful.Project) bool {
+	return opts.StaleAfter > 0 && !p.PushedAt.IsZero() && time.Since(p.PushedAt) > opts.StaleAfter
+}
+
+// FeaturedChanges counts projects whose Featured flag differs between the
+// previous CMS state and the newly computed selection, matched by slug.
+// Projects that are new or dropped count as a change only if they were featured.
+func FeaturedChanges(previous, next []contentful.Project) int {
+	prev := make(map[string]bool, len(previous))
+	for _, p := range previous {
+		prev[p.Slug] = p.Featured
+	}
+
+	changed := 0
+	seen := make(map[string]bool, len(next))
+	for _, p := range next {
+		seen[p.Slug] = true
+		if prev[p.Slug] != p.Featured {
+			changed++
+		}
+	}
+	for _, p := range previous {
+		if !seen[p.Slug] && p.Featured {
+			changed++
+		}
+	}
+	return changed
+}
@@ -0,0 +1,142 @@
+package heuristic
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+// byRecency returns projects named in order, each pushed a day before the
+// previous one.
+func byRecency(names ...string) []contentful.Project {
+	projects := slugs(names...)
+	for i := range projects {
+		projects[i].PushedAt = time.Now().Add(-time.Duration(i) * 24 * time.Hour)
+	}
+	return projects
+}
+
+func set(names ...string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, n := range names {
+		m[n] = true
+	}
+	return m
+}
+
+func TestApplyFeaturedWarnsOnUnknownPriority(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	ApplyFeatured(byRecency("a", "b"), FeaturedOptions{MaxFeatured: 1, MaxTotal: 2, Priority: []string{"b", "missing"}})
+	out := buf.String()
+	if !strings.Contains(out, `priority slug "missing" not found`) || strings.Contains(out, `"b"`) {
+		t.Errorf("log = %q, want a warning for the unknown slug only", out)
+	}
+}
+
+func TestApplyFeatured(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         FeaturedOptions
+		wantOrder    string
+		wantFeatured string
+	}{
+		{
+			name:         "recency only",
+			opts:         FeaturedOptions{MaxFeatured: 2, MaxTotal: 4},
+			wantOrder:    "a b c d",
+			wantFeatured: "a b",
+		},
+		{
+			name:         "priority in exact order",
+			opts:         FeaturedOptions{MaxFeatured: 2, MaxTotal: 4, Priority: []string{"d", "b"}},
+			wantOrder:    "d b a c",
+			wantFeatured: "d b",
+		},
+		{
+			name:         "unknown priority slugs are ignored",
+			opts:         FeaturedOptions{MaxFeatured: 1, MaxTotal: 4, Priority: []string{"x", "c", "y"}},
+			wantOrder:    "c a b d",
+			wantFeatured: "c",
+		},
+		{
+			name:         "repeated priority slug keeps its first position",
+			opts:         FeaturedOptions{MaxFeatured: 1, MaxTotal: 4, Priority: []string{"c", "d", "c"}},
+			wantOrder:    "c d a b",
+			wantFeatured: "c",
+		},
+		{
+			name:         "pinned fills its slot before automatic picks",
+			opts:         FeaturedOptions{MaxFeatured: 2, MaxTotal: 4, Pinned: set("d")},
+			wantOrder:    "d a b c",
+			wantFeatured: "d a",
+		},
+		{
+			name:         "priority takes its slot before pinned",
+			opts:         FeaturedOptions{MaxFeatured: 1, MaxTotal: 4, Priority: []string{"c"}, Pinned: set("d")},
+			wantOrder:    "c d a b",
+			wantFeatured: "c d",
+		},
+		{
+			name:         "topic ranks before pinned",
+			opts:         FeaturedOptions{MaxFeatured: 2, MaxTotal: 4, Forced: set("c"), Pinned: set("d")},
+			wantOrder:    "c d a b",
+			wantFeatured: "c d",
+		},
+		{
+			name:         "pinned beyond the budget all stay featured",
+			opts:         FeaturedOptions{MaxFeatured: 1, MaxTotal: 4, Pinned: set("b", "c", "d")},
+			wantOrder:    "b c d a",
+			wantFeatured: "b c d",
+		},
+		{
+			name:         "topic outside the budget leaves the slots",
+			opts:         FeaturedOptions{MaxFeatured: 1, MaxTotal: 4, Forced: set("d"), ForcedOutsideBudget: true},
+			wantOrder:    "d a b c",
+			wantFeatured: "d a",
+		},
+		{
+			name:         "stale projects pass their slot on",
+			opts:         FeaturedOptions{MaxFeatured: 3, MaxTotal: 4, StaleAfter: 36 * time.Hour},
+			wantOrder:    "a b c d",
+			wantFeatured: "a b",
+		},
+		{
+			name:         "stale priority slug is not featured",
+			opts:         FeaturedOptions{MaxFeatured: 1, MaxTotal: 4, Priority: []string{"d"}, StaleAfter: 36 * time.Hour},
+			wantOrder:    "d a b c",
+			wantFeatured: "a",
+		},
+		{
+			name:         "truncated to MaxTotal",
+			opts:         FeaturedOptions{MaxFeatured: 1, MaxTotal: 2, Pinned: set("d")},
+			wantOrder:    "d a",
+			wantFeatured: "d",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ApplyFeatured(byRecency("a", "b", "c", "d"), tt.opts)
+			var order, featured []string
+			for _, p := range got {
+				order = append(order, p.Slug)
+				if p.Featured {
+					featured = append(featured, p.Slug)
+				}
+			}
+			if s := strings.Join(order, " "); s != tt.wantOrder {
+				t.Errorf("order = %q, want %q", s, tt.wantOrder)
+			}
+			if s := strings.Join(featured, " "); s != tt.wantFeatured {
+				t.Errorf("featured = %q, want %q", s, tt.wantFeatured)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package heuristic
+
+import (
+	"sort"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+// PreserveOrder returns a copy of projects arranged by the manual order the
+// same slugs have in existing. Existing projects without an order keep their
+// CMS array position after the ordered ones; projects new to the CMS are
+// appended in their incoming (recency) order. Order is renumbered from 1.
+func PreserveOrder(projects, existing []contentful.Project) []contentful.Project {
+	type position struct {
+		order int
+		index int
+	}
+	known := make(map[string]position, len(existing))
+	for i, p := range existing {
+		known[p.Slug] = position{order: p.Order, index: i}
+	}
+
+	out := make([]contentful.Project, len(projects))
+	copy(out, projects)
+
+	// Sort key: ordered CMS projects by order, then unordered CMS projects
+	// by array position, then new projects in incoming order.
+	rank := func(p contentful.Project) (group, key int) {
+		pos, ok := known[p.Slug]
+		switch {
+		case ok && pos.order > 0:
+			return 0, pos.order
+		case ok:
+			return 1, pos.index
+		default:
+			return 2, 0
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		gi, ki := rank(out[i])
+		gj, kj := rank(out[j])
+		if gi != gj {
+			return gi < gj
+		}
+		return ki < kj
+	})
+
+	for i := range out {
+		out[i].Order = i + 1
+	}
+	return out
+}
+
+// Churn measures how much the project ordering moved between two runs.
+type Churn struct {
+	// Moved is the number of projects present in both lists whose
+	// position changed.
+	Moved int
+
+	// Distance is the total number of positions those projects moved.
+	Distance int
+}
+
+// OrderChurn compares each project's position in next with its position in
+// previous, matched by slug. Position is the array index, which is what the
+// frontend renders; projects added or removed are not counted.
+func OrderChurn(previous, next []contentful.Project) Churn {
+	before := make(map[string]int, len(previous))
+	for i, p := range previous {
+		before[p.Slug] = i
+	}
+
+	var c Churn
+	for i, p := range next {
+		j, ok := before[p.Slug]
+		if !ok || i == j {
+			continue
+		}
+		c.Moved++
+		if i > j {
+			c.Distance += i - j
+		} else {
+			c.Distance += j - i
+		}
+	}
+	return c
+}
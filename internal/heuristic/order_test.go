@@ -0,0 +1,37 @@
+package heuristic
+
+import (
+	"testing"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+func slugs(names ...string) []contentful.Project {
+	projects := make([]contentful.Project, len(names))
+	for i, n := range names {
+		projects[i] = contentful.Project{Slug: n}
+	}
+	return projects
+}
+
+func TestOrderChurn(t *testing.T) {
+	tests := []struct {
+		name           string
+		previous, next []contentful.Project
+		want           Churn
+	}{
+		{"unchanged", slugs("a", "b", "c"), slugs("a", "b", "c"), Churn{}},
+		{"swap", slugs("a", "b", "c"), slugs("b", "a", "c"), Churn{Moved: 2, Distance: 2}},
+		{"reversed", slugs("a", "b", "c", "d"), slugs("d", "c", "b", "a"), Churn{Moved: 4, Distance: 8}},
+		{"insert at front shifts the rest", slugs("a", "b"), slugs("new", "a", "b"), Churn{Moved: 2, Distance: 2}},
+		{"removed projects are not counted", slugs("a", "gone", "b"), slugs("a", "b"), Churn{Moved: 1, Distance: 1}},
+		{"empty previous", nil, slugs("a"), Churn{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OrderChurn(tt.previous, tt.next); got != tt.want {
+				t.Errorf("OrderChurn() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
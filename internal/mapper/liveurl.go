@@ -0,0 +1,118 @@
+package mapper
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// urlRe matches http(s) URLs in README text, stopping at whitespace and the
+// characters that close markdown and HTML links.
+var urlRe = regexp.MustCompile(`https?://[^\s<>()\[\]"'` + "`" + `]+`)
+
+// liveLabelRe matches a line that labels its link as the deployed site, and
+// videoLabelRe one that labels it as a recording of it instead.
+var (
+	liveLabelRe  = regexp.MustCompile(`(?i)\b(live|demo|website|deployed|visit)\b`)
+	videoLabelRe = regexp.MustCompile(`(?i)\b(video|screencast|recording|walkthrough)\b`)
+)
+
+// deployHosts are hosting suffixes whose URLs are taken as the deployed
+// site wherever they appear.
+var deployHosts = []string{
+	".vercel.app", ".netlify.app", ".github.io", ".pages.dev", ".herokuapp.com",
+	".fly.dev", ".onrender.com", ".surge.sh", ".web.app", ".firebaseapp.com",
+}
+
+// extractLiveURL returns the first deployed-site URL in readme: a link on a
+// line labeled Live, Demo or similar just before it, or any link to a known hosting
+// domain. Image and badge URLs and links back to GitHub are skipped. It
+// returns "" when none is found.
+func extractLiveURL(readme string) string {
+	for _, line := range strings.Split(readme, "\n") {
+		labelStart := 0
+		for _, loc := range urlRe.FindAllStringIndex(line, -1) {
+			// A label only covers the text since the previous URL
+			label := line[labelStart:loc[0]]
+			labelStart = loc[1]
+			if loc[0] >= 2 && line[loc[0]-2:loc[0]] == "](" && isImageLink(line[:loc[0]-2]) {
+				continue
+			}
+			u, ok := cleanLiveURL(line[loc[0]:loc[1]])
+			if !ok {
+				continue
+			}
+			if isLiveLabel(label) || hasDeployHost(u) {
+				return u.String()
+			}
+		}
+	}
+	return ""
+}
+
+// isLiveLabel reports whether the text before a URL labels it as the live
+// site, e.g. "Live demo:" or "[Demo](". Only a few words count, so prose
+// that merely mentions a demo does not, and "Demo video" is a recording.
+func isLiveLabel(prefix string) bool {
+	return liveLabelRe.MatchString(prefix) && !videoLabelRe.MatchString(prefix) &&
+		len(strings.Fields(prefix)) <= maxLabelWords
+}
+
+// maxLabelWords bounds how many words may precede a labeled URL.
+const maxLabelWords = 6
+
+// isImageLink reports whether the markdown link whose "](" follows prefix
+// is an image, i.e. its "[" is preceded by "!".
+func isImageLink(prefix string) bool {
+	depth := 0
+	for i := len(prefix) - 1; i >= 0; i-- {
+		switch prefix[i] {
+		case ']':
+			depth++
+		case '[':
+			if depth == 0 {
+				return i > 0 && prefix[i-1] == '!'
+			}
+			depth--
+		}
+	}
+	return false
+}
+
+// videoHosts are hosts whose links are recordings, never the deployed site.
+var videoHosts = []string{"youtube.com", "youtu.be", "vimeo.com", "loom.com"}
+
+// cleanLiveURL trims trailing punctuation and rejects URLs that cannot be a
+// deployed site: GitHub itself, badge services, video hosts and image files.
+func cleanLiveURL(raw string) (*url.URL, bool) {
+	u, err := url.Parse(strings.TrimRight(raw, ".,;:!?*_"))
+	if err != nil || u.Host == "" {
+		return nil, false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "github.com" || strings.HasSuffix(host, ".github.com") || strings.HasSuffix(host, "githubusercontent.com") ||
+		host == "shields.io" || strings.HasSuffix(host, ".shields.io") || host == "badge.fury.io" {
+		return nil, false
+	}
+	for _, video := range videoHosts {
+		if host == video || strings.HasSuffix(host, "."+video) {
+			return nil, false
+		}
+	}
+	switch strings.ToLower(path.Ext(u.Path)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp":
+		return nil, false
+	}
+	return u, true
+}
+
+func hasDeployHost(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	for _, suffix := range deployHosts {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
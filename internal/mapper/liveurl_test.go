@@ -0,0 +1,30 @@
+package mapper
+
+import "testing"
+
+func TestExtractLiveURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		readme string
+		want   string
+	}{
+		{name: "none", readme: "# tool\n\nA CLI.\n", want: ""},
+		{name: "hosting domain", readme: "See https://tool.vercel.app for more.", want: "https://tool.vercel.app"},
+		{name: "labeled line", readme: "**Live demo:** https://tool.example.com.", want: "https://tool.example.com"},
+		{name: "labeled markdown link", readme: "[Demo](https://tool.example.com)", want: "https://tool.example.com"},
+		{name: "demo video link", readme: "[Demo video](https://www.youtube.com/watch?v=abc)", want: ""},
+		{name: "video label on another host", readme: "Demo video: https://cdn.example.com/demo", want: ""},
+		{name: "video host under a live label", readme: "Live: https://youtu.be/abc", want: ""},
+		{name: "video then site", readme: "[Demo video](https://vimeo.com/1) | [Live](https://tool.example.com)", want: "https://tool.example.com"},
+		{name: "badge skipped", readme: "[![Deploy](https://tool.netlify.app/badge.svg)](https://app.netlify.com)\nLive: https://tool.example.com", want: "https://tool.example.com"},
+		{name: "github link skipped", readme: "Demo: https://github.com/octo/tool", want: ""},
+		{name: "prose mentions demo", readme: "This project was built as a demo for a talk I gave at a meetup, slides at https://slides.example.com", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractLiveURL(tt.readme); got != tt.want {
+				t.Errorf("extractLiveURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,12 @@
 package mapper
 
 import (
+	"regexp"
 	"sort"
 	"strings"
 	"time"
 
-	"github.com/alberto-moreno-sa/go-service-kit/github"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/github"
 )
 
 // RawProject holds the raw data from GitHub before AI enrichment.
@@ -18,13 +19,49 @@ type RawProject struct {
 	ReadmeRaw string
 	RepoSize  int
 	PushedAt  time.Time
+
+	// SourceCommit is the default-branch head SHA the README was read at.
+	SourceCommit string
+
+	// Draft marks a recently created repo with no tags or releases yet.
+	Draft bool
+
+	// Overview is the text of a configured README section, used to seed
+	// the long description.
+	Overview string
+
+	// Description is the GitHub repo description with any inline tags removed.
+	Description string
+
+	// Topics are the repo's GitHub topics.
+	Topics []string
+
+	// CategoryOverride and TechHints come from inline description tags.
+	CategoryOverride string
+	TechHints        []string
+
+	// Display comes from the repo's config file; nil shows every section.
+	Display *Display
+
+	// Parent is the slug of the monorepo a sub-project was expanded from;
+	// empty for a repo's own project.
+	Parent string
 }
 
-// ToRawProject converts a GitHub repo with its languages and README into a RawProject.
+// ToRawProject converts a GitHub repo with its languages and README into a
+// RawProject. LiveURL is the repo homepage, or the deployed-site link found
+// in the README when the homepage is empty.
 func ToRawProject(repo github.Repo, languages map[string]int, readme string) RawProject {
 	var liveURL string
 	if repo.Homepage != nil {
-		liveURL = *repo.Homepage
+		liveURL = strings.TrimSpace(*repo.Homepage)
+	}
+	if liveURL == "" {
+		liveURL = extractLiveURL(readme)
+	}
+	var description string
+	if repo.Description != nil {
+		description = *repo.Description
 	}
 
 	return RawProject{
@@ -33,28 +70,132 @@ func ToRawProject(repo github.Repo, languages map[string]int, readme string) Raw
 		GitHubURL: repo.HTMLURL,
 		LiveURL:   liveURL,
 		Languages: sortedLanguages(languages),
-		ReadmeRaw: readme,
+		ReadmeRaw: AbsolutizeLinks(readme, repo.HTMLURL),
 		RepoSize:  repo.Size,
 		PushedAt:  repo.PushedAt,
+
+		Description: description,
+		Topics:      repo.Topics,
 	}
 }
 
-// FilterRepos removes forks, archived repos, and the profile README repo.
-func FilterRepos(repos []github.Repo, username string) []github.Repo {
+// ApplyDescriptionTags extracts inline tags such as "[cat:Web]" or
+// "[tech:Go]" from the project's description using tagRe, whose first two
+// groups are the key and value. "cat"/"category" sets CategoryOverride and
+// "tech" adds to TechHints; other keys are ignored. Matched tags are removed
+// from the description either way.
+func ApplyDescriptionTags(raw *RawProject, tagRe *regexp.Regexp) {
+	if tagRe == nil || raw.Description == "" {
+		return
+	}
+
+	for _, m := range tagRe.FindAllStringSubmatch(raw.Description, -1) {
+		if len(m) < 3 {
+			continue
+		}
+		value := strings.TrimSpace(m[2])
+		switch strings.ToLower(strings.TrimSpace(m[1])) {
+		case "cat", "category":
+			raw.CategoryOverride = value
+		case "tech":
+			raw.TechHints = append(raw.TechHints, value)
+		}
+	}
+
+	raw.Description = strings.Join(strings.Fields(tagRe.ReplaceAllString(raw.Description, "")), " ")
+}
+
+// FilterOptions tunes which repos FilterRepos keeps.
+type FilterOptions struct {
+	// IncludeTemplates keeps GitHub template repos, which are dropped by default.
+	IncludeTemplates bool
+
+	// KeepArchived keeps archived repos so they can be routed elsewhere.
+	KeepArchived bool
+
+	// ExcludePatterns drops repos whose name matches any of them.
+	ExcludePatterns []*regexp.Regexp
+
+	// RequireTopic, when set, drops repos without this GitHub topic
+	// (case-insensitive).
+	RequireTopic string
+
+	// Include, when non-empty, drops every repo it does not name. Exclude
+	// drops the repos it names, even ones also listed in Include.
+	Include []string
+	Exclude []string
+
+	// MinSizeKB drops repos smaller than this many KB (GitHub reports Size
+	// in KB), except the repos named in SizeAllow.
+	MinSizeKB int
+	SizeAllow []string
+
+	// MinStars drops repos with fewer stargazers, except the repos named in
+	// StarsAllow.
+	MinStars   int
+	StarsAllow []string
+}
+
+// FilterRepos removes forks, archived repos (unless opts.KeepArchived),
+// template repos (unless opts.IncludeTemplates), the profile README repo,
+// repos named in opts.Exclude, missing from a non-empty opts.Include or
+// lacking opts.RequireTopic, and
+// repos whose name matches one of opts.ExcludePatterns, that are smaller than
+// opts.MinSizeKB or that have fewer than opts.MinStars stars.
+func FilterRepos(repos []github.Repo, username string, opts FilterOptions) []github.Repo {
 	profileRepo := strings.ToLower(username)
 	var filtered []github.Repo
 	for _, r := range repos {
-		if r.Fork || r.Archived {
+		if r.Fork || (r.Archived && !opts.KeepArchived) {
+			continue
+		}
+		if r.IsTemplate && !opts.IncludeTemplates {
 			continue
 		}
 		if strings.ToLower(r.Name) == profileRepo {
 			continue
 		}
+		if containsFold(opts.Exclude, r.Name) {
+			continue
+		}
+		if len(opts.Include) > 0 && !containsFold(opts.Include, r.Name) {
+			continue
+		}
+		if opts.RequireTopic != "" && !containsFold(r.Topics, opts.RequireTopic) {
+			continue
+		}
+		if matchesAny(r.Name, opts.ExcludePatterns) {
+			continue
+		}
+		if r.Size < opts.MinSizeKB && !containsFold(opts.SizeAllow, r.Name) {
+			continue
+		}
+		if r.StargazersCount < opts.MinStars && !containsFold(opts.StarsAllow, r.Name) {
+			continue
+		}
 		filtered = append(filtered, r)
 	}
 	return filtered
 }
 
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(name string, patterns []*regexp.Regexp) bool {
+	for _, re := range patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
 // sortedLanguages returns language names sorted by byte count descending.
 func sortedLanguages(languages map[string]int) []string {
 	type langCount struct {
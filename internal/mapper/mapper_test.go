@@ -0,0 +1,135 @@
+package mapper
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/github"
+	githubapi "github.com/alberto-moreno-sa/go-service-kit/github"
+)
+
+func repo(name string) github.Repo {
+	return github.Repo{Repo: githubapi.Repo{Name: name, HTMLURL: "https://github.com/octo/" + name}}
+}
+
+func names(repos []github.Repo) []string {
+	var out []string
+	for _, r := range repos {
+		out = append(out, r.Name)
+	}
+	return out
+}
+
+func TestFilterReposTemplates(t *testing.T) {
+	template := repo("starter")
+	template.IsTemplate = true
+	repos := []github.Repo{repo("app"), template}
+
+	tests := []struct {
+		name string
+		opts FilterOptions
+		want []string
+	}{
+		{"dropped by default", FilterOptions{}, []string{"app"}},
+		{"kept with IncludeTemplates", FilterOptions{IncludeTemplates: true}, []string{"app", "starter"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := names(FilterRepos(repos, "octo", tt.opts))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterRepos() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterReposLists(t *testing.T) {
+	repos := []github.Repo{repo("app"), repo("api"), repo("dotfiles"), repo("Blog")}
+
+	tests := []struct {
+		name string
+		opts FilterOptions
+		want []string
+	}{
+		{"no lists", FilterOptions{}, []string{"app", "api", "dotfiles", "Blog"}},
+		{"exclude", FilterOptions{Exclude: []string{"dotfiles"}}, []string{"app", "api", "Blog"}},
+		{"include is an allowlist", FilterOptions{Include: []string{"app", "blog"}}, []string{"app", "Blog"}},
+		{"exclude wins over include", FilterOptions{Include: []string{"app", "api"}, Exclude: []string{"API"}}, []string{"app"}},
+		{"unknown include names", FilterOptions{Include: []string{"missing"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := names(FilterRepos(repos, "octo", tt.opts))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterRepos() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterReposExcludePatterns(t *testing.T) {
+	repos := []github.Repo{repo("app"), repo("exp-parser"), repo("my-exp"), repo("dotfiles"), repo("api-v2")}
+	patterns := func(exprs ...string) []*regexp.Regexp {
+		var out []*regexp.Regexp
+		for _, e := range exprs {
+			out = append(out, regexp.MustCompile(e))
+		}
+		return out
+	}
+
+	tests := []struct {
+		name string
+		opts FilterOptions
+		want []string
+	}{
+		{"no patterns", FilterOptions{}, []string{"app", "exp-parser", "my-exp", "dotfiles", "api-v2"}},
+		{"anchored prefix", FilterOptions{ExcludePatterns: patterns("^exp-")}, []string{"app", "my-exp", "dotfiles", "api-v2"}},
+		{"unanchored matches anywhere", FilterOptions{ExcludePatterns: patterns("exp")}, []string{"app", "dotfiles", "api-v2"}},
+		{"any of several", FilterOptions{ExcludePatterns: patterns("^dot", `-v\d+$`)}, []string{"app", "exp-parser", "my-exp"}},
+		{"case-insensitive flag", FilterOptions{ExcludePatterns: patterns("(?i)^APP$")}, []string{"exp-parser", "my-exp", "dotfiles", "api-v2"}},
+		{"include cannot override", FilterOptions{Include: []string{"exp-parser", "app"}, ExcludePatterns: patterns("^exp-")}, []string{"app"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := names(FilterRepos(repos, "octo", tt.opts))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterRepos() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterReposThresholds(t *testing.T) {
+	small := repo("tiny")
+	small.Size = 10
+	small.StargazersCount = 50
+	unstarred := repo("experiment")
+	unstarred.Size = 500
+	popular := repo("app")
+	popular.Size = 500
+	popular.StargazersCount = 20
+	repos := []github.Repo{small, unstarred, popular}
+
+	tests := []struct {
+		name string
+		opts FilterOptions
+		want []string
+	}{
+		{"no thresholds", FilterOptions{}, []string{"tiny", "experiment", "app"}},
+		{"min size", FilterOptions{MinSizeKB: 100}, []string{"experiment", "app"}},
+		{"min stars", FilterOptions{MinStars: 5}, []string{"tiny", "app"}},
+		{"both", FilterOptions{MinSizeKB: 100, MinStars: 5}, []string{"app"}},
+		{"allow lists exempt", FilterOptions{MinSizeKB: 100, MinStars: 5, SizeAllow: []string{"Tiny"}, StarsAllow: []string{"experiment"}}, []string{"tiny", "experiment", "app"}},
+		{"size allow does not exempt from stars", FilterOptions{MinStars: 5, SizeAllow: []string{"experiment"}}, []string{"tiny", "app"}},
+		{"stars allow does not exempt from size", FilterOptions{MinSizeKB: 100, StarsAllow: []string{"tiny"}}, []string{"experiment", "app"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := names(FilterRepos(repos, "octo", tt.opts))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterRepos() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package mapper
+
+import (
+	"bytes"
+	"encoding/base64"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// markdownLinkRe matches inline markdown links and images: [text](target) and ![alt](target).
+// An optional title after the target ("...") is kept in group 4.
+var markdownLinkRe = regexp.MustCompile(`(!?)\[([^\]]*)\]\(\s*([^)\s]+)(\s+"[^"]*")?\s*\)`)
+
+// AbsolutizeLinks rewrites relative markdown links and images in a README to
+// absolute GitHub URLs. Links point at the blob view and images at raw content,
+// both on the default branch. Relative paths (./, ../, bare) resolve from the
+// repo root, where GitHub serves the README from; root-relative paths (/x) are
+// taken from the repo root as well.
+func AbsolutizeLinks(readme, htmlURL string) string {
+	return AbsolutizeLinksIn(readme, htmlURL, "")
+}
+
+// AbsolutizeLinksIn is AbsolutizeLinks for a README in the repo directory
+// dir, which relative paths resolve from.
+func AbsolutizeLinksIn(readme, htmlURL, dir string) string {
+	if readme == "" || !strings.HasPrefix(htmlURL, "https://github.com/") {
+		return readme
+	}
+	repoPath := strings.TrimSuffix(strings.TrimPrefix(htmlURL, "https://github.com/"), "/")
+	blobBase := "https://github.com/" + repoPath + "/blob/HEAD"
+	rawBase := "https://raw.githubusercontent.com/" + repoPath + "/HEAD"
+
+	return markdownLinkRe.ReplaceAllStringFunc(readme, func(m string) string {
+		parts := markdownLinkRe.FindStringSubmatch(m)
+		bang, text, target, title := parts[1], parts[2], parts[3], parts[4]
+		if !isRelative(target) {
+			return m
+		}
+
+		base := blobBase
+		if bang == "!" {
+			base = rawBase
+		}
+		return bang + "[" + text + "](" + base + resolvePath(dir, target) + title + ")"
+	})
+}
+
+func isRelative(target string) bool {
+	if strings.HasPrefix(target, "#") || strings.HasPrefix(target, "//") {
+		return false
+	}
+	if i := strings.Index(target, ":"); i > 0 && !strings.ContainsAny(target[:i], "/?#") {
+		return false
+	}
+	return true
+}
+
+// resolvePath cleans a relative target against dir, or the repo root when
+// the target starts with a slash, keeping any query string or fragment.
+// Paths that climb above the root are clamped to it.
+func resolvePath(dir, target string) string {
+	suffix := ""
+	if i := strings.IndexAny(target, "?#"); i >= 0 {
+		target, suffix = target[:i], target[i:]
+	}
+	if !strings.HasPrefix(target, "/") {
+		target = dir + "/" + target
+	}
+	return path.Clean("/"+target) + suffix
+}
+
+// base64Re matches text made only of base64 characters, optionally wrapped
+// across lines the way the GitHub contents API returns it.
+var base64Re = regexp.MustCompile(`^[A-Za-z0-9+/\r\n]+={0,2}\s*$`)
+
+// DecodeREADME returns readme decoded from base64 when it looks like an
+// encoded payload (the GitHub contents API "content" field), and unchanged
+// otherwise. A payload only counts as base64 if it decodes to text (see
+// decodedText), so plain READMEs that happen to be a single word are never
+// touched. The decoded bytes are returned as is; ToUTF8 transcodes them.
+func DecodeREADME(readme string) string {
+	trimmed := strings.TrimSpace(readme)
+	if len(trimmed) < 8 || !base64Re.MatchString(trimmed) {
+		return readme
+	}
+
+	compact := strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, trimmed)
+	if len(compact)%4 != 0 {
+		return readme
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(compact)
+	if err != nil || !decodedText(decoded) {
+		return readme
+	}
+	return string(decoded)
+}
+
+// decodedText reports whether b, decoded from base64, reads as a README:
+// UTF-8 text, UTF-16 with a BOM, or Latin-1 text spanning several lines
+// without control characters.
+func decodedText(b []byte) bool {
+	if bytes.HasPrefix(b, []byte{0xFF, 0xFE}) || bytes.HasPrefix(b, []byte{0xFE, 0xFF}) {
+		_, ok := ToUTF8(string(b))
+		return ok
+	}
+	if utf8.Valid(b) {
+		return isMostlyText(b)
+	}
+	if bytes.IndexByte(b, '\n') < 0 {
+		return false
+	}
+	for _, c := range b {
+		if c < 0x20 && c != '\n' && c != '\r' && c != '\t' || c >= 0x7F && c < 0xA0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ToUTF8 converts readme to UTF-8 and strips any byte-order mark. UTF-16 is
+// recognized by its BOM. A multi-byte character cut off by a download cap is
+// dropped; other input that is not valid UTF-8 is taken to be Latin-1. ok is
+// false when the content looks binary (NUL bytes or control
+// characters), in which case "" is returned.
+func ToUTF8(readme string) (text string, ok bool) {
+	b := []byte(readme)
+	switch {
+	case bytes.HasPrefix(b, []byte{0xEF, 0xBB, 0xBF}):
+		b = b[3:]
+	case bytes.HasPrefix(b, []byte{0xFF, 0xFE}):
+		b = []byte(decodeUTF16(b[2:], false))
+	case bytes.HasPrefix(b, []byte{0xFE, 0xFF}):
+		b = []byte(decodeUTF16(b[2:], true))
+	case !utf8.Valid(b):
+		if t := trimPartialRune(b); utf8.Valid(t) {
+			b = t
+			break
+		}
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+		b = []byte(string(runes))
+	}
+
+	if bytes.IndexByte(b, 0) >= 0 || !isMostlyText(b) {
+		return "", false
+	}
+	return string(b), true
+}
+
+// trimPartialRune drops an incomplete UTF-8 sequence at the end of b.
+func trimPartialRune(b []byte) []byte {
+	for k := 1; k <= utf8.UTFMax-1 && k <= len(b); k++ {
+		if utf8.RuneStart(b[len(b)-k]) {
+			if !utf8.FullRune(b[len(b)-k:]) {
+				return b[:len(b)-k]
+			}
+			break
+		}
+	}
+	return b
+}
+
+// decodeUTF16 decodes b as UTF-16 in the given byte order; a trailing odd
+// byte is dropped.
+func decodeUTF16(b []byte, bigEndian bool) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+		} else {
+			units[i] = uint16(b[2*i+1])<<8 | uint16(b[2*i])
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// isMostlyText reports whether b is free of control characters other than
+// common whitespace.
+func isMostlyText(b []byte) bool {
+	for _, r := range string(b) {
+		if r < 0x20 && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+var headingRe = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+
+// ExtractSection returns the body of the first markdown section whose
+// heading matches name (case-insensitive), up to the next heading of the same
+// or a higher level. Whitespace is collapsed. It returns "" when the section
+// is missing or empty.
+func ExtractSection(readme, name string) string {
+	if readme == "" || name == "" {
+		return ""
+	}
+
+	var body []string
+	level := 0
+	inFence := false
+	for _, line := range strings.Split(readme, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if m := headingRe.FindStringSubmatch(line); m != nil && !inFence {
+			if level > 0 && len(m[1]) <= level {
+				break
+			}
+			if level == 0 && strings.EqualFold(m[2], name) {
+				level = len(m[1])
+				continue
+			}
+		}
+		if level > 0 {
+			body = append(body, line)
+		}
+	}
+
+	return strings.Join(strings.Fields(strings.Join(body, "\n")), " ")
+}
+
+var (
+	badgeRe       = regexp.MustCompile(`\[!\[[^\]]*\]\([^)]*\)\]\([^)]*\)|!\[[^\]]*\]\([^)]*\)`)
+	htmlCommentRe = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// IsStubREADME reports whether readme carries no content beyond headings,
+// badges and HTML comments, as in a freshly generated "# project-name" file.
+// An empty README is not a stub: it may be missing or have failed to fetch,
+// and says nothing about what the repo would have documented.
+func IsStubREADME(readme string) bool {
+	if strings.TrimSpace(readme) == "" {
+		return false
+	}
+	readme = htmlCommentRe.ReplaceAllString(readme, "")
+	readme = badgeRe.ReplaceAllString(readme, "")
+	for _, line := range strings.Split(readme, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || headingRe.MatchString(line) {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package mapper
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestIsStubREADME(t *testing.T) {
+	tests := []struct {
+		name   string
+		readme string
+		want   bool
+	}{
+		{name: "empty", readme: "", want: false},
+		{name: "whitespace", readme: " \n\n", want: false},
+		{name: "title only", readme: "# my-tool\n", want: true},
+		{name: "title and badges", readme: "# my-tool\n\n[![CI](https://x/badge.svg)](https://x) ![Go](https://y/go.svg)\n", want: true},
+		{name: "title and comment", readme: "# my-tool\n<!-- TODO: write docs -->\n", want: true},
+		{name: "title and text", readme: "# my-tool\n\nA CLI that syncs things.\n", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsStubREADME(tt.readme); got != tt.want {
+				t.Errorf("IsStubREADME(%q) = %v, want %v", tt.readme, got, tt.want)
+			}
+		})
+	}
+}
+
+// wrapBase64 encodes s the way the GitHub contents API does: standard
+// base64 broken into 60-character lines.
+func wrapBase64(s string) string {
+	enc := base64.StdEncoding.EncodeToString([]byte(s))
+	var b strings.Builder
+	for len(enc) > 60 {
+		b.WriteString(enc[:60] + "\n")
+		enc = enc[60:]
+	}
+	b.WriteString(enc + "\n")
+	return b.String()
+}
+
+func TestDecodeREADME(t *testing.T) {
+	const fixture = "# my-tool\n\nA CLI that syncs GitHub repos to Contentful. Supports `--dry-run` and ñ/ü.\n\n## Install\n\n    go install ./...\n"
+	tests := []struct {
+		name   string
+		readme string
+		want   string
+	}{
+		{name: "wrapped payload", readme: wrapBase64(fixture), want: fixture},
+		{name: "single-line payload", readme: base64.StdEncoding.EncodeToString([]byte(fixture)), want: fixture},
+		{name: "CRLF-wrapped payload", readme: strings.ReplaceAll(wrapBase64(fixture), "\n", "\r\n"), want: fixture},
+		{name: "plain markdown", readme: fixture, want: fixture},
+		{name: "plain single word", readme: "Documentation", want: "Documentation"},
+		{name: "too short", readme: "aGk=", want: "aGk="},
+		{name: "length not a multiple of four", readme: "QUJDREVGR0hJSw", want: "QUJDREVGR0hJSw"},
+		{name: "UTF-16 payload", readme: base64.StdEncoding.EncodeToString([]byte("\xff\xfe#\x00 \x00a\x00\n\x00")), want: "\xff\xfe#\x00 \x00a\x00\n\x00"},
+		{name: "Latin-1 payload", readme: base64.StdEncoding.EncodeToString([]byte("# Caf\xe9\n\nUn outil.\n")), want: "# Caf\xe9\n\nUn outil.\n"},
+		{name: "single-line Latin-1 payload", readme: base64.StdEncoding.EncodeToString([]byte("Caf\xe9 ok")), want: base64.StdEncoding.EncodeToString([]byte("Caf\xe9 ok"))},
+		{name: "binary payload", readme: base64.StdEncoding.EncodeToString([]byte{0x89, 'P', 'N', 'G', 0, 1, 2, 3, 4}), want: base64.StdEncoding.EncodeToString([]byte{0x89, 'P', 'N', 'G', 0, 1, 2, 3, 4})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DecodeREADME(tt.readme); got != tt.want {
+				t.Errorf("DecodeREADME(%q) = %q, want %q", tt.readme, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToUTF8(t *testing.T) {
+	const fixture = "# Café\n\nUn outil de synchronisation à l'ancienne.\n"
+	latin1 := "# Caf\xe9\n\nUn outil de synchronisation \xe0 l'ancienne.\n"
+	utf16le := "\xff\xfe#\x00 \x00C\x00a\x00f\x00\xe9\x00"
+	utf16be := "\xfe\xff\x00#\x00 \x00C\x00a\x00f\x00\xe9"
+
+	tests := []struct {
+		name   string
+		readme string
+		want   string
+		wantOK bool
+	}{
+		{name: "UTF-8", readme: fixture, want: fixture, wantOK: true},
+		{name: "UTF-8 BOM", readme: "\xef\xbb\xbf" + fixture, want: fixture, wantOK: true},
+		{name: "Latin-1", readme: latin1, want: fixture, wantOK: true},
+		{name: "UTF-16LE BOM", readme: utf16le, want: "# Café", wantOK: true},
+		{name: "UTF-16BE BOM", readme: utf16be, want: "# Café", wantOK: true},
+		{name: "UTF-16 odd trailing byte", readme: utf16le + "\x00", want: "# Café", wantOK: true},
+		{name: "multi-byte character cut off", readme: "# Café\n\n→"[:len("# Café\n\n→")-1], want: "# Café\n\n", wantOK: true},
+		{name: "empty", readme: "", want: "", wantOK: true},
+		{name: "NUL bytes", readme: "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"},
+		{name: "control characters", readme: "\x01\x02\x03 binary"},
+		{name: "UTF-16 without BOM", readme: "#\x00 \x00a\x00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ToUTF8(tt.readme)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ToUTF8(%q) = %q, %v, want %q, %v", tt.readme, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package mapper
+
+import (
+	"bufio"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// RepoConfig is what a repo's config file such as .cms-sync.yml can set.
+type RepoConfig struct {
+	Display Display
+
+	// Subprojects, when set, expand the repo into one project per entry.
+	Subprojects []Subproject
+}
+
+// Display holds the per-project switches for optional sections of the
+// frontend card.
+type Display struct {
+	ShowDemo         bool
+	ShowTechnologies bool
+	ShowHighlights   bool
+}
+
+// Subproject is one package of a monorepo, listed under "subprojects".
+type Subproject struct {
+	Name        string
+	Description string
+	Path        string
+}
+
+// DefaultDisplay shows every section.
+func DefaultDisplay() Display {
+	return Display{ShowDemo: true, ShowTechnologies: true, ShowHighlights: true}
+}
+
+// ParseRepoConfig reads a repo config file. Only a small YAML subset is
+// understood: top-level "key: value" lines, where showDemo,
+// showTechnologies and showHighlights set the matching display switch,
+// and a "subprojects:" list of items with name, description and path
+// keys. Other keys are ignored. Missing switches default to true.
+func ParseRepoConfig(content string) (RepoConfig, error) {
+	cfg := RepoConfig{Display: DefaultDisplay()}
+	inSubprojects := false
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		raw := scanner.Text()
+		// As in YAML, # starts a comment only at the line start or after a space
+		if strings.HasPrefix(strings.TrimSpace(raw), "#") {
+			continue
+		}
+		if i := strings.Index(raw, " #"); i >= 0 {
+			raw = raw[:i]
+		}
+		text := strings.TrimSpace(raw)
+		if text == "" {
+			continue
+		}
+		indented := raw[0] == ' ' || raw[0] == '\t'
+
+		if inSubprojects && (indented || strings.HasPrefix(text, "- ")) {
+			if rest, ok := strings.CutPrefix(text, "- "); ok {
+				cfg.Subprojects = append(cfg.Subprojects, Subproject{})
+				text = strings.TrimSpace(rest)
+			}
+			if len(cfg.Subprojects) == 0 {
+				return cfg, fmt.Errorf("line %d: expected a \"- \" list item under subprojects", line)
+			}
+			key, value, ok := strings.Cut(text, ":")
+			if !ok {
+				return cfg, fmt.Errorf("line %d: expected key: value", line)
+			}
+			sub := &cfg.Subprojects[len(cfg.Subprojects)-1]
+			switch strings.TrimSpace(key) {
+			case "name":
+				sub.Name = unquote(value)
+			case "description":
+				sub.Description = unquote(value)
+			case "path":
+				sub.Path = strings.Trim(path.Clean("/"+unquote(value)), "/")
+			}
+			continue
+		}
+		inSubprojects = false
+		if indented {
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, ":")
+		if !ok {
+			continue
+		}
+		var field *bool
+		switch strings.TrimSpace(key) {
+		case "subprojects":
+			inSubprojects = true
+			continue
+		case "showDemo":
+			field = &cfg.Display.ShowDemo
+		case "showTechnologies":
+			field = &cfg.Display.ShowTechnologies
+		case "showHighlights":
+			field = &cfg.Display.ShowHighlights
+		default:
+			continue
+		}
+
+		switch strings.ToLower(unquote(value)) {
+		case "true", "yes", "on":
+			*field = true
+		case "false", "no", "off":
+			*field = false
+		default:
+			return cfg, fmt.Errorf("line %d: %s must be true or false, got %q", line, strings.TrimSpace(key), strings.TrimSpace(value))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return cfg, err
+	}
+
+	for i, sub := range cfg.Subprojects {
+		if sub.Path == "" {
+			return cfg, fmt.Errorf("subproject %d: path is required", i+1)
+		}
+	}
+	return cfg, nil
+}
+
+func unquote(value string) string {
+	return strings.Trim(strings.TrimSpace(value), `"'`)
+}
+
+// SubRawProject derives a monorepo sub-project from its repo's raw project.
+// The slug is the repo slug plus the path with slashes turned into dashes,
+// the GitHub URL points at the subdirectory, and readme is the
+// sub-project's own README with links resolved from its directory.
+// A live URL in that README replaces the repo's. Languages and other
+// repo-level data are shared with the parent.
+func SubRawProject(parent RawProject, sub Subproject, readme string) RawProject {
+	p := parent
+	p.Name = sub.Name
+	if p.Name == "" {
+		p.Name = path.Base(sub.Path)
+	}
+	p.Slug = parent.Slug + "-" + strings.ReplaceAll(sub.Path, "/", "-")
+	p.Parent = parent.Slug
+	p.GitHubURL = strings.TrimSuffix(parent.GitHubURL, "/") + "/tree/HEAD/" + sub.Path
+	p.ReadmeRaw = AbsolutizeLinksIn(readme, parent.GitHubURL, sub.Path)
+	if liveURL := extractLiveURL(readme); liveURL != "" {
+		p.LiveURL = liveURL
+	}
+	p.Description = sub.Description
+	p.Overview = ""
+	p.Languages = append([]string(nil), parent.Languages...)
+	p.TechHints = append([]string(nil), parent.TechHints...)
+	return p
+}
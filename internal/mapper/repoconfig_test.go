@@ -0,0 +1,118 @@
+package mapper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandSubprojects(t *testing.T) {
+	cfg, err := ParseRepoConfig(`showDemo: false
+subprojects:
+  - name: Web App
+    description: The dashboard
+    path: apps/web
+  - path: packages/sdk
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Display.ShowDemo {
+		t.Error("ShowDemo = true, want false")
+	}
+
+	parent := RawProject{
+		Name:      "mono",
+		Slug:      "mono",
+		GitHubURL: "https://github.com/octo/mono",
+		LiveURL:   "https://mono.dev",
+		Languages: []string{"Go", "TypeScript"},
+	}
+	tests := []struct {
+		readme string
+		want   RawProject
+	}{
+		{
+			readme: "# Web\n\nDemo: https://web.mono.dev\n",
+			want: RawProject{
+				Name:        "Web App",
+				Slug:        "mono-apps-web",
+				GitHubURL:   "https://github.com/octo/mono/tree/HEAD/apps/web",
+				LiveURL:     "https://web.mono.dev",
+				Description: "The dashboard",
+				Parent:      "mono",
+			},
+		},
+		{
+			want: RawProject{
+				Name:      "sdk",
+				Slug:      "mono-packages-sdk",
+				GitHubURL: "https://github.com/octo/mono/tree/HEAD/packages/sdk",
+				LiveURL:   "https://mono.dev",
+				Parent:    "mono",
+			},
+		},
+	}
+	if len(cfg.Subprojects) != len(tests) {
+		t.Fatalf("got %d subprojects, want %d", len(cfg.Subprojects), len(tests))
+	}
+	for i, tt := range tests {
+		got := SubRawProject(parent, cfg.Subprojects[i], tt.readme)
+		if got.Name != tt.want.Name || got.Slug != tt.want.Slug || got.GitHubURL != tt.want.GitHubURL ||
+			got.LiveURL != tt.want.LiveURL || got.Description != tt.want.Description || got.Parent != tt.want.Parent {
+			t.Errorf("subproject %d = %+v, want %+v", i, got, tt.want)
+		}
+		if !reflect.DeepEqual(got.Languages, parent.Languages) {
+			t.Errorf("subproject %d languages = %v, want %v", i, got.Languages, parent.Languages)
+		}
+	}
+}
+
+func TestParseRepoConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "bad switch", content: "showDemo: maybe\n"},
+		{name: "missing path", content: "subprojects:\n  - name: x\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseRepoConfig(tt.content); err == nil {
+				t.Error("err = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestParseRepoConfigDisplay(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Display
+	}{
+		{name: "empty file shows everything", content: "", want: DefaultDisplay()},
+		{name: "other keys ignored", content: "owner: octo\n", want: DefaultDisplay()},
+		{name: "one switch off", content: "showDemo: false\n", want: Display{ShowTechnologies: true, ShowHighlights: true}},
+		{
+			name:    "all switches off",
+			content: "showDemo: no\nshowTechnologies: off\nshowHighlights: \"false\"\n",
+			want:    Display{},
+		},
+		{
+			name:    "explicit true and comments",
+			content: "# card sections\nshowDemo: yes # keep the button\nshowHighlights: False\n",
+			want:    Display{ShowDemo: true, ShowTechnologies: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseRepoConfig(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Display != tt.want {
+				t.Errorf("Display = %+v, want %+v", cfg.Display, tt.want)
+			}
+		})
+	}
+}
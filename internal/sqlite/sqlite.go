@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+
+	// Pure-Go driver, so the binary still builds without cgo
+	_ "modernc.org/sqlite"
+)
+
+// schema keeps one row per project per run, so the portfolio's evolution
+// can be queried over time.
+const schema = `CREATE TABLE IF NOT EXISTS projects (
+	run_at       TEXT    NOT NULL,
+	slug         TEXT    NOT NULL,
+	name         TEXT    NOT NULL,
+	content_hash TEXT    NOT NULL,
+	featured     INTEGER NOT NULL,
+	category     TEXT    NOT NULL,
+	position     INTEGER NOT NULL,
+	PRIMARY KEY (run_at, slug)
+)`
+
+const upsert = `INSERT INTO projects (run_at, slug, name, content_hash, featured, category, position)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (run_at, slug) DO UPDATE SET
+	name = excluded.name,
+	content_hash = excluded.content_hash,
+	featured = excluded.featured,
+	category = excluded.category,
+	position = excluded.position`
+
+// Sink records the projects of every sync run in a local SQLite database,
+// alongside Contentful.
+type Sink struct {
+	db *sql.DB
+}
+
+// Open opens or creates the database at path and its projects table.
+// ":memory:" gives a private in-memory database.
+func Open(path string) (*Sink, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	// Every connection to ":memory:" would get its own empty database
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create projects table: %w", err)
+	}
+	return &Sink{db: db}, nil
+}
+
+// Record upserts projects as the rows of the run at runAt, stored as RFC 3339
+// in UTC. Recording the same run again replaces its rows.
+func (s *Sink) Record(ctx context.Context, runAt time.Time, projects []contentful.Project) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, upsert)
+	if err != nil {
+		return fmt.Errorf("prepare upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	ts := runAt.UTC().Format(time.RFC3339)
+	for i, p := range projects {
+		if _, err := stmt.ExecContext(ctx, ts, p.Slug, p.Name, p.ComputeContentHash(), p.Featured, p.Category, i); err != nil {
+			return fmt.Errorf("upsert %s: %w", p.Slug, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database.
+func (s *Sink) Close() error {
+	return s.db.Close()
+}
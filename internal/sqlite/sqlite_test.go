@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+type row struct {
+	RunAt    string
+	Slug     string
+	Name     string
+	Hash     string
+	Featured bool
+	Category string
+	Position int
+}
+
+func readRows(t *testing.T, s *Sink) []row {
+	t.Helper()
+	rows, err := s.db.Query(`SELECT run_at, slug, name, content_hash, featured, category, position FROM projects ORDER BY run_at, position`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var out []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.RunAt, &r.Slug, &r.Name, &r.Hash, &r.Featured, &r.Category, &r.Position); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestRecord(t *testing.T) {
+	s, err := Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	api := contentful.Project{Name: "API", Slug: "api", Category: "Backend", Featured: true}
+	web := contentful.Project{Name: "Web", Slug: "web", Category: "Web"}
+	first := time.Date(2026, 10, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	second := time.Date(2026, 10, 2, 10, 0, 0, 0, time.UTC)
+
+	ctx := context.Background()
+	if err := s.Record(ctx, first, []contentful.Project{api, web}); err != nil {
+		t.Fatal(err)
+	}
+	// Recording a run again replaces its rows instead of failing
+	web.Featured = true
+	if err := s.Record(ctx, first, []contentful.Project{api, web}); err != nil {
+		t.Fatal(err)
+	}
+	api.Category = "DevOps"
+	if err := s.Record(ctx, second, []contentful.Project{api}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []row{
+		{RunAt: "2026-10-01T10:00:00Z", Slug: "api", Name: "API", Hash: contentful.Project{Name: "API", Slug: "api", Category: "Backend"}.ComputeContentHash(), Featured: true, Category: "Backend", Position: 0},
+		{RunAt: "2026-10-01T10:00:00Z", Slug: "web", Name: "Web", Hash: web.ComputeContentHash(), Featured: true, Category: "Web", Position: 1},
+		{RunAt: "2026-10-02T10:00:00Z", Slug: "api", Name: "API", Hash: api.ComputeContentHash(), Featured: true, Category: "DevOps", Position: 0},
+	}
+	if got := readRows(t, s); !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %+v, want %+v", got, want)
+	}
+	if want[0].Hash == want[2].Hash {
+		t.Error("a category change did not change the content hash")
+	}
+}
+
+func TestRecordCancelled(t *testing.T) {
+	s, err := Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.Record(ctx, time.Now(), []contentful.Project{{Slug: "api"}}); err == nil {
+		t.Fatal("Record with a cancelled context succeeded")
+	}
+	if got := readRows(t, s); len(got) != 0 {
+		t.Errorf("rows = %+v, want none", got)
+	}
+}
@@ -0,0 +1,40 @@
+package syncer
+
+import (
+	"context"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/github"
+)
+
+// GitHubClient is the subset of *github.Client the syncer uses, so a fake
+// returning fixtures can stand in for the API.
+type GitHubClient interface {
+	ListRepos(ctx context.Context, user string) ([]github.Repo, error)
+	GetRepoLanguages(ctx context.Context, user, repo string) (map[string]int, error)
+	GetREADME(ctx context.Context, owner, repo string, maxBytes int64) (string, error)
+	GetFile(ctx context.Context, owner, repo, path string, maxBytes int64) (content string, found bool, err error)
+	HasTags(ctx context.Context, owner, repo string) (bool, error)
+	GetHeadSHA(ctx context.Context, owner, repo string) (string, error)
+}
+
+// CMAClient is the subset of *contentful.Client the syncer uses to read and
+// write a target.
+type CMAClient interface {
+	ResolveEntryID(ctx context.Context, entryID, sectionID string) (string, error)
+	GetProjects(ctx context.Context, entryID string) (*contentful.ProjectsResult, error)
+	GetProjectsAtVersion(ctx context.Context, entryID string, version int) (*contentful.ProjectsResult, error)
+	UpdateProjects(ctx context.Context, result *contentful.ProjectsResult, projects []contentful.Project) (int, error)
+	UpdateLocalizedProjects(ctx context.Context, result *contentful.ProjectsResult, byLocale map[string][]contentful.Project) (int, error)
+	WaitBeforePublish(ctx context.Context, d time.Duration)
+	PublishEntry(ctx context.Context, entryID string, version int) error
+	GetBuildLog(ctx context.Context) (*contentful.BuildLogResult, error)
+	WriteStats(ctx context.Context, sectionID string, stats contentful.PortfolioStats) error
+}
+
+// Sink is an additional destination, such as *sqlite.Sink, that records
+// the projects of each run.
+type Sink interface {
+	Record(ctx context.Context, runAt time.Time, projects []contentful.Project) error
+}
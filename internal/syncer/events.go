@@ -0,0 +1,47 @@
+package syncer
+
+// EventKind identifies a progress event emitted during Run.
+type EventKind string
+
+const (
+	// EventStageStarted marks the start of a pipeline stage; Stage is set.
+	EventStageStarted EventKind = "stage-started"
+
+	// EventRepoFetched follows each repo's details fetch; Repo is set.
+	EventRepoFetched EventKind = "repo-fetched"
+
+	// EventEnrichBatchSent precedes each Gemini request; Count is the
+	// number of projects in the batch.
+	EventEnrichBatchSent EventKind = "enrich-batch-sent"
+
+	// EventPublished follows a successful publish; Target and Count (the
+	// projects written) are set.
+	EventPublished EventKind = "published"
+)
+
+// Pipeline stages reported by EventStageStarted.
+const (
+	StageListRepos = "list-repos"
+	StageDetails   = "details"
+	StageEnrich    = "enrich"
+	StageHeuristic = "heuristic"
+	StageWrite     = "write"
+)
+
+// Event is a typed progress notification for hosts that render their own
+// progress instead of reading the log.
+type Event struct {
+	Kind   EventKind
+	Stage  string
+	Repo   string
+	Target string
+	Count  int
+}
+
+// emit passes e to the run's event callback, if any. Events from concurrent
+// stages may arrive from several goroutines at once.
+func (s *Syncer) emit(e Event) {
+	if s.onEvent != nil {
+		s.onEvent(e)
+	}
+}
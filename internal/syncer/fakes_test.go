@@ -0,0 +1,353 @@
+package syncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/config"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/enricher"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/github"
+	servicekit "github.com/alberto-moreno-sa/go-service-kit/contentful"
+	githubapi "github.com/alberto-moreno-sa/go-service-kit/github"
+)
+
+// fakeGitHub serves repos and READMEs from fixtures and counts detail
+// requests per repo. Language requests take delay plus a random share of
+// jitter, and peak records how many were in flight at once.
+type fakeGitHub struct {
+	repos     []github.Repo
+	readmes   map[string]string
+	files     map[string]string
+	readmeErr map[string]error
+	delay     time.Duration
+	jitter    time.Duration
+
+	mu       sync.Mutex
+	fetched  map[string]int
+	inFlight int
+	peak     int
+}
+
+func (f *fakeGitHub) ListRepos(ctx context.Context, user string) ([]github.Repo, error) {
+	return f.repos, nil
+}
+
+func (f *fakeGitHub) GetRepoLanguages(ctx context.Context, user, repo string) (map[string]int, error) {
+	f.mu.Lock()
+	if f.fetched == nil {
+		f.fetched = make(map[string]int)
+	}
+	f.fetched[repo]++
+	f.inFlight++
+	f.peak = max(f.peak, f.inFlight)
+	f.mu.Unlock()
+
+	time.Sleep(f.delay + rand.N(f.jitter+1))
+
+	f.mu.Lock()
+	f.inFlight--
+	f.mu.Unlock()
+	return map[string]int{"Go": 1000}, nil
+}
+
+func (f *fakeGitHub) GetREADME(ctx context.Context, owner, repo string, maxBytes int64) (string, error) {
+	if err := f.readmeErr[repo]; err != nil {
+		return "", err
+	}
+	return f.readmes[repo], nil
+}
+
+func (f *fakeGitHub) GetFile(ctx context.Context, owner, repo, path string, maxBytes int64) (string, bool, error) {
+	content, ok := f.files[repo+"/"+path]
+	return content, ok, nil
+}
+
+func (f *fakeGitHub) HasTags(ctx context.Context, owner, repo string) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeGitHub) GetHeadSHA(ctx context.Context, owner, repo string) (string, error) {
+	return "abc123", nil
+}
+
+func (f *fakeGitHub) fetchCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, c := range f.fetched {
+		n += c
+	}
+	return n
+}
+
+// fakeCMA holds the "projects" entry and the build log in memory and records
+// every write to it. Other entries, such as the archive or sections, only
+// keep their default-locale projects in entries.
+type fakeCMA struct {
+	mu        sync.Mutex
+	projects  []contentful.Project
+	localized map[string][]contentful.Project
+	version   int
+	buildLog  []contentful.BuildLogEntry
+	entries   map[string][]contentful.Project
+	stats     map[string]contentful.PortfolioStats
+
+	updateErr    error
+	beforeUpdate func()
+	updates      []map[string][]contentful.Project
+	waits        []time.Duration
+	published    []int
+}
+
+func (f *fakeCMA) ResolveEntryID(ctx context.Context, entryID, sectionID string) (string, error) {
+	if sectionID != "" {
+		return "section-" + sectionID, nil
+	}
+	return entryID, nil
+}
+
+func (f *fakeCMA) GetProjects(ctx context.Context, entryID string) (*contentful.ProjectsResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if entryID != "projects" {
+		return &contentful.ProjectsResult{Projects: f.entries[entryID], EntryID: entryID, Version: 1}, nil
+	}
+	localized := map[string][]contentful.Project{contentful.DefaultLocale: f.projects}
+	for locale, projects := range f.localized {
+		localized[locale] = projects
+	}
+	return &contentful.ProjectsResult{
+		Projects:  append([]contentful.Project(nil), f.projects...),
+		Localized: localized,
+		EntryID:   entryID,
+		Version:   f.version,
+	}, nil
+}
+
+func (f *fakeCMA) GetProjectsAtVersion(ctx context.Context, entryID string, version int) (*contentful.ProjectsResult, error) {
+	return nil, fmt.Errorf("no snapshot of version %d", version)
+}
+
+func (f *fakeCMA) UpdateProjects(ctx context.Context, result *contentful.ProjectsResult, projects []contentful.Project) (int, error) {
+	return f.UpdateLocalizedProjects(ctx, result, map[string][]contentful.Project{contentful.DefaultLocale: projects})
+}
+
+func (f *fakeCMA) UpdateLocalizedProjects(ctx context.Context, result *contentful.ProjectsResult, byLocale map[string][]contentful.Project) (int, error) {
+	if f.beforeUpdate != nil {
+		f.beforeUpdate()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.updateErr != nil {
+		return 0, f.updateErr
+	}
+	if result.EntryID != "projects" {
+		if f.entries == nil {
+			f.entries = make(map[string][]contentful.Project)
+		}
+		f.entries[result.EntryID] = byLocale[contentful.DefaultLocale]
+		return result.Version + 1, nil
+	}
+	f.updates = append(f.updates, byLocale)
+	f.projects = byLocale[contentful.DefaultLocale]
+	for locale, projects := range byLocale {
+		if locale == contentful.DefaultLocale {
+			continue
+		}
+		if f.localized == nil {
+			f.localized = make(map[string][]contentful.Project)
+		}
+		f.localized[locale] = projects
+	}
+	f.version++
+	return f.version, nil
+}
+
+func (f *fakeCMA) WaitBeforePublish(ctx context.Context, d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.waits = append(f.waits, d)
+}
+
+func (f *fakeCMA) PublishEntry(ctx context.Context, entryID string, version int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if entryID != "projects" {
+		return nil
+	}
+	f.published = append(f.published, version)
+	return nil
+}
+
+func (f *fakeCMA) GetBuildLog(ctx context.Context) (*contentful.BuildLogResult, error) {
+	return &contentful.BuildLogResult{Entries: f.buildLog}, nil
+}
+
+func (f *fakeCMA) WriteStats(ctx context.Context, sectionID string, stats contentful.PortfolioStats) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.stats == nil {
+		f.stats = make(map[string]contentful.PortfolioStats)
+	}
+	f.stats[sectionID] = stats
+	return nil
+}
+
+// testRepo returns a repo pushed the given number of days before now.
+func testRepo(name string, daysAgo int) github.Repo {
+	return github.Repo{Repo: githubapi.Repo{
+		Name:     name,
+		HTMLURL:  "https://github.com/octo/" + name,
+		Size:     100,
+		PushedAt: time.Now().Add(-time.Duration(daysAgo) * 24 * time.Hour),
+	}}
+}
+
+// testConfig returns a config with the defaults Run relies on.
+func testConfig() *config.Config {
+	return &config.Config{
+		GitHubUsername:   "octo",
+		MaxFeatured:      2,
+		MaxProjects:      10,
+		FetchConcurrency: 4,
+		ReadmeMaxBytes:   1 << 20,
+		GeminiBatchSize:  10,
+	}
+}
+
+// successEntry is a successful build-log entry of this service at ts.
+func successEntry(ts time.Time, configHash string) contentful.BuildLogEntry {
+	return contentful.BuildLogEntry{
+		BuildLogEntry: servicekit.BuildLogEntry{
+			Service:   ServiceName,
+			Timestamp: ts.UTC().Format(time.RFC3339),
+			Status:    "success",
+		},
+		ConfigHash: configHash,
+	}
+}
+
+// unchangedEntry is a no-changes build-log entry of this service at ts.
+func unchangedEntry(ts time.Time, configHash string) contentful.BuildLogEntry {
+	e := successEntry(ts, configHash)
+	e.Status = "no-changes"
+	return e
+}
+
+// degradedEntry is a degraded build-log entry of this service at ts.
+func degradedEntry(ts time.Time, configHash string) contentful.BuildLogEntry {
+	e := successEntry(ts, configHash)
+	e.Status = "degraded"
+	return e
+}
+
+// recordRun appends the build-log entry cmd/sync writes for stats to f's
+// log, keeping this service's newest keep entries as BUILD_LOG_KEEP does.
+func (f *fakeCMA) recordRun(cfg *config.Config, stats *SyncStats, keep int) {
+	entry := successEntry(time.Now(), ConfigHash(cfg))
+	entry.Status = stats.Status
+	entry.FeaturedScheme = stats.FeaturedScheme
+	f.buildLog = append(f.buildLog, entry)
+	if len(f.buildLog) > keep {
+		f.buildLog = f.buildLog[len(f.buildLog)-keep:]
+	}
+}
+
+// fakeGenerator answers enrichment prompts with one project per repo named
+// in the prompt and translation prompts by prefixing each text with the
+// locale. A prompt naming a repo listed in fail gets a reply that does not
+// parse, so the enricher falls back to one repo at a time. Repos get the
+// "Backend" category unless categories names another.
+type fakeGenerator struct {
+	fail       map[string]bool
+	categories map[string]string
+
+	mu           sync.Mutex
+	requests     int
+	translations int
+	prompts      []string
+}
+
+func (g *fakeGenerator) GenerateContent(ctx context.Context, system, user string) (string, error) {
+	g.mu.Lock()
+	g.requests++
+	g.mu.Unlock()
+
+	if strings.HasPrefix(system, "You are translating") {
+		g.mu.Lock()
+		g.translations++
+		g.mu.Unlock()
+		var texts []enricher.Translation
+		if err := json.Unmarshal([]byte(user), &texts); err != nil {
+			return "", err
+		}
+		locale := strings.TrimSuffix(strings.Fields(strings.SplitN(system, " into ", 2)[1])[0], ".")
+		for i, t := range texts {
+			texts[i].ShortDescription = locale + " " + t.ShortDescription
+			texts[i].LongDescription = locale + " " + t.LongDescription
+		}
+		out, err := json.Marshal(texts)
+		return string(out), err
+	}
+
+	g.mu.Lock()
+	g.prompts = append(g.prompts, user)
+	g.mu.Unlock()
+
+	var repos []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(user), &repos); err != nil {
+		return "", err
+	}
+	var out []map[string]interface{}
+	for _, r := range repos {
+		if g.fail[r.Name] {
+			return "not json", nil
+		}
+		category := "Backend"
+		if c, ok := g.categories[r.Name]; ok {
+			category = c
+		}
+		out = append(out, map[string]interface{}{
+			"name":             r.Name,
+			"shortDescription": "About " + r.Name,
+			"longDescription":  r.Name + " does things.",
+			"technologies":     []string{"Go"},
+			"highlights":       []string{"Fast"},
+			"category":         category,
+			"gradient":         "from-cyan-500 to-blue-600",
+		})
+	}
+	data, err := json.Marshal(out)
+	return string(data), err
+}
+
+func (g *fakeGenerator) requestCount() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.requests
+}
+
+func (g *fakeGenerator) translationCount() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.translations
+}
+
+// fakeSink records the slugs of every run it is given.
+type fakeSink struct {
+	err  error
+	runs [][]string
+}
+
+func (f *fakeSink) Record(ctx context.Context, runAt time.Time, projects []contentful.Project) error {
+	f.runs = append(f.runs, projectSlugs(projects))
+	return f.err
+}
@@ -0,0 +1,24 @@
+package syncer
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// recordSink records the projects of the first target that was written,
+// as it now holds them. A sink failure is only a warning: the targets are
+// already up to date.
+func (s *Syncer) recordSink(ctx context.Context, targets []TargetStats) {
+	for _, ts := range targets {
+		if ts.Err != nil {
+			continue
+		}
+		if err := s.sink.Record(ctx, time.Now(), ts.Projects); err != nil {
+			log.Printf("WARNING: [sink] %v", err)
+			return
+		}
+		log.Printf("Recorded %d projects from %s in the sink", len(ts.Projects), ts.Name)
+		return
+	}
+}
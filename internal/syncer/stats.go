@@ -0,0 +1,61 @@
+package syncer
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/mapper"
+)
+
+// computeStats aggregates the final project list. Technologies are counted
+// case-insensitively; languages come from the raw projects matching a final
+// slug, so projects merged in by FillGaps without a fetch add none.
+func computeStats(projects []contentful.Project, raw []mapper.RawProject, now time.Time) contentful.PortfolioStats {
+	techs := make(map[string]bool)
+	slugs := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		slugs[p.Slug] = true
+		for _, t := range p.Technologies {
+			techs[strings.ToLower(t)] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	languages := []string{}
+	for _, r := range raw {
+		if !slugs[r.Slug] {
+			continue
+		}
+		for _, l := range r.Languages {
+			if !seen[l] {
+				seen[l] = true
+				languages = append(languages, l)
+			}
+		}
+	}
+	sort.Strings(languages)
+
+	return contentful.PortfolioStats{
+		ProjectCount:    len(projects),
+		TechnologyCount: len(techs),
+		LanguageCount:   len(languages),
+		Languages:       languages,
+		LastSync:        now.UTC().Format(time.RFC3339),
+	}
+}
+
+// writeStats writes the aggregates to the stats entry in the first target's
+// space. Failures are logged, not returned, like the archive write.
+func (s *Syncer) writeStats(ctx context.Context, projects []contentful.Project, raw []mapper.RawProject) {
+	stats := computeStats(projects, raw, time.Now())
+	if err := s.targets[0].CMA.WriteStats(ctx, s.cfg.StatsSectionID, stats); err != nil {
+		log.Printf("WARNING: [stats] %v", err)
+		return
+	}
+	log.Printf("Wrote stats: %d projects, %d technologies, %d languages",
+		stats.ProjectCount, stats.TechnologyCount, stats.LanguageCount)
+}
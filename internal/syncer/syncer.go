@@ -2,146 +2,1465 @@ package syncer
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
-	githubapi "github.com/alberto-moreno-sa/go-service-kit/github"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/backup"
 	"github.com/alberto-moreno-sa/github-cms-sync/internal/config"
 	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
 	"github.com/alberto-moreno-sa/github-cms-sync/internal/enricher"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/github"
 	"github.com/alberto-moreno-sa/github-cms-sync/internal/heuristic"
 	"github.com/alberto-moreno-sa/github-cms-sync/internal/mapper"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/transform"
 )
 
-// SyncStats holds the results of a sync run.
+// ServiceName identifies this tool's entries in the shared build log.
+const ServiceName = "github-cms-sync"
+
+// FeaturedScheme versions how featured flags are kept between runs. Runs
+// record it in the build log; the first run under a newer scheme recomputes
+// featured from scratch instead of keeping flags the old one left.
+const FeaturedScheme = 1
+
+// maxFetchFailureRatio is the share of repos whose details may fail to fetch
+// before fetchDetails gives up instead of enriching incomplete data.
+const maxFetchFailureRatio = 0.5
+
+// repoConfigMaxBytes caps the per-repo config file download.
+const repoConfigMaxBytes = 16 * 1024
+
+// SyncStats holds the results of a sync run. NewAdded and Total describe
+// the first target; Targets has the per-space breakdown.
 type SyncStats struct {
 	NewAdded int
 	Total    int
 	Status   string
+	Targets  []TargetStats
+
+	AddedSlugs   []string
+	RemovedSlugs []string
+	UpdatedSlugs []string
+
+	// ArchiveTotal is the number of archived projects written to the
+	// archive entry, when one is configured.
+	ArchiveTotal int
+
+	// Churn is how far the first target's ordering moved in this run.
+	Churn heuristic.Churn
+
+	// Pruned is the number of CMS projects dropped because their repo no
+	// longer exists.
+	Pruned int
+
+	// Sections has one entry per category section written, when
+	// SectionRoutes is configured.
+	Sections []TargetStats
+
+	// FetchFailures is the number of repos whose languages or README could
+	// not be fetched; they were enriched with what was available.
+	FetchFailures int
+
+	// Raw and Projects hold the output of the last stage when
+	// RunOptions.StopAfter ended the run early: Raw for StageDetails,
+	// Projects for StageEnrich (every enriched project) and StageHeuristic
+	// (the final selection).
+	Raw      []mapper.RawProject
+	Projects []contentful.Project
+
+	// Degraded is the number of repos that failed enrichment. When non-zero
+	// the successful projects were merged into each target's content
+	// without removing anything.
+	Degraded int
+
+	// FeaturedScheme is the featured scheme the written flags follow. Runs
+	// that exit before writing carry forward the scheme already recorded;
+	// dry runs leave it zero.
+	FeaturedScheme int
+}
+
+// TargetStats holds the write result for a single Contentful target.
+type TargetStats struct {
+	Name     string
+	NewAdded int
+	Total    int
+	Diff     contentful.ProjectDiff
+	Churn    heuristic.Churn
+	Err      error
+
+	// Pruned counts orphaned projects dropped from a degraded merge.
+	Pruned int
+
+	// Unchanged is set when the target already held exactly these
+	// projects and the write was skipped.
+	Unchanged bool
+
+	// Projects is the final list written (or, in a dry run, that would
+	// have been written) to this target.
+	Projects []contentful.Project
+}
+
+// RunOptions changes how Run executes.
+type RunOptions struct {
+	// DryRun runs the full pipeline and computes each target's diff, but
+	// skips backups, UpdateProjects, PublishEntry and the archive write.
+	DryRun bool
+
+	// AgainstVersion, in a dry run, diffs the first target against this
+	// published version of its projects entry instead of its current
+	// content. Zero uses the current content.
+	AgainstVersion int
+
+	// OnEvent, when set, receives progress events. It may be called from
+	// several goroutines at once and must not block for long.
+	OnEvent func(Event)
+
+	// StopAfter ends the run once the named stage (StageDetails,
+	// StageEnrich or StageHeuristic) finishes, returning its output in
+	// SyncStats.Raw or SyncStats.Projects with status "stopped". Empty runs
+	// every stage.
+	StopAfter string
+}
+
+// Target is a Contentful space the enriched projects are written to.
+type Target struct {
+	Name      string
+	CMA       CMAClient
+	EntryID   string
+	SectionID string
 }
 
 // Syncer orchestrates the GitHub → CMS sync pipeline.
 type Syncer struct {
-	cfg    *config.Config
-	github *githubapi.Client
-	cma    *contentful.Client
+	cfg     *config.Config
+	github  GitHubClient
+	targets []Target
+	http    *http.Client
+
+	// generator, when set, replaces the Gemini calls.
+	generator enricher.Generator
+
+	// sink, when set, records the projects of every run that writes.
+	sink Sink
+
+	onEvent func(Event)
 }
 
-// New creates a new Syncer.
-func New(cfg *config.Config, gh *githubapi.Client, cma *contentful.Client) *Syncer {
+// New creates a new Syncer that writes to every given target. httpClient is
+// shared with the enricher's direct API calls.
+func New(cfg *config.Config, gh GitHubClient, targets []Target, httpClient *http.Client) *Syncer {
 	return &Syncer{
-		cfg:    cfg,
-		github: gh,
-		cma:    cma,
+		cfg:     cfg,
+		github:  gh,
+		targets: targets,
+		http:    httpClient,
 	}
 }
 
+// SetGenerator replaces the Gemini calls made by enrichment and translation,
+// e.g. with a fake returning canned responses.
+func (s *Syncer) SetGenerator(g enricher.Generator) {
+	s.generator = g
+}
+
+// SetSink records the projects of every run that reaches the write stage
+// in sink, in addition to the targets.
+func (s *Syncer) SetSink(sink Sink) {
+	s.sink = sink
+}
+
 // Run executes the full sync pipeline.
-func (s *Syncer) Run(ctx context.Context) (*SyncStats, error) {
-	// 1. Fetch repos
-	log.Println("Fetching GitHub repositories...")
-	repos, err := s.github.ListRepos(ctx, s.cfg.GitHubUsername)
+func (s *Syncer) Run(ctx context.Context, opts RunOptions) (*SyncStats, error) {
+	s.onEvent = opts.OnEvent
+	defer func() { s.onEvent = nil }()
+
+	// 1-2. Fetch and filter repos
+	filtered, archived, listed, err := s.listRepos(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("list repos: %w", err)
+		return nil, err
 	}
-	log.Printf("Found %d public repos", len(repos))
-
-	// 2. Filter
-	filtered := mapper.FilterRepos(repos, s.cfg.GitHubUsername)
-	log.Printf("After filtering: %d repos", len(filtered))
 
+	// No repos left usually means a bad username or token, not a real sync
 	if len(filtered) == 0 {
-		return &SyncStats{Status: "success"}, nil
+		var archiveTotal int
+		if len(archived) > 0 && !opts.DryRun {
+			archiveTotal = s.syncArchive(ctx, archived)
+		}
+		if s.cfg.Strict {
+			return nil, fmt.Errorf("no repos left after filtering %s", s.cfg.GitHubUsername)
+		}
+		log.Println("WARNING: no active repos left after filtering, nothing to sync")
+		return &SyncStats{Status: "empty", ArchiveTotal: archiveTotal, FeaturedScheme: s.carriedScheme(ctx)}, nil
+	}
+	topicFeatured := withTopic(filtered, s.cfg.FeaturedTopic)
+
+	// Dry runs, as used by diff, exist to show the changes, so they always run
+	if s.cfg.SkipUnchanged && !opts.DryRun && !s.cfg.ForceUpdate && !s.cfg.ForceEnrich {
+		unchanged, err := s.unchangedSinceLastSync(ctx, filtered)
+		if err != nil {
+			log.Printf("WARNING: change check failed, running full sync: %v", err)
+		} else if unchanged {
+			log.Println("No repos pushed since the last successful sync, skipping.")
+			return &SyncStats{Status: "no-changes", FeaturedScheme: s.carriedScheme(ctx)}, nil
+		}
+	}
+
+	var existing []contentful.Project
+	if s.cfg.FillGaps {
+		filtered, existing, err = s.findGaps(ctx, filtered)
+		if err != nil {
+			return nil, fmt.Errorf("fill gaps: %w", err)
+		}
+		if len(filtered) == 0 {
+			log.Println("Every repo already has a CMS project, nothing to fill.")
+			return &SyncStats{Status: "no-changes", FeaturedScheme: s.carriedScheme(ctx)}, nil
+		}
+		archived = nil
+		log.Printf("Filling %d gaps", len(filtered))
 	}
 
 	// 3. Fetch details concurrently
 	log.Println("Fetching repo details (languages, READMEs)...")
-	rawProjects, err := s.fetchDetails(ctx, filtered)
+	s.emit(Event{Kind: EventStageStarted, Stage: StageDetails})
+	fetched := append(filtered, archived...)
+	rawProjects, fetchFailures, err := s.fetchDetails(ctx, fetched)
 	if err != nil {
 		return nil, fmt.Errorf("fetch details: %w", err)
 	}
+	rawProjects = dedupeSlugs(rawProjects)
+
+	var src *sources
+	var pruned int
+	if s.cfg.PruneOrphans {
+		src = newSources(listed, fetched, rawProjects)
+		existing, pruned = src.prune(existing)
+	}
+	if opts.StopAfter == StageDetails {
+		return &SyncStats{Status: "stopped", Raw: rawProjects, FetchFailures: fetchFailures}, nil
+	}
 
 	// 4. Enrich with Gemini
 	log.Println("Enriching projects with Gemini AI...")
-	enriched, err := enricher.Enrich(ctx, s.cfg.GeminiAPIKey, rawProjects)
+	s.emit(Event{Kind: EventStageStarted, Stage: StageEnrich})
+	enriched, err := s.enrich(ctx, rawProjects)
 	if err != nil {
 		return nil, fmt.Errorf("enrich: %w", err)
 	}
 	log.Printf("Enriched %d projects", len(enriched))
+	if opts.StopAfter == StageEnrich {
+		return &SyncStats{Status: "stopped", Projects: enriched, FetchFailures: fetchFailures}, nil
+	}
+
+	// Repos that were fetched but not enriched leave the run degraded: the
+	// CMS keeps its copy of them instead of losing them.
+	unenriched := missingSlugs(rawProjects, enriched)
+	if len(unenriched) > 0 {
+		log.Printf("WARNING: %d repos failed enrichment, merging into existing content: %v", len(unenriched), unenriched)
+	}
+
+	enriched, archiveProjects := splitArchivedProjects(enriched, archived)
+	if len(s.cfg.FieldTransforms) > 0 {
+		log.Printf("Applying %d field transforms", len(s.cfg.FieldTransforms))
+	}
+	noLiveURL := emptyLiveURLs(enriched)
+	// Existing CMS projects were transformed when first written
+	enriched = append(existing, s.applyTransforms(enriched)...)
 
 	// 5. Apply featured heuristic
-	projects := heuristic.ApplyFeatured(enriched, s.cfg.MaxFeatured, s.cfg.MaxProjects)
-	log.Printf("Final selection: %d projects (%d featured)", len(projects), s.cfg.MaxFeatured)
+	s.emit(Event{Kind: EventStageStarted, Stage: StageHeuristic})
+	featuredOpts := heuristic.FeaturedOptions{
+		MaxFeatured: s.cfg.MaxFeatured,
+		MaxTotal:    s.cfg.MaxProjects,
+		Priority:    s.cfg.PriorityOrder,
 
-	// 6. Fetch current state from Contentful
-	log.Println("Fetching current projects from Contentful...")
-	result, err := s.cma.GetProjects(ctx, s.cfg.EntryID)
+		Forced:              topicFeatured,
+		ForcedOutsideBudget: s.cfg.FeaturedTopicOutsideBudget,
+		StaleAfter:          s.cfg.StaleAfter,
+	}
+	projects := heuristic.ApplyFeatured(enriched, featuredOpts)
+	featured := 0
+	for _, p := range projects {
+		if p.Featured {
+			featured++
+		}
+	}
+	log.Printf("Final selection: %d projects (%d featured)", len(projects), featured)
+	if opts.StopAfter == StageHeuristic {
+		return &SyncStats{Status: "stopped", Projects: projects, FetchFailures: fetchFailures}, nil
+	}
+
+	// 6-8. Write to every target concurrently
+	s.emit(Event{Kind: EventStageStarted, Stage: StageWrite})
+	wopts := writeOptions{dryRun: opts.DryRun, degraded: len(unenriched) > 0, featured: &featuredOpts, emptyLiveURL: noLiveURL}
+	if !s.cfg.RecomputeFeatured {
+		reconciled, err := s.featuredReconciled(ctx)
+		if err != nil {
+			log.Printf("WARNING: featured reconciliation check failed, keeping CMS flags: %v", err)
+		}
+		wopts.reconcile = err == nil && !reconciled
+	}
+	if wopts.degraded {
+		wopts.sources = src
+		wopts.pushedAt = make(map[string]time.Time, len(rawProjects))
+		for _, raw := range rawProjects {
+			wopts.pushedAt[raw.Slug] = raw.PushedAt
+		}
+	}
+	if opts.DryRun {
+		wopts.againstVersion = opts.AgainstVersion
+	} else if len(s.cfg.Locales) > 0 {
+		// Translated on first use, so runs that write nothing skip it
+		wopts.translations = sync.OnceValue(func() map[string]map[string]enricher.Translation {
+			return s.translate(ctx, projects)
+		})
+	}
+	targetStats := s.writeTargets(ctx, projects, wopts)
+
+	stats := &SyncStats{Status: "success", Targets: targetStats, Pruned: pruned, Degraded: len(unenriched), FetchFailures: fetchFailures}
+	if opts.DryRun {
+		stats.Status = "dry-run"
+	} else {
+		stats.FeaturedScheme = FeaturedScheme
+	}
+	failed := 0
+	for _, ts := range targetStats {
+		if ts.Err != nil {
+			failed++
+		}
+	}
+	if failed == len(targetStats) {
+		return nil, targetStats[0].Err
+	}
+	if failed > 0 {
+		stats.Status = "partial"
+	} else if stats.Degraded > 0 && !opts.DryRun {
+		stats.Status = "degraded"
+	} else if !opts.DryRun && allUnchanged(targetStats) {
+		stats.Status = "no-changes"
+	}
+	stats.Pruned += targetStats[0].Pruned
+	stats.NewAdded = targetStats[0].NewAdded
+	stats.Total = targetStats[0].Total
+	stats.AddedSlugs = targetStats[0].Diff.Added
+	stats.RemovedSlugs = targetStats[0].Diff.Removed
+	stats.UpdatedSlugs = targetStats[0].Diff.Updated
+	stats.Churn = targetStats[0].Churn
+
+	if opts.DryRun {
+		log.Printf("Dry run: computed changes for %d/%d targets, nothing written.", len(targetStats)-failed, len(targetStats))
+		return stats, nil
+	}
+	log.Printf("Successfully synced and published to %d/%d targets.", len(targetStats)-failed, len(targetStats))
+
+	if len(archiveProjects) > 0 {
+		stats.ArchiveTotal = s.writeArchive(ctx, archiveProjects)
+	}
+	if len(s.cfg.SectionRoutes) > 0 {
+		stats.Sections = s.writeSections(ctx, projects, noLiveURL)
+	}
+	if s.cfg.WriteStatsEntry {
+		s.writeStats(ctx, projects, rawProjects)
+	}
+	if s.sink != nil {
+		s.recordSink(ctx, targetStats)
+	}
+
+	return stats, nil
+}
+
+// enrich enriches raw projects with Gemini, through the enrichment cache
+// when one is configured.
+func (s *Syncer) enrich(ctx context.Context, raw []mapper.RawProject) ([]contentful.Project, error) {
+	var cache *enricher.Cache
+	if s.cfg.EnrichCachePath != "" {
+		var err error
+		cache, err = enricher.LoadCache(s.cfg.EnrichCachePath)
+		if err != nil {
+			log.Printf("WARNING: enrichment cache disabled: %v", err)
+		}
+	}
+	return enricher.Enrich(ctx, enricher.Options{
+		APIKey:     s.cfg.GeminiAPIKey,
+		Generator:  s.generator,
+		BaseURL:    s.cfg.GeminiBaseURL,
+		Model:      s.cfg.GeminiModel,
+		Seed:       s.cfg.RandomSeed,
+		HTTPClient: s.http,
+		StrictURLs: s.cfg.StrictURLs,
+
+		RetryOn:          s.cfg.GeminiRetryOn,
+		CategoryRules:    s.cfg.CategoryRules,
+		StrictCategories: s.cfg.Strict,
+
+		LongDescFromSection: s.cfg.LongDescSource == "readme-section",
+
+		Cache:        cache,
+		RefreshCache: s.cfg.ForceEnrich,
+
+		BatchSize:   s.cfg.GeminiBatchSize,
+		Concurrency: s.cfg.GeminiConcurrency,
+
+		OnBatch: func(n int) { s.emit(Event{Kind: EventEnrichBatchSent, Count: n}) },
+	}, raw)
+}
+
+// PreviewRow is one slug in a Preview report.
+type PreviewRow struct {
+	Slug string
+
+	// Status is "new" (would be added), "kept" (already in the CMS and
+	// selected), "dropped" (cut by the MaxProjects cap) or "orphaned" (in
+	// the CMS with no matching repo).
+	Status string
+}
+
+// Preview compares the filtered repos with the first target's projects
+// without enriching anything. Repos are ranked with the featured heuristic
+// to decide which fall outside MaxProjects. Rows are sorted by status, then
+// slug.
+func (s *Syncer) Preview(ctx context.Context) ([]PreviewRow, error) {
+	repos, _, _, err := s.listRepos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.primaryProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]contentful.Project, len(repos))
+	for i, r := range repos {
+		candidates[i] = contentful.Project{Slug: r.Name, PushedAt: r.PushedAt}
+	}
+	selected := heuristic.ApplyFeatured(candidates, heuristic.FeaturedOptions{
+		MaxFeatured: s.cfg.MaxFeatured,
+		MaxTotal:    s.cfg.MaxProjects,
+		Priority:    s.cfg.PriorityOrder,
+
+		Forced:              withTopic(repos, s.cfg.FeaturedTopic),
+		ForcedOutsideBudget: s.cfg.FeaturedTopicOutsideBudget,
+	})
+
+	inCMS := make(map[string]bool, len(result.Projects))
+	for _, p := range result.Projects {
+		inCMS[p.Slug] = true
+	}
+	kept := make(map[string]bool, len(selected))
+	for _, p := range selected {
+		kept[p.Slug] = true
+	}
+
+	var rows []PreviewRow
+	seen := make(map[string]bool, len(repos))
+	for _, r := range repos {
+		seen[r.Name] = true
+		status := "dropped"
+		if kept[r.Name] {
+			status = "new"
+			if inCMS[r.Name] {
+				status = "kept"
+			}
+		}
+		rows = append(rows, PreviewRow{Slug: r.Name, Status: status})
+	}
+	for _, p := range result.Projects {
+		if !seen[p.Slug] {
+			rows = append(rows, PreviewRow{Slug: p.Slug, Status: "orphaned"})
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Status != rows[j].Status {
+			return rows[i].Status < rows[j].Status
+		}
+		return rows[i].Slug < rows[j].Slug
+	})
+	return rows, nil
+}
+
+// primaryProjects fetches the first target's current projects entry.
+func (s *Syncer) primaryProjects(ctx context.Context) (*contentful.ProjectsResult, error) {
+	primary := s.targets[0]
+	entryID, err := primary.CMA.ResolveEntryID(ctx, primary.EntryID, primary.SectionID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := primary.CMA.GetProjects(ctx, entryID)
 	if err != nil {
 		return nil, fmt.Errorf("get projects: %w", err)
 	}
+	return result, nil
+}
+
+// preservedFeatured returns the slugs that are featured in current.
+func preservedFeatured(current []contentful.Project) map[string]bool {
+	pinned := make(map[string]bool)
+	for _, p := range current {
+		if p.Featured {
+			pinned[p.Slug] = true
+		}
+	}
+	return pinned
+}
+
+func allUnchanged(stats []TargetStats) bool {
+	for _, ts := range stats {
+		if !ts.Unchanged {
+			return false
+		}
+	}
+	return true
+}
+
+// withTopic returns the names of repos tagged with topic, or nil when topic
+// is empty.
+func withTopic(repos []github.Repo, topic string) map[string]bool {
+	if topic == "" {
+		return nil
+	}
+	slugs := make(map[string]bool)
+	for _, r := range repos {
+		for _, t := range r.Topics {
+			if strings.EqualFold(t, topic) {
+				slugs[r.Name] = true
+				break
+			}
+		}
+	}
+	return slugs
+}
+
+// findGaps returns the repos with no project of the same slug in the first
+// target's CMS content, plus that content with PushedAt restored from the
+// repo list so it ranks alongside the new projects.
+func (s *Syncer) findGaps(ctx context.Context, repos []github.Repo) ([]github.Repo, []contentful.Project, error) {
+	result, err := s.primaryProjects(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pushedAt := make(map[string]time.Time, len(repos))
+	for _, r := range repos {
+		pushedAt[r.Name] = r.PushedAt
+	}
+	existing := make([]contentful.Project, len(result.Projects))
+	present := make(map[string]bool, len(result.Projects))
+	for i, p := range result.Projects {
+		p.PushedAt = pushedAt[p.Slug]
+		existing[i] = p
+		present[p.Slug] = true
+	}
+
+	var missing []github.Repo
+	for _, r := range repos {
+		if !present[r.Name] {
+			missing = append(missing, r)
+		}
+	}
+	return missing, existing, nil
+}
+
+// FetchRaw runs only the fetch, filter and details stages and returns the raw
+// projects that would be sent to the enricher.
+func (s *Syncer) FetchRaw(ctx context.Context) ([]mapper.RawProject, error) {
+	filtered, archived, _, err := s.listRepos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(filtered)+len(archived) == 0 {
+		return nil, nil
+	}
+	rawProjects, _, err := s.fetchDetails(ctx, append(filtered, archived...))
+	if err != nil {
+		return nil, fmt.Errorf("fetch details: %w", err)
+	}
+	return rawProjects, nil
+}
+
+// listRepos fetches the user's repos and filters them, returning active and
+// archived repos separately. archived is empty unless an archive entry is set.
+// listed holds the name of every repo GitHub returned, before filtering.
+func (s *Syncer) listRepos(ctx context.Context) (active, archived []github.Repo, listed map[string]bool, err error) {
+	// 1. Fetch repos
+	log.Println("Fetching GitHub repositories...")
+	s.emit(Event{Kind: EventStageStarted, Stage: StageListRepos})
+	repos, err := s.github.ListRepos(ctx, s.cfg.GitHubUsername)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("list repos: %w", err)
+	}
+	log.Printf("Found %d public repos", len(repos))
+	listed = make(map[string]bool, len(repos))
+	for _, r := range repos {
+		listed[r.Name] = true
+	}
+
+	// 2. Filter
+	filtered := mapper.FilterRepos(repos, s.cfg.GitHubUsername, mapper.FilterOptions{
+		IncludeTemplates: s.cfg.IncludeTemplates,
+		KeepArchived:     s.cfg.ArchiveEntryID != "",
+		ExcludePatterns:  s.cfg.ExcludePatterns,
+		RequireTopic:     s.cfg.RequireTopic,
+		Include:          s.cfg.IncludeRepos,
+		Exclude:          s.cfg.ExcludeRepos,
+		MinSizeKB:        s.cfg.MinRepoSizeKB,
+		SizeAllow:        s.cfg.MinRepoSizeAllow,
+		MinStars:         s.cfg.MinStars,
+		StarsAllow:       s.cfg.MinStarsAllow,
+	})
+	active, archived = splitArchived(filtered)
+	log.Printf("After filtering: %d repos (%d archived)", len(active), len(archived))
+	return active, archived, listed, nil
+}
+
+// sources records which slugs still come from GitHub, to prune orphaned
+// CMS projects.
+type sources struct {
+	// listed holds every repo GitHub lists, including filtered-out ones.
+	listed map[string]bool
+	// fetched holds the repos whose details were fetched this run.
+	fetched map[string]bool
+	// expanded holds the slugs the fetched repos produced, sub-projects
+	// included.
+	expanded map[string]bool
+}
+
+func newSources(listed map[string]bool, fetched []github.Repo, raw []mapper.RawProject) *sources {
+	src := &sources{
+		listed:   listed,
+		fetched:  make(map[string]bool, len(fetched)),
+		expanded: make(map[string]bool, len(raw)),
+	}
+	for _, r := range fetched {
+		src.fetched[r.Name] = true
+	}
+	for _, p := range raw {
+		src.expanded[p.Slug] = true
+	}
+	return src
+}
+
+// has reports whether slug still has a repo or sub-project on GitHub. The
+// sub-projects of a repo that was not fetched this run, because a filter
+// hid it or it was no gap to fill, cannot be checked and are assumed to.
+func (src *sources) has(slug string) bool {
+	if src.listed[slug] || src.expanded[slug] {
+		return true
+	}
+	for repo := range src.listed {
+		if !src.fetched[repo] && strings.HasPrefix(slug, repo+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// prune drops projects whose repo or sub-project GitHub no longer has.
+// Repos that still exist but were filtered out are kept.
+func (src *sources) prune(projects []contentful.Project) ([]contentful.Project, int) {
+	kept := projects[:0]
+	for _, p := range projects {
+		if !src.has(p.Slug) {
+			log.Printf("Pruned orphaned project %s", p.Slug)
+			continue
+		}
+		kept = append(kept, p)
+	}
+	return kept, len(projects) - len(kept)
+}
+
+// dedupeSlugs drops sub-projects whose slug collides with a repo or another
+// sub-project, since the CMS tells projects apart by slug. Repos always win;
+// between sub-projects, the one whose parent sorts first does.
+func dedupeSlugs(projects []mapper.RawProject) []mapper.RawProject {
+	owner := make(map[string]string, len(projects))
+	for _, p := range projects {
+		if cur, ok := owner[p.Slug]; !ok || p.Parent < cur {
+			owner[p.Slug] = p.Parent
+		}
+	}
+	seen := make(map[string]bool, len(projects))
+	kept := projects[:0]
+	for _, p := range projects {
+		if owner[p.Slug] != p.Parent || seen[p.Slug] {
+			log.Printf("WARNING: sub-project %s of %s collides with another project's slug, skipping", p.Slug, p.Parent)
+			continue
+		}
+		seen[p.Slug] = true
+		kept = append(kept, p)
+	}
+	return kept
+}
+
+// syncArchive fetches, enriches and writes archived repos on their own, for
+// runs with no active repos to sync. Failures are logged and reported as
+// zero written.
+func (s *Syncer) syncArchive(ctx context.Context, archived []github.Repo) int {
+	raw, _, err := s.fetchDetails(ctx, archived)
+	if err != nil {
+		log.Printf("WARNING: [archive] fetch details: %v", err)
+		return 0
+	}
+	projects, err := s.enrich(ctx, raw)
+	if err != nil {
+		log.Printf("WARNING: [archive] enrich: %v", err)
+		return 0
+	}
+	return s.writeArchive(ctx, projects)
+}
+
+// writeArchive writes archived projects, newest first and never featured, to
+// the archive entry in the first target's space. Failures are logged and
+// reported as zero written.
+func (s *Syncer) writeArchive(ctx context.Context, projects []contentful.Project) int {
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].PushedAt.After(projects[j].PushedAt)
+	})
+	for i := range projects {
+		projects[i].Featured = false
+	}
+	noLiveURL := emptyLiveURLs(projects)
+	projects = s.applyTransforms(projects)
+
+	archive := Target{Name: "archive", CMA: s.targets[0].CMA, EntryID: s.cfg.ArchiveEntryID}
+	ts := s.writeTarget(ctx, archive, projects, writeOptions{emptyLiveURL: noLiveURL})
+	if ts.Err != nil {
+		log.Printf("WARNING: [archive] %v", ts.Err)
+		return 0
+	}
+	log.Printf("Archived %d projects", ts.Total)
+	return ts.Total
+}
+
+// emptyLiveURLs returns the slugs of projects without a liveUrl.
+func emptyLiveURLs(projects []contentful.Project) map[string]bool {
+	empty := make(map[string]bool)
+	for _, p := range projects {
+		if p.LiveURL == "" {
+			empty[p.Slug] = true
+		}
+	}
+	return empty
+}
+
+// applyTransforms runs the configured field transforms and clean-ups on
+// freshly enriched projects. None of them depend on the featured selection.
+func (s *Syncer) applyTransforms(projects []contentful.Project) []contentful.Project {
+	projects = transform.Apply(projects, s.cfg.FieldTransforms)
+	if s.cfg.StripMarkdown {
+		projects = transform.StripMarkdown(projects)
+	}
+	if s.cfg.NormalizeURLs {
+		projects = transform.NormalizeURLs(projects, s.cfg.Strict)
+	}
+	projects = transform.FilterTechnologies(projects, s.cfg.TechAliases, s.cfg.TechDenylist, s.cfg.TechAllowlist, s.cfg.MaxTechnologies)
+	projects = transform.FitHighlights(projects, s.cfg.HighlightsTotalMax)
+	return transform.SetDisplayDates(projects, s.cfg.DisplayDates)
+}
+
+// splitArchived separates archived repos from active ones.
+func splitArchived(repos []github.Repo) (active, archived []github.Repo) {
+	for _, r := range repos {
+		if r.Archived {
+			archived = append(archived, r)
+		} else {
+			active = append(active, r)
+		}
+	}
+	return active, archived
+}
+
+// splitArchivedProjects separates enriched projects whose slug belongs to an
+// archived repo.
+func splitArchivedProjects(projects []contentful.Project, archived []github.Repo) (active, archive []contentful.Project) {
+	if len(archived) == 0 {
+		return projects, nil
+	}
+	isArchived := make(map[string]bool, len(archived))
+	for _, r := range archived {
+		isArchived[r.Name] = true
+	}
+	for _, p := range projects {
+		if isArchived[p.Slug] {
+			archive = append(archive, p)
+		} else {
+			active = append(active, p)
+		}
+	}
+	return active, archive
+}
+
+// writeSections writes each category section's projects to the siteSection
+// with that sectionId in the first target's space. Sections are written one
+// after another; failures are logged and recorded in the returned stats.
+func (s *Syncer) writeSections(ctx context.Context, projects []contentful.Project, noLiveURL map[string]bool) []TargetStats {
+	bySection := transform.RouteSections(projects, s.cfg.SectionRoutes, s.cfg.SectionFallback)
+	ids := make([]string, 0, len(bySection))
+	for id := range bySection {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var stats []TargetStats
+	for _, id := range ids {
+		section := Target{Name: "section " + id, CMA: s.targets[0].CMA, SectionID: id}
+		ts := s.writeTarget(ctx, section, bySection[id], writeOptions{emptyLiveURL: noLiveURL})
+		if ts.Err != nil {
+			log.Printf("WARNING: [%s] %v", section.Name, ts.Err)
+		}
+		stats = append(stats, ts)
+	}
+	return stats
+}
+
+// writeOptions changes how writeTarget handles a target.
+type writeOptions struct {
+	// dryRun stops after computing the diff.
+	dryRun bool
+
+	// degraded merges projects into the target's current content instead
+	// of replacing it, and skips publishing unless PublishOnPartial is set.
+	// The merged list is featured, capped and ordered again with featured,
+	// ranking the CMS copies by their repo's pushedAt.
+	// sources, when set, prunes orphaned projects from the merged list.
+	degraded bool
+	pushedAt map[string]time.Time
+	sources  *sources
+
+	// featured, when set, re-ranks projects that were merged in degraded
+	// runs or pinned by the target's current content.
+	featured *heuristic.FeaturedOptions
+
+	// reconcile ignores the target's featured flags, which an older
+	// FeaturedScheme left, so featured is recomputed from scratch.
+	reconcile bool
+
+	// translations, when set, returns the text of each extra locale by
+	// slug. It is only called for targets that get written. A locale
+	// with a missing slug falls back to the default locale's text.
+	translations func() map[string]map[string]enricher.Translation
+
+	// emptyLiveURL holds the slugs enriched without a liveUrl, which keep
+	// the target's current one.
+	emptyLiveURL map[string]bool
+
+	// againstVersion reads the first target's current projects from this
+	// entry version's snapshot; zero reads the live entry.
+	againstVersion int
+}
+
+// writeTargets writes projects to each target in parallel, returning one
+// TargetStats per target in the same order as s.targets.
+func (s *Syncer) writeTargets(ctx context.Context, projects []contentful.Project, opts writeOptions) []TargetStats {
+	stats := make([]TargetStats, len(s.targets))
+
+	var wg sync.WaitGroup
+	for i, t := range s.targets {
+		wg.Add(1)
+		topts := opts
+		if i > 0 {
+			// Entry versions are per space; only the first target's applies
+			topts.againstVersion = 0
+		}
+		go func(i int, t Target) {
+			defer wg.Done()
+			stats[i] = s.writeTarget(ctx, t, projects, topts)
+			if stats[i].Err != nil {
+				log.Printf("WARNING: [%s] %v", t.Name, stats[i].Err)
+			}
+		}(i, t)
+	}
+	wg.Wait()
+
+	return stats
+}
+
+// writeTarget merges projects with the target's current content and writes
+// and publishes the result, as adjusted by opts.
+func (s *Syncer) writeTarget(ctx context.Context, t Target, projects []contentful.Project, opts writeOptions) TargetStats {
+	stats := TargetStats{Name: t.Name}
+
+	// 6. Fetch current state from Contentful
+	log.Printf("[%s] Fetching current projects from Contentful...", t.Name)
+	entryID, err := t.CMA.ResolveEntryID(ctx, t.EntryID, t.SectionID)
+	if err != nil {
+		stats.Err = err
+		return stats
+	}
+	var result *contentful.ProjectsResult
+	if opts.againstVersion > 0 {
+		log.Printf("[%s] Comparing against version %d", t.Name, opts.againstVersion)
+		result, err = t.CMA.GetProjectsAtVersion(ctx, entryID, opts.againstVersion)
+	} else {
+		result, err = t.CMA.GetProjects(ctx, entryID)
+	}
+	if err != nil {
+		stats.Err = fmt.Errorf("get projects: %w", err)
+		return stats
+	}
+	if opts.degraded {
+		projects = mergeInto(result.Projects, projects)
+		if opts.sources != nil {
+			projects, stats.Pruned = opts.sources.prune(projects)
+		}
+		// The CMS does not store push dates, so kept copies borrow their
+		// repo's; projects with no repo this run rank last
+		for i := range projects {
+			if projects[i].PushedAt.IsZero() {
+				projects[i].PushedAt = opts.pushedAt[projects[i].Slug]
+			}
+		}
+	}
+	if opts.featured != nil {
+		var pinned map[string]bool
+		if !opts.reconcile && !s.cfg.RecomputeFeatured {
+			pinned = preservedFeatured(result.Projects)
+		}
+		if opts.degraded || len(pinned) > 0 {
+			featured := *opts.featured
+			featured.Pinned = pinned
+			// projects is shared with the other targets unless merged
+			projects = heuristic.ApplyFeatured(slices.Clone(projects), featured)
+		}
+		if len(pinned) > 0 {
+			log.Printf("[%s] Keeping %d projects featured in the CMS", t.Name, len(pinned))
+		}
+	}
+	if opts.reconcile {
+		log.Printf("[%s] Reconciled featured flags left by an older version: %d changed", t.Name, heuristic.FeaturedChanges(result.Projects, projects))
+	} else {
+		log.Printf("[%s] Featured flags changed: %d", t.Name, heuristic.FeaturedChanges(result.Projects, projects))
+	}
+
+	// Locked fields keep whatever this space currently holds
+	projects = transform.RestoreLocked(projects, result.Projects, s.cfg.LockedFields)
+	projects = transform.KeepLiveURLs(projects, result.Projects, opts.emptyLiveURL)
+
+	if s.cfg.ManualOrder {
+		projects = heuristic.PreserveOrder(projects, result.Projects)
+	}
+
+	// Grouped after the lock restore so groups match the final technologies
+	transform.GroupTechnologies(projects, s.cfg.TechGroups)
+
+	if s.cfg.ContentHash {
+		for i := range projects {
+			projects[i].ContentHash = projects[i].ComputeContentHash()
+		}
+	}
+
+	stats.Diff = contentful.DiffProjects(result.Projects, projects)
+	stats.Churn = heuristic.OrderChurn(result.Projects, projects)
+	stats.Projects = projects
+	stats.NewAdded = len(stats.Diff.Added)
+	stats.Total = len(projects)
+
+	if opts.dryRun {
+		return stats
+	}
+
+	var locales []string
+	if opts.translations != nil {
+		locales = s.cfg.Locales
+	}
+	// With a watermark field an unchanged run still writes, but only the
+	// watermark: the current content goes back as it is
+	watermarkOnly := false
+	if !s.cfg.ForceUpdate && contentful.ProjectsEqual(result.Projects, projects) && hasLocales(result, locales) {
+		if s.cfg.WatermarkField == "" {
+			log.Printf("[%s] Content unchanged, skipping update and publish", t.Name)
+			stats.Unchanged = true
+			return stats
+		}
+		log.Printf("[%s] Content unchanged, updating the sync watermark", t.Name)
+		watermarkOnly = true
+	}
+
+	if s.cfg.BackupDir != "" {
+		path, err := backup.Write(s.cfg.BackupDir, t.Name, result.Projects)
+		if err != nil {
+			stats.Err = fmt.Errorf("backup: %w", err)
+			return stats
+		}
+		log.Printf("[%s] Backed up %d current projects to %s", t.Name, len(result.Projects), path)
+	}
 
 	// 7. Update Contentful
-	log.Println("Updating projects in Contentful...")
-	newVersion, err := s.cma.UpdateProjects(ctx, result, projects)
+	log.Printf("[%s] Updating projects in Contentful...", t.Name)
+	var newVersion int
+	switch {
+	case watermarkOnly:
+		newVersion, err = t.CMA.UpdateLocalizedProjects(ctx, result, result.Localized)
+	case opts.translations != nil:
+		newVersion, err = t.CMA.UpdateLocalizedProjects(ctx, result, s.localize(projects, result, opts.translations(), opts.degraded))
+	default:
+		newVersion, err = t.CMA.UpdateProjects(ctx, result, projects)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("update projects: %w", err)
+		stats.Err = fmt.Errorf("update projects: %w", err)
+		return stats
+	}
+	if watermarkOnly && s.cfg.WatermarkOnlyNoPublish {
+		log.Printf("[%s] Only the watermark changed, saved without publishing", t.Name)
+		stats.Unchanged = true
+		return stats
 	}
 
 	// 8. Publish (use the real entry ID from Contentful, not the config value)
-	if err := s.cma.PublishEntry(ctx, result.EntryID, newVersion); err != nil {
-		return nil, fmt.Errorf("publish: %w", err)
+	if opts.degraded && !s.cfg.PublishOnPartial {
+		log.Printf("[%s] Degraded run, saved without publishing", t.Name)
+		return stats
+	}
+	t.CMA.WaitBeforePublish(ctx, s.cfg.PublishDelay)
+	if err := t.CMA.PublishEntry(ctx, result.EntryID, newVersion); err != nil {
+		stats.Err = fmt.Errorf("publish: %w", err)
+		return stats
 	}
 
-	log.Println("Successfully synced and published.")
+	s.emit(Event{Kind: EventPublished, Target: t.Name, Count: len(projects)})
+	return stats
+}
 
-	return &SyncStats{
-		NewAdded: len(projects) - len(result.Projects),
-		Total:    len(projects),
-		Status:   "success",
-	}, nil
+// translate asks Gemini for every configured locale's text of projects. A
+// locale that fails is logged and left empty, so it falls back to the
+// default locale's text.
+func (s *Syncer) translate(ctx context.Context, projects []contentful.Project) map[string]map[string]enricher.Translation {
+	opts := enricher.Options{
+		APIKey:     s.cfg.GeminiAPIKey,
+		Generator:  s.generator,
+		BaseURL:    s.cfg.GeminiBaseURL,
+		Model:      s.cfg.GeminiModel,
+		Seed:       s.cfg.RandomSeed,
+		HTTPClient: s.http,
+		RetryOn:    s.cfg.GeminiRetryOn,
+		BatchSize:  s.cfg.GeminiBatchSize,
+		OnBatch:    func(n int) { s.emit(Event{Kind: EventEnrichBatchSent, Count: n}) },
+	}
+
+	translations := make(map[string]map[string]enricher.Translation, len(s.cfg.Locales))
+	for _, locale := range s.cfg.Locales {
+		log.Printf("Translating %d projects to %s...", len(projects), locale)
+		t, err := enricher.Translate(ctx, opts, projects, locale)
+		if err != nil {
+			log.Printf("WARNING: translate to %s failed, using %s text: %v", locale, contentful.DefaultLocale, err)
+			t = nil
+		} else if missing := len(projects) - len(t); missing > 0 {
+			log.Printf("WARNING: %d projects missing from the %s translation, using %s text", missing, locale, contentful.DefaultLocale)
+		}
+		translations[locale] = t
+	}
+	return translations
+}
+
+// localize builds the project list for the default locale and each
+// translated one. Locked fields are restored per locale from that locale's
+// current content. In a degraded run, projects without a translation keep
+// that locale's current text, since the CMS copies merged back in were not
+// translated this run.
+func (s *Syncer) localize(projects []contentful.Project, result *contentful.ProjectsResult, translations map[string]map[string]enricher.Translation, degraded bool) map[string][]contentful.Project {
+	byLocale := map[string][]contentful.Project{contentful.DefaultLocale: projects}
+	for locale, bySlug := range translations {
+		var current map[string]contentful.Project
+		if degraded {
+			current = make(map[string]contentful.Project, len(result.Localized[locale]))
+			for _, p := range result.Localized[locale] {
+				current[p.Slug] = p
+			}
+		}
+		localized := make([]contentful.Project, len(projects))
+		for i, p := range projects {
+			if t, ok := bySlug[p.Slug]; ok {
+				p = t.Apply(p)
+			} else if c, ok := current[p.Slug]; ok {
+				p = enricher.TranslationOf(c).Apply(p)
+			}
+			localized[i] = p
+		}
+		if s.cfg.StripMarkdown {
+			localized = transform.StripMarkdown(localized)
+		}
+		byLocale[locale] = transform.RestoreLocked(localized, result.Localized[locale], s.cfg.LockedFields)
+	}
+	return byLocale
+}
+
+// hasLocales reports whether result already holds content for every
+// locale, so an unchanged default locale means nothing to write.
+func hasLocales(result *contentful.ProjectsResult, locales []string) bool {
+	for _, locale := range locales {
+		if _, ok := result.Localized[locale]; !ok {
+			return false
+		}
+	}
+	return true
 }
 
-func (s *Syncer) fetchDetails(ctx context.Context, repos []githubapi.Repo) ([]mapper.RawProject, error) {
+// missingSlugs returns the slugs of raw projects that have no enriched
+// project, in input order.
+func missingSlugs(raw []mapper.RawProject, enriched []contentful.Project) []string {
+	have := make(map[string]bool, len(enriched))
+	for _, p := range enriched {
+		have[p.Slug] = true
+	}
+	var missing []string
+	for _, r := range raw {
+		if !have[r.Slug] {
+			missing = append(missing, r.Slug)
+		}
+	}
+	return missing
+}
+
+// mergeInto returns existing with each project replaced by the update of the
+// same slug, followed by updates not already present. Nothing is removed.
+func mergeInto(existing, updates []contentful.Project) []contentful.Project {
+	bySlug := make(map[string]contentful.Project, len(updates))
+	for _, p := range updates {
+		bySlug[p.Slug] = p
+	}
+	merged := make([]contentful.Project, 0, len(existing)+len(updates))
+	seen := make(map[string]bool, len(existing))
+	for _, p := range existing {
+		if u, ok := bySlug[p.Slug]; ok {
+			p = u
+		}
+		seen[p.Slug] = true
+		merged = append(merged, p)
+	}
+	for _, p := range updates {
+		if !seen[p.Slug] {
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
+
+// ConfigHash fingerprints the configuration, Gemini model and prompt that
+// shape the synced content, so a run can tell whether the last sync used the
+// same settings. Credentials and per-run flags are left out.
+func ConfigHash(cfg *config.Config) string {
+	c := *cfg
+	c.GitHubToken, c.CMAToken, c.GeminiAPIKey = "", "", ""
+	c.Targets = make([]config.Target, len(cfg.Targets))
+	for i, t := range cfg.Targets {
+		t.CMAToken = ""
+		c.Targets[i] = t
+	}
+	c.ForceUpdate, c.ForceEnrich, c.FillGaps, c.SkipUnchanged = false, false, false, false
+	c.ClearConcurrency()
+	c.HTTPTimeout, c.ReadmeTimeout, c.PublishDelay, c.PublishRPS = 0, 0, 0, 0
+	c.BuildLogKeep, c.BackupDir, c.EnrichCachePath, c.SQLitePath = 0, "", "", ""
+
+	// %+v would print the pointer, not the seed
+	seed := "none"
+	if cfg.RandomSeed != nil {
+		seed = fmt.Sprint(*cfg.RandomSeed)
+	}
+	c.RandomSeed = nil
+
+	system, _ := enricher.Prompts(nil)
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%+v\x00%s\x00%s", c, seed, system)))
+	return hex.EncodeToString(sum[:])
+}
+
+// featuredReconciled reports whether a run of this service already wrote
+// featured flags under the current FeaturedScheme.
+func (s *Syncer) featuredReconciled(ctx context.Context) (bool, error) {
+	scheme, err := s.recordedScheme(ctx)
+	if err != nil {
+		return false, err
+	}
+	return scheme >= FeaturedScheme, nil
+}
+
+// recordedScheme returns the newest FeaturedScheme any entry of this service
+// recorded in the build log, whatever its status, or 0 when none did.
+func (s *Syncer) recordedScheme(ctx context.Context) (int, error) {
+	buildLog, err := s.targets[0].CMA.GetBuildLog(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get build log: %w", err)
+	}
+	scheme := 0
+	for _, e := range buildLog.Entries {
+		if e.Service == ServiceName {
+			scheme = max(scheme, e.FeaturedScheme)
+		}
+	}
+	return scheme, nil
+}
+
+// carriedScheme returns the FeaturedScheme a run that writes nothing records:
+// the CMS still holds the flags an earlier run wrote, so its scheme carries
+// forward and survives build-log pruning.
+func (s *Syncer) carriedScheme(ctx context.Context) int {
+	scheme, err := s.recordedScheme(ctx)
+	if err != nil {
+		log.Printf("WARNING: featured scheme not carried forward: %v", err)
+	}
+	return scheme
+}
+
+// unchangedSinceLastSync reports whether no filtered repo was pushed after
+// this service's last successful or unchanged build-log entry, that entry
+// was written with the current ConfigHash, and the repos that would be
+// selected still match the slugs in the first target's CMS content. An
+// unchanged run confirmed the CMS was current, so it moves the watermark
+// like a full sync and keeps early exits going past BUILD_LOG_KEEP.
+func (s *Syncer) unchangedSinceLastSync(ctx context.Context, repos []github.Repo) (bool, error) {
+	primary := s.targets[0]
+
+	buildLog, err := primary.CMA.GetBuildLog(ctx)
+	if err != nil {
+		return false, fmt.Errorf("get build log: %w", err)
+	}
+	var (
+		watermark time.Time
+		lastHash  string
+	)
+	for _, e := range buildLog.Entries {
+		if e.Service != ServiceName || (e.Status != "success" && e.Status != "no-changes") {
+			continue
+		}
+		if ts, err := time.Parse(time.RFC3339, e.Timestamp); err == nil && ts.After(watermark) {
+			watermark = ts
+			lastHash = e.ConfigHash
+		}
+	}
+	if watermark.IsZero() {
+		return false, nil
+	}
+	if lastHash != ConfigHash(s.cfg) {
+		log.Println("Configuration changed since the last successful sync")
+		return false, nil
+	}
+
+	for _, r := range repos {
+		if r.PushedAt.After(watermark) {
+			return false, nil
+		}
+	}
+
+	entryID, err := primary.CMA.ResolveEntryID(ctx, primary.EntryID, primary.SectionID)
+	if err != nil {
+		return false, err
+	}
+	result, err := primary.CMA.GetProjects(ctx, entryID)
+	if err != nil {
+		return false, fmt.Errorf("get projects: %w", err)
+	}
+	current := make(map[string]bool, len(result.Projects))
+	for _, p := range result.Projects {
+		current[p.Slug] = true
+	}
+
+	candidates := make([]github.Repo, len(repos))
+	copy(candidates, repos)
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].PushedAt.After(candidates[j].PushedAt)
+	})
+	if len(candidates) > s.cfg.MaxProjects {
+		candidates = candidates[:s.cfg.MaxProjects]
+	}
+	if len(candidates) != len(current) {
+		return false, nil
+	}
+	for _, r := range candidates {
+		if !current[r.Name] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// fetchREADME downloads a repo's README, capped at ReadmeMaxBytes and bounded
+// by ReadmeTimeout so one outlier cannot hold up the details stage. The
+// ReadmeCandidates paths are tried in order first; when none exists the
+// README GitHub detects is used.
+func (s *Syncer) fetchREADME(ctx context.Context, repo string) (string, error) {
+	if s.cfg.ReadmeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.ReadmeTimeout)
+		defer cancel()
+	}
+	for _, path := range s.cfg.ReadmeCandidates {
+		content, found, err := s.github.GetFile(ctx, s.cfg.GitHubUsername, repo, path, s.cfg.ReadmeMaxBytes)
+		if err != nil {
+			return "", err
+		}
+		if found {
+			return content, nil
+		}
+	}
+	return s.github.GetREADME(ctx, s.cfg.GitHubUsername, repo, s.cfg.ReadmeMaxBytes)
+}
+
+// fetchRepoConfig reads the repo's RepoConfigFile. A missing or invalid
+// file yields nil, which shows every section and expands nothing.
+func (s *Syncer) fetchRepoConfig(ctx context.Context, repo string) *mapper.RepoConfig {
+	content, found, err := s.github.GetFile(ctx, s.cfg.GitHubUsername, repo, s.cfg.RepoConfigFile, repoConfigMaxBytes)
+	if err != nil {
+		log.Printf("WARNING: %s failed for %s: %v", s.cfg.RepoConfigFile, repo, err)
+		return nil
+	}
+	if !found {
+		return nil
+	}
+	repoCfg, err := mapper.ParseRepoConfig(content)
+	if err != nil {
+		log.Printf("WARNING: %s of %s: %v", s.cfg.RepoConfigFile, repo, err)
+		return nil
+	}
+	return &repoCfg
+}
+
+// expandSubprojects turns a monorepo's raw project into one raw project per
+// configured sub-project, each with the README found in its directory. A
+// sub-project without a README is enriched from its description alone.
+func (s *Syncer) expandSubprojects(ctx context.Context, parent mapper.RawProject, subs []mapper.Subproject) []mapper.RawProject {
+	projects := make([]mapper.RawProject, 0, len(subs))
+	for _, sub := range subs {
+		readme, _, err := s.github.GetFile(ctx, s.cfg.GitHubUsername, parent.Name, sub.Path+"/README.md", s.cfg.ReadmeMaxBytes)
+		if err != nil {
+			log.Printf("WARNING: readme failed for %s/%s: %v", parent.Name, sub.Path, err)
+		}
+		raw := mapper.SubRawProject(parent, sub, cleanREADME(parent.Name+"/"+sub.Path, readme))
+		raw.Overview = mapper.ExtractSection(raw.ReadmeRaw, s.cfg.ReadmeSection)
+		projects = append(projects, raw)
+	}
+	return projects
+}
+
+// cleanREADME decodes a downloaded README to UTF-8 text, dropping it when it
+// looks binary.
+func cleanREADME(name, readme string) string {
+	readme = mapper.DecodeREADME(readme)
+	if text, ok := mapper.ToUTF8(readme); ok {
+		return text
+	}
+	log.Printf("WARNING: README of %s looks binary, ignoring it", name)
+	return ""
+}
+
+// draftCandidate reports whether r was created less than maxAge before now.
+// Only such repos are checked for tags; a zero maxAge disables drafts.
+func draftCandidate(r github.Repo, maxAge time.Duration, now time.Time) bool {
+	return maxAge > 0 && now.Sub(r.CreatedAt) < maxAge
+}
+
+// fetchDetails fetches languages, README and optional extras for each repo,
+// returning projects in the order of repos. A repo whose languages or README
+// fail is still returned with what was available and counted in failed; when
+// more than maxFetchFailureRatio of the repos fail, the errors are returned
+// together instead, since that points to a systemic problem such as a bad
+// token or rate limiting.
+func (s *Syncer) fetchDetails(ctx context.Context, repos []github.Repo) (rawProjects []mapper.RawProject, failed int, err error) {
 	var (
-		mu          sync.Mutex
-		wg          sync.WaitGroup
-		sem         = make(chan struct{}, 5)
-		rawProjects []mapper.RawProject
-		firstErr    error
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, s.cfg.FetchConcurrency)
+		errs []error
 	)
+	fail := func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
 
-	for _, repo := range repos {
+	// Indexed by repo so the result keeps the input order whatever order
+	// the fetches finish in; chunking and prompts depend on it
+	byRepo := make([][]mapper.RawProject, len(repos))
+	for i, repo := range repos {
 		wg.Add(1)
-		go func(r githubapi.Repo) {
+		go func(i int, r github.Repo) {
 			defer wg.Done()
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
+			var repoErrs []error
 			languages, err := s.github.GetRepoLanguages(ctx, s.cfg.GitHubUsername, r.Name)
 			if err != nil {
 				log.Printf("WARNING: languages failed for %s: %v", r.Name, err)
+				repoErrs = append(repoErrs, fmt.Errorf("languages: %w", err))
 				languages = map[string]int{}
+			} else if len(languages) == 0 && s.cfg.ExcludeLanguageless {
+				log.Printf("  Skipping %s: no detected languages", r.Name)
+				return
 			}
 
-			readme, err := s.github.GetRepoREADME(ctx, s.cfg.GitHubUsername, r.Name)
+			readme, err := s.fetchREADME(ctx, r.Name)
 			if err != nil {
 				log.Printf("WARNING: readme failed for %s: %v", r.Name, err)
+				repoErrs = append(repoErrs, fmt.Errorf("readme: %w", err))
+			}
+			if len(repoErrs) > 0 {
+				fail(fmt.Errorf("%s: %w", r.Name, errors.Join(repoErrs...)))
 			}
+			readme = cleanREADME(r.Name, readme)
 
 			raw := mapper.ToRawProject(r, languages, readme)
+			raw.Overview = mapper.ExtractSection(raw.ReadmeRaw, s.cfg.ReadmeSection)
+			mapper.ApplyDescriptionTags(&raw, s.cfg.DescriptionTagRe)
+			if len(raw.Languages) == 0 {
+				if raw.CategoryOverride == "" {
+					raw.CategoryOverride = s.cfg.LanguagelessCategory
+				}
+				if s.cfg.LanguagelessTechnology != "" {
+					raw.TechHints = append(raw.TechHints, s.cfg.LanguagelessTechnology)
+				}
+			}
 
-			mu.Lock()
-			rawProjects = append(rawProjects, raw)
-			mu.Unlock()
-		}(repo)
+			var repoCfg *mapper.RepoConfig
+			if s.cfg.RepoConfigFile != "" {
+				repoCfg = s.fetchRepoConfig(ctx, r.Name)
+			}
+			if repoCfg != nil {
+				raw.Display = &repoCfg.Display
+			}
+
+			// Only recent repos can be drafts, so older ones skip the tags lookup
+			if draftCandidate(r, s.cfg.DraftMaxAge, time.Now()) {
+				hasTags, err := s.github.HasTags(ctx, s.cfg.GitHubUsername, r.Name)
+				if err != nil {
+					log.Printf("WARNING: tags failed for %s: %v", r.Name, err)
+				} else {
+					raw.Draft = !hasTags
+				}
+			}
+
+			if s.cfg.CaptureSourceCommit {
+				sha, err := s.github.GetHeadSHA(ctx, s.cfg.GitHubUsername, r.Name)
+				if err != nil {
+					log.Printf("WARNING: head commit failed for %s: %v", r.Name, err)
+				}
+				raw.SourceCommit = sha
+			}
+
+			expanded := []mapper.RawProject{raw}
+			if repoCfg != nil && len(repoCfg.Subprojects) > 0 {
+				expanded = s.expandSubprojects(ctx, raw, repoCfg.Subprojects)
+				log.Printf("  Expanded %s into %d sub-projects", r.Name, len(expanded))
+			}
+
+			byRepo[i] = expanded
+			s.emit(Event{Kind: EventRepoFetched, Repo: r.Name})
+		}(i, repo)
 	}
 
 	wg.Wait()
-
-	if firstErr != nil {
-		return nil, firstErr
+	for _, expanded := range byRepo {
+		rawProjects = append(rawProjects, expanded...)
 	}
 
-	return rawProjects, nil
+	if len(errs) > 0 && float64(len(errs)) > maxFetchFailureRatio*float64(len(repos)) {
+		return nil, len(errs), fmt.Errorf("%d of %d repos failed: %w", len(errs), len(repos), errors.Join(errs...))
+	}
+	return rawProjects, len(errs), nil
 }
@@ -0,0 +1,1529 @@
+package syncer
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/backup"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/config"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/github"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/mapper"
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/transform"
+	githubapi "github.com/alberto-moreno-sa/go-service-kit/github"
+)
+
+func TestDraftCandidate(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+
+	tests := []struct {
+		name    string
+		created time.Time
+		maxAge  time.Duration
+		want    bool
+	}{
+		{"disabled", now.Add(-time.Hour), 0, false},
+		{"inside window", now.Add(-6 * 24 * time.Hour), week, true},
+		{"at the boundary", now.Add(-week), week, false},
+		{"older than window", now.Add(-30 * 24 * time.Hour), week, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := github.Repo{Repo: githubapi.Repo{Name: "app"}, CreatedAt: tt.created}
+			if got := draftCandidate(r, tt.maxAge, now); got != tt.want {
+				t.Errorf("draftCandidate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunSkipUnchanged(t *testing.T) {
+	cfg := testConfig()
+	cfg.SkipUnchanged = true
+	lastSync := time.Now().Add(-24 * time.Hour)
+	current := []contentful.Project{{Slug: "api"}, {Slug: "cli"}}
+
+	changedCfg := *cfg
+	changedCfg.MaxFeatured = 1
+
+	tests := []struct {
+		name       string
+		repos      []github.Repo
+		entry      contentful.BuildLogEntry
+		dryRun     bool
+		wantStatus string
+	}{
+		{"nothing pushed, same config", []github.Repo{testRepo("api", 3), testRepo("cli", 5)}, successEntry(lastSync, ConfigHash(cfg)), false, "no-changes"},
+		{"dry run ignores it", []github.Repo{testRepo("api", 3), testRepo("cli", 5)}, successEntry(lastSync, ConfigHash(cfg)), true, "stopped"},
+		{"repo pushed since", []github.Repo{testRepo("api", 0), testRepo("cli", 5)}, successEntry(lastSync, ConfigHash(cfg)), false, "stopped"},
+		{"config changed", []github.Repo{testRepo("api", 3), testRepo("cli", 5)}, successEntry(lastSync, ConfigHash(&changedCfg)), false, "stopped"},
+		{"unchanged entry", []github.Repo{testRepo("api", 3), testRepo("cli", 5)}, unchangedEntry(lastSync, ConfigHash(cfg)), false, "no-changes"},
+		{"unchanged entry, repo pushed since", []github.Repo{testRepo("api", 0), testRepo("cli", 5)}, unchangedEntry(lastSync, ConfigHash(cfg)), false, "stopped"},
+		{"degraded entry", []github.Repo{testRepo("api", 3), testRepo("cli", 5)}, degradedEntry(lastSync, ConfigHash(cfg)), false, "stopped"},
+		{"entry without a config hash", []github.Repo{testRepo("api", 3), testRepo("cli", 5)}, successEntry(lastSync, ""), false, "stopped"},
+		{"new repo", []github.Repo{testRepo("api", 3), testRepo("cli", 5), testRepo("web", 9)}, successEntry(lastSync, ConfigHash(cfg)), false, "stopped"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gh := &fakeGitHub{repos: tt.repos}
+			cma := &fakeCMA{projects: current, buildLog: []contentful.BuildLogEntry{tt.entry}}
+			s := New(cfg, gh, []Target{{Name: "main", CMA: cma, EntryID: "projects"}}, nil)
+
+			stats, err := s.Run(context.Background(), RunOptions{DryRun: tt.dryRun, StopAfter: StageDetails})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", stats.Status, tt.wantStatus)
+			}
+			if fetched := gh.fetchCount() > 0; fetched != (tt.wantStatus != "no-changes") {
+				t.Errorf("details fetched = %v for status %q", fetched, stats.Status)
+			}
+		})
+	}
+}
+
+func TestRunSkipUnchangedPastBuildLogKeep(t *testing.T) {
+	const keep = 3
+	repos := []github.Repo{testRepo("api", 3), testRepo("cli", 5)}
+	cfg := testConfig()
+	cfg.SkipUnchanged = true
+	gen := &fakeGenerator{}
+	cma := &fakeCMA{version: 1}
+	s, gh := newTestSyncer(cfg, repos, gen, cma)
+
+	first, err := s.Run(context.Background(), RunOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cma.recordRun(cfg, first, keep)
+	fetched, requests := gh.fetchCount(), gen.requestCount()
+
+	// Past keep runs the full sync's entry is pruned; the unchanged ones
+	// must carry the watermark on their own
+	for i := 0; i < keep+2; i++ {
+		stats, err := s.Run(context.Background(), RunOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Status != "no-changes" {
+			t.Fatalf("run %d: status %q, want no-changes", i+1, stats.Status)
+		}
+		cma.recordRun(cfg, stats, keep)
+	}
+	if got := gh.fetchCount(); got != fetched {
+		t.Errorf("unchanged runs fetched details %d times", got-fetched)
+	}
+	if got := gen.requestCount(); got != requests {
+		t.Errorf("unchanged runs made %d Gemini requests", got-requests)
+	}
+}
+
+// newTestSyncer wires s to a fake GitHub serving repos, each with a short
+// README, and to one fake CMA target per entry of cmas.
+func newTestSyncer(cfg *config.Config, repos []github.Repo, gen *fakeGenerator, cmas ...*fakeCMA) (*Syncer, *fakeGitHub) {
+	gh := &fakeGitHub{repos: repos, readmes: map[string]string{}}
+	for _, r := range repos {
+		gh.readmes[r.Name] = "# " + r.Name + "\n\nA small tool.\n"
+	}
+	var targets []Target
+	for i, cma := range cmas {
+		targets = append(targets, Target{Name: fmt.Sprintf("target-%d", i), CMA: cma, EntryID: "projects"})
+	}
+	s := New(cfg, gh, targets, nil)
+	s.SetGenerator(gen)
+	return s, gh
+}
+
+func projectSlugs(projects []contentful.Project) []string {
+	var out []string
+	for _, p := range projects {
+		out = append(out, p.Slug)
+	}
+	return out
+}
+
+func TestRun(t *testing.T) {
+	fork := testRepo("forked", 0)
+	fork.Fork = true
+	repos := []github.Repo{testRepo("api", 1), testRepo("cli", 2), testRepo("web", 3), fork}
+
+	tests := []struct {
+		name      string
+		configure func(*config.Config)
+		existing  []contentful.Project
+		fail      map[string]bool
+		dryRun    bool
+
+		wantStatus    string
+		wantSlugs     []string
+		wantFeatured  int
+		wantAdded     int
+		wantPruned    int
+		wantPublished bool
+	}{
+		{
+			name:          "fresh entry",
+			wantStatus:    "success",
+			wantSlugs:     []string{"api", "cli", "web"},
+			wantFeatured:  2,
+			wantAdded:     3,
+			wantPublished: true,
+		},
+		{
+			name:          "max projects cap",
+			configure:     func(c *config.Config) { c.MaxProjects = 2; c.MaxFeatured = 1 },
+			wantStatus:    "success",
+			wantSlugs:     []string{"api", "cli"},
+			wantFeatured:  1,
+			wantAdded:     2,
+			wantPublished: true,
+		},
+		{
+			name:         "dry run writes nothing",
+			dryRun:       true,
+			wantStatus:   "dry-run",
+			wantSlugs:    []string{"api", "cli", "web"},
+			wantFeatured: 2,
+			wantAdded:    3,
+		},
+		{
+			name:          "orphaned project replaced",
+			existing:      []contentful.Project{{Slug: "gone", Name: "Gone"}},
+			wantStatus:    "success",
+			wantSlugs:     []string{"api", "cli", "web"},
+			wantFeatured:  2,
+			wantAdded:     3,
+			wantPublished: true,
+		},
+		{
+			name:         "failed enrichment keeps the CMS copy unpublished",
+			existing:     []contentful.Project{{Slug: "web", Name: "Web (CMS)"}},
+			fail:         map[string]bool{"web": true},
+			wantStatus:   "degraded",
+			wantSlugs:    []string{"api", "cli", "web"},
+			wantFeatured: 2,
+			wantAdded:    2,
+		},
+		{
+			name:      "degraded merge keeps caps and order",
+			configure: func(c *config.Config) { c.MaxProjects = 3; c.MaxFeatured = 1 },
+			existing: []contentful.Project{
+				{Slug: "old", Name: "Old", Featured: true},
+				{Slug: "web", Name: "Web (CMS)", Featured: true},
+				{Slug: "older", Name: "Older"},
+			},
+			fail:         map[string]bool{"web": true},
+			wantStatus:   "degraded",
+			wantSlugs:    []string{"api", "cli", "web"},
+			wantFeatured: 1,
+			wantAdded:    2,
+		},
+		{
+			name:      "degraded merge prunes orphans",
+			configure: func(c *config.Config) { c.PruneOrphans = true },
+			existing: []contentful.Project{
+				{Slug: "gone", Name: "Gone"},
+				{Slug: "web", Name: "Web (CMS)"},
+			},
+			fail:         map[string]bool{"web": true},
+			wantStatus:   "degraded",
+			wantSlugs:    []string{"api", "cli", "web"},
+			wantFeatured: 2,
+			wantAdded:    2,
+			wantPruned:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			if tt.configure != nil {
+				tt.configure(cfg)
+			}
+			cma := &fakeCMA{projects: tt.existing, version: 1}
+			s, gh := newTestSyncer(cfg, repos, &fakeGenerator{fail: tt.fail}, cma)
+
+			stats, err := s.Run(context.Background(), RunOptions{DryRun: tt.dryRun})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", stats.Status, tt.wantStatus)
+			}
+			if gh.fetched["forked"] > 0 {
+				t.Error("forked repo was fetched")
+			}
+
+			written := stats.Targets[0].Projects
+			if got := projectSlugs(written); !reflect.DeepEqual(got, tt.wantSlugs) {
+				t.Errorf("slugs = %v, want %v", got, tt.wantSlugs)
+			}
+			featured := 0
+			for _, p := range written {
+				if p.Featured {
+					featured++
+				}
+			}
+			if featured != tt.wantFeatured {
+				t.Errorf("featured = %d, want %d", featured, tt.wantFeatured)
+			}
+			if stats.NewAdded != tt.wantAdded {
+				t.Errorf("NewAdded = %d, want %d", stats.NewAdded, tt.wantAdded)
+			}
+			if stats.Pruned != tt.wantPruned {
+				t.Errorf("Pruned = %d, want %d", stats.Pruned, tt.wantPruned)
+			}
+
+			if tt.dryRun && len(cma.updates) > 0 {
+				t.Error("dry run updated the entry")
+			}
+			if published := len(cma.published) > 0; published != tt.wantPublished {
+				t.Errorf("published = %v, want %v", published, tt.wantPublished)
+			}
+		})
+	}
+}
+
+func TestRunEvents(t *testing.T) {
+	head := []Event{
+		{Kind: EventStageStarted, Stage: StageListRepos},
+		{Kind: EventStageStarted, Stage: StageDetails},
+		{Kind: EventRepoFetched, Repo: "api"},
+		{Kind: EventRepoFetched, Repo: "cli"},
+		{Kind: EventStageStarted, Stage: StageEnrich},
+		{Kind: EventEnrichBatchSent, Count: 2},
+		{Kind: EventStageStarted, Stage: StageHeuristic},
+		{Kind: EventStageStarted, Stage: StageWrite},
+	}
+	tests := []struct {
+		name   string
+		dryRun bool
+		want   []Event
+	}{
+		{name: "happy path", want: append(slices.Clone(head), Event{Kind: EventPublished, Target: "target-0", Count: 2})},
+		{name: "dry run publishes nothing", dryRun: true, want: head},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestSyncer(testConfig(), []github.Repo{testRepo("api", 1), testRepo("cli", 2)}, &fakeGenerator{}, &fakeCMA{version: 1})
+
+			var mu sync.Mutex
+			var events []Event
+			onEvent := func(e Event) {
+				mu.Lock()
+				events = append(events, e)
+				mu.Unlock()
+			}
+			if _, err := s.Run(context.Background(), RunOptions{DryRun: tt.dryRun, OnEvent: onEvent}); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(events) < 4 {
+				t.Fatalf("events = %+v, want at least the fetch stage", events)
+			}
+			// Details are fetched concurrently, so repo events may come in
+			// any order within their stage.
+			fetched := events[2:4]
+			slices.SortFunc(fetched, func(a, b Event) int { return strings.Compare(a.Repo, b.Repo) })
+			if !reflect.DeepEqual(events, tt.want) {
+				t.Errorf("events =\n%+v\nwant\n%+v", events, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunDisplayDatesKeepRanking(t *testing.T) {
+	repos := []github.Repo{testRepo("new", 1), testRepo("mid", 5), testRepo("old", 30)}
+	tests := []struct {
+		name      string
+		overrides map[string]string
+	}{
+		{name: "no overrides"},
+		{name: "old project shown as newest", overrides: map[string]string{"old": time.Now().AddDate(1, 0, 0).Format("2006-01-02")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.DisplayDates = tt.overrides
+			cma := &fakeCMA{version: 1}
+			s, _ := newTestSyncer(cfg, repos, &fakeGenerator{}, cma)
+
+			stats, err := s.Run(context.Background(), RunOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			written := stats.Targets[0].Projects
+			if got := projectSlugs(written); !reflect.DeepEqual(got, []string{"new", "mid", "old"}) {
+				t.Errorf("order = %v, want ranking by push date", got)
+			}
+			for _, p := range written {
+				if p.Featured != (p.Slug != "old") {
+					t.Errorf("%s featured = %v", p.Slug, p.Featured)
+				}
+				want := p.PushedAt.UTC().Format("2006-01-02")
+				if date, ok := tt.overrides[p.Slug]; ok {
+					want = date
+				}
+				if p.DisplayDate != want {
+					t.Errorf("%s displayDate = %q, want %q", p.Slug, p.DisplayDate, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPreview(t *testing.T) {
+	fork := testRepo("forked", 0)
+	fork.Fork = true
+	repos := []github.Repo{testRepo("api", 1), testRepo("cli", 2), testRepo("web", 3), testRepo("old", 30), fork}
+	current := []contentful.Project{{Slug: "api"}, {Slug: "gone"}}
+
+	tests := []struct {
+		name     string
+		priority []string
+		want     []PreviewRow
+	}{
+		{
+			name: "ranked by recency",
+			want: []PreviewRow{
+				{Slug: "old", Status: "dropped"},
+				{Slug: "api", Status: "kept"},
+				{Slug: "cli", Status: "new"},
+				{Slug: "web", Status: "new"},
+				{Slug: "gone", Status: "orphaned"},
+			},
+		},
+		{
+			name:     "priority keeps an old repo",
+			priority: []string{"old"},
+			want: []PreviewRow{
+				{Slug: "web", Status: "dropped"},
+				{Slug: "api", Status: "kept"},
+				{Slug: "cli", Status: "new"},
+				{Slug: "old", Status: "new"},
+				{Slug: "gone", Status: "orphaned"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.MaxProjects = 3
+			cfg.PriorityOrder = tt.priority
+			gen := &fakeGenerator{}
+			cma := &fakeCMA{projects: current, version: 1}
+			s, gh := newTestSyncer(cfg, repos, gen, cma)
+
+			rows, err := s.Preview(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("rows = %+v, want %+v", rows, tt.want)
+			}
+			if gen.requestCount() != 0 || len(cma.updates) != 0 || gh.fetchCount() != 0 {
+				t.Errorf("preview made %d Gemini requests, %d writes and %d detail fetches, want none", gen.requestCount(), len(cma.updates), gh.fetchCount())
+			}
+		})
+	}
+}
+
+func TestComputeStats(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 30, 0, 0, time.FixedZone("CEST", 2*3600))
+	tests := []struct {
+		name     string
+		projects []contentful.Project
+		raw      []mapper.RawProject
+		want     contentful.PortfolioStats
+	}{
+		{
+			name: "empty",
+			want: contentful.PortfolioStats{Languages: []string{}, LastSync: "2026-10-16T07:30:00Z"},
+		},
+		{
+			name: "technologies deduplicated case-insensitively",
+			projects: []contentful.Project{
+				{Slug: "api", Technologies: []string{"Go", "PostgreSQL"}},
+				{Slug: "web", Technologies: []string{"go", "React"}},
+			},
+			raw: []mapper.RawProject{
+				{Slug: "api", Languages: []string{"Go", "Shell"}},
+				{Slug: "web", Languages: []string{"TypeScript", "Shell"}},
+			},
+			want: contentful.PortfolioStats{
+				ProjectCount:    2,
+				TechnologyCount: 3,
+				LanguageCount:   3,
+				Languages:       []string{"Go", "Shell", "TypeScript"},
+				LastSync:        "2026-10-16T07:30:00Z",
+			},
+		},
+		{
+			name:     "languages only from final projects",
+			projects: []contentful.Project{{Slug: "api", Technologies: []string{"Go"}}},
+			raw: []mapper.RawProject{
+				{Slug: "api", Languages: []string{"Go"}},
+				{Slug: "capped", Languages: []string{"Rust"}},
+			},
+			want: contentful.PortfolioStats{
+				ProjectCount:    1,
+				TechnologyCount: 1,
+				LanguageCount:   1,
+				Languages:       []string{"Go"},
+				LastSync:        "2026-10-16T07:30:00Z",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeStats(tt.projects, tt.raw, now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("computeStats = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunWriteStats(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		dryRun    bool
+		wantStats bool
+	}{
+		{name: "disabled"},
+		{name: "enabled", enabled: true, wantStats: true},
+		{name: "dry run writes nothing", enabled: true, dryRun: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.WriteStatsEntry = tt.enabled
+			cfg.StatsSectionID = "stats"
+			cma := &fakeCMA{version: 1}
+			s, _ := newTestSyncer(cfg, []github.Repo{testRepo("api", 1), testRepo("cli", 2)}, &fakeGenerator{}, cma)
+
+			if _, err := s.Run(context.Background(), RunOptions{DryRun: tt.dryRun}); err != nil {
+				t.Fatal(err)
+			}
+			got, ok := cma.stats["stats"]
+			if ok != tt.wantStats {
+				t.Fatalf("stats written = %v, want %v", ok, tt.wantStats)
+			}
+			if ok && (got.ProjectCount != 2 || got.TechnologyCount != 1) {
+				t.Errorf("stats = %+v, want 2 projects and 1 technology", got)
+			}
+		})
+	}
+}
+
+func TestRunSections(t *testing.T) {
+	repos := []github.Repo{testRepo("orm", 1), testRepo("api", 2), testRepo("site", 3)}
+	gen := &fakeGenerator{categories: map[string]string{"orm": "Libraries", "site": "Web"}}
+	cfg := testConfig()
+	cfg.SectionRoutes = transform.SectionRoutes{"libraries": {"libraries", "backend"}, "backend": {"backend"}}
+	cfg.SectionFallback = "misc"
+	cma := &fakeCMA{version: 1}
+	s, _ := newTestSyncer(cfg, repos, gen, cma)
+
+	stats, err := s.Run(context.Background(), RunOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string][]string)
+	for id, projects := range cma.entries {
+		got[id] = projectSlugs(projects)
+	}
+	want := map[string][]string{
+		"section-libraries": {"orm"},
+		"section-backend":   {"orm", "api"},
+		"section-misc":      {"site"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sections = %v, want %v", got, want)
+	}
+	if len(stats.Sections) != 3 {
+		t.Errorf("got %d section stats, want 3", len(stats.Sections))
+	}
+	if got := projectSlugs(cma.projects); !reflect.DeepEqual(got, []string{"orm", "api", "site"}) {
+		t.Errorf("projects entry = %v, want every project", got)
+	}
+}
+
+func TestRunDisplayFromRepoConfig(t *testing.T) {
+	repos := []github.Repo{testRepo("api", 1), testRepo("cli", 2)}
+	cfg := testConfig()
+	cfg.RepoConfigFile = ".cms-sync.yml"
+	cma := &fakeCMA{version: 1}
+	s, gh := newTestSyncer(cfg, repos, &fakeGenerator{}, cma)
+	gh.files = map[string]string{"api/.cms-sync.yml": "showDemo: false\nshowHighlights: false\n"}
+
+	if _, err := s.Run(context.Background(), RunOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]contentful.Display{
+		"api": {ShowTechnologies: true},
+		"cli": {ShowDemo: true, ShowTechnologies: true, ShowHighlights: true},
+	}
+	for _, p := range cma.projects {
+		if p.Display == nil || *p.Display != want[p.Slug] {
+			t.Errorf("%s display = %+v, want %+v", p.Slug, p.Display, want[p.Slug])
+		}
+	}
+	if len(cma.projects) != len(want) {
+		t.Errorf("wrote %v, want %d projects", projectSlugs(cma.projects), len(want))
+	}
+}
+
+func TestRunStopAfter(t *testing.T) {
+	repos := []github.Repo{testRepo("api", 1), testRepo("cli", 2), testRepo("web", 3)}
+	tests := []struct {
+		name         string
+		stage        string
+		wantStatus   string
+		wantRaw      []string
+		wantProjects []string
+		wantFeatured int
+		wantGemini   bool
+		wantWrite    bool
+	}{
+		{name: "details", stage: StageDetails, wantStatus: "stopped", wantRaw: []string{"api", "cli", "web"}},
+		{name: "enrich", stage: StageEnrich, wantStatus: "stopped", wantProjects: []string{"api", "cli", "web"}, wantGemini: true},
+		{name: "heuristic", stage: StageHeuristic, wantStatus: "stopped", wantProjects: []string{"api", "cli"}, wantFeatured: 1, wantGemini: true},
+		{name: "write", wantStatus: "success", wantGemini: true, wantWrite: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.MaxProjects, cfg.MaxFeatured = 2, 1
+			gen := &fakeGenerator{}
+			cma := &fakeCMA{version: 1}
+			s, _ := newTestSyncer(cfg, repos, gen, cma)
+
+			stats, err := s.Run(context.Background(), RunOptions{StopAfter: tt.stage})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", stats.Status, tt.wantStatus)
+			}
+			var raw []string
+			for _, r := range stats.Raw {
+				raw = append(raw, r.Slug)
+			}
+			slices.Sort(raw)
+			if !reflect.DeepEqual(raw, tt.wantRaw) {
+				t.Errorf("Raw = %v, want %v", raw, tt.wantRaw)
+			}
+			if tt.stage != "" {
+				got := projectSlugs(stats.Projects)
+				if tt.stage == StageEnrich {
+					// Enriched projects are not ranked yet
+					slices.Sort(got)
+				}
+				if !reflect.DeepEqual(got, tt.wantProjects) {
+					t.Errorf("Projects = %v, want %v", got, tt.wantProjects)
+				}
+				featured := 0
+				for _, p := range stats.Projects {
+					if p.Featured {
+						featured++
+					}
+				}
+				if featured != tt.wantFeatured {
+					t.Errorf("featured = %d, want %d", featured, tt.wantFeatured)
+				}
+			}
+			if called := gen.requestCount() > 0; called != tt.wantGemini {
+				t.Errorf("Gemini called = %v, want %v", called, tt.wantGemini)
+			}
+			if wrote := len(cma.updates) > 0; wrote != tt.wantWrite {
+				t.Errorf("wrote = %v, want %v", wrote, tt.wantWrite)
+			}
+		})
+	}
+}
+
+func TestRunBackup(t *testing.T) {
+	repos := []github.Repo{testRepo("api", 1), testRepo("cli", 2)}
+	existing := []contentful.Project{{Slug: "old", Name: "Old"}}
+
+	tests := []struct {
+		name       string
+		backup     bool
+		dryRun     bool
+		updateErr  error
+		wantBackup bool
+	}{
+		{name: "no backup dir"},
+		{name: "backup before the write", backup: true, wantBackup: true},
+		{name: "backup kept when the write fails", backup: true, updateErr: errors.New("409 conflict"), wantBackup: true},
+		{name: "dry run writes no backup", backup: true, dryRun: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "backups")
+			cfg := testConfig()
+			if tt.backup {
+				cfg.BackupDir = dir
+			}
+			cma := &fakeCMA{projects: slices.Clone(existing), version: 1, updateErr: tt.updateErr}
+			var atUpdate []string
+			cma.beforeUpdate = func() {
+				atUpdate, _ = filepath.Glob(filepath.Join(dir, "backup-target-0-*.json"))
+			}
+			s, _ := newTestSyncer(cfg, repos, &fakeGenerator{}, cma)
+
+			s.Run(context.Background(), RunOptions{DryRun: tt.dryRun})
+
+			files, _ := filepath.Glob(filepath.Join(dir, "backup-target-0-*.json"))
+			if got := len(files) == 1; got != tt.wantBackup {
+				t.Fatalf("backup files = %v, want one: %v", files, tt.wantBackup)
+			}
+			if !tt.wantBackup {
+				return
+			}
+			if len(atUpdate) != 1 {
+				t.Errorf("backup files at the write = %v, want it written before", atUpdate)
+			}
+			saved, err := backup.Read(files[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(projectSlugs(saved), []string{"old"}) {
+				t.Errorf("backup = %v, want the content before the write", projectSlugs(saved))
+			}
+		})
+	}
+}
+
+func TestRunUnchangedSkipsWrite(t *testing.T) {
+	tests := []struct {
+		name          string
+		force         bool
+		wantStatus    string
+		wantUpdates   int
+		wantPublishes int
+	}{
+		{name: "unchanged skips update and publish", wantStatus: "no-changes", wantUpdates: 1, wantPublishes: 1},
+		{name: "force always writes", force: true, wantStatus: "success", wantUpdates: 2, wantPublishes: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cma := &fakeCMA{version: 1}
+			s, _ := newTestSyncer(testConfig(), []github.Repo{testRepo("api", 1), testRepo("cli", 2)}, &fakeGenerator{}, cma)
+
+			if _, err := s.Run(context.Background(), RunOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			s.cfg.ForceUpdate = tt.force
+			stats, err := s.Run(context.Background(), RunOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.Status != tt.wantStatus {
+				t.Errorf("second run Status = %q, want %q", stats.Status, tt.wantStatus)
+			}
+			if len(cma.updates) != tt.wantUpdates || len(cma.published) != tt.wantPublishes {
+				t.Errorf("got %d updates and %d publishes, want %d and %d", len(cma.updates), len(cma.published), tt.wantUpdates, tt.wantPublishes)
+			}
+		})
+	}
+}
+
+func TestRunWaitsBeforePublish(t *testing.T) {
+	cfg := testConfig()
+	cfg.PublishDelay = 3 * time.Second
+	cma := &fakeCMA{version: 1}
+	s, _ := newTestSyncer(cfg, []github.Repo{testRepo("api", 1)}, &fakeGenerator{}, cma)
+
+	if _, err := s.Run(context.Background(), RunOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []time.Duration{3 * time.Second}; !reflect.DeepEqual(cma.waits, want) || len(cma.published) != 1 {
+		t.Errorf("waits = %v before %d publishes, want %v before 1", cma.waits, len(cma.published), want)
+	}
+}
+
+func TestRunSink(t *testing.T) {
+	tests := []struct {
+		name     string
+		dryRun   bool
+		sinkErr  error
+		wantRuns [][]string
+	}{
+		{name: "records written projects", wantRuns: [][]string{{"api", "cli"}}},
+		{name: "dry run records nothing", dryRun: true},
+		{name: "sink failure does not fail the run", sinkErr: errors.New("disk full"), wantRuns: [][]string{{"api", "cli"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cma := &fakeCMA{version: 1}
+			s, _ := newTestSyncer(testConfig(), []github.Repo{testRepo("api", 1), testRepo("cli", 2)}, &fakeGenerator{}, cma)
+			sink := &fakeSink{err: tt.sinkErr}
+			s.SetSink(sink)
+
+			if _, err := s.Run(context.Background(), RunOptions{DryRun: tt.dryRun}); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(sink.runs, tt.wantRuns) {
+				t.Errorf("sink runs = %v, want %v", sink.runs, tt.wantRuns)
+			}
+		})
+	}
+}
+
+func TestRunWatermark(t *testing.T) {
+	tests := []struct {
+		name          string
+		field         string
+		noPublish     bool
+		wantUpdates   int
+		wantPublishes int
+		wantStatus    string
+	}{
+		{name: "no watermark skips the write", wantUpdates: 1, wantPublishes: 1, wantStatus: "no-changes"},
+		{name: "watermark is written and published", field: "lastSyncedAt", wantUpdates: 2, wantPublishes: 2, wantStatus: "success"},
+		{name: "watermark only is saved unpublished", field: "lastSyncedAt", noPublish: true, wantUpdates: 2, wantPublishes: 1, wantStatus: "no-changes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.WatermarkField = tt.field
+			cfg.WatermarkOnlyNoPublish = tt.noPublish
+			cma := &fakeCMA{version: 1}
+			s, _ := newTestSyncer(cfg, []github.Repo{testRepo("api", 1), testRepo("cli", 2)}, &fakeGenerator{}, cma)
+
+			if _, err := s.Run(context.Background(), RunOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			written := cma.projects
+			stats, err := s.Run(context.Background(), RunOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.Status != tt.wantStatus {
+				t.Errorf("second run Status = %q, want %q", stats.Status, tt.wantStatus)
+			}
+			if len(cma.updates) != tt.wantUpdates || len(cma.published) != tt.wantPublishes {
+				t.Errorf("got %d updates and %d publishes, want %d and %d", len(cma.updates), len(cma.published), tt.wantUpdates, tt.wantPublishes)
+			}
+			if !reflect.DeepEqual(cma.projects, written) {
+				t.Errorf("second run changed the content: %+v, want %+v", cma.projects, written)
+			}
+		})
+	}
+}
+
+func TestFetchDetailsConcurrency(t *testing.T) {
+	var repos []github.Repo
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		repos = append(repos, testRepo(name, 1))
+	}
+
+	tests := []struct {
+		name     string
+		flag     int
+		wantPeak int
+	}{
+		{name: "flag unset keeps FETCH_CONCURRENCY", flag: 0, wantPeak: 4},
+		{name: "flag of one serializes", flag: 1, wantPeak: 1},
+		{name: "flag widens the semaphore", flag: 6, wantPeak: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			if err := cfg.ApplyConcurrency(tt.flag); err != nil {
+				t.Fatal(err)
+			}
+			s, gh := newTestSyncer(cfg, repos, &fakeGenerator{})
+			gh.delay = 20 * time.Millisecond
+
+			if _, _, err := s.fetchDetails(context.Background(), repos); err != nil {
+				t.Fatal(err)
+			}
+			if gh.peak != tt.wantPeak {
+				t.Errorf("peak concurrent fetches = %d, want %d", gh.peak, tt.wantPeak)
+			}
+		})
+	}
+}
+
+func TestFetchDetailsFailureThreshold(t *testing.T) {
+	repos := []github.Repo{testRepo("a", 1), testRepo("b", 1), testRepo("c", 1), testRepo("d", 1)}
+	boom := errors.New("502 bad gateway")
+
+	tests := []struct {
+		name       string
+		failing    []string
+		wantFailed int
+		wantErr    bool
+		wantCount  int
+	}{
+		{name: "no failures", wantCount: 4},
+		{name: "below threshold", failing: []string{"a"}, wantFailed: 1, wantCount: 4},
+		{name: "at threshold", failing: []string{"a", "b"}, wantFailed: 2, wantCount: 4},
+		{name: "above threshold", failing: []string{"a", "b", "c"}, wantFailed: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, gh := newTestSyncer(testConfig(), repos, &fakeGenerator{})
+			gh.readmeErr = map[string]error{}
+			for _, name := range tt.failing {
+				gh.readmeErr[name] = boom
+			}
+
+			raw, failed, err := s.fetchDetails(context.Background(), repos)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, boom) {
+				t.Errorf("err = %v, want it to wrap the per-repo error", err)
+			}
+			if failed != tt.wantFailed {
+				t.Errorf("failed = %d, want %d", failed, tt.wantFailed)
+			}
+			if len(raw) != tt.wantCount {
+				t.Errorf("got %d raw projects, want %d", len(raw), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestFetchDetailsDecodesBase64README(t *testing.T) {
+	const readme = "# a\n\nA CLI that syncs repos.\n"
+	encoded := base64.StdEncoding.EncodeToString([]byte(readme))
+
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{name: "plain", payload: readme},
+		{name: "base64", payload: encoded},
+		{name: "wrapped base64", payload: encoded[:20] + "\n" + encoded[20:] + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := []github.Repo{testRepo("a", 1)}
+			s, gh := newTestSyncer(testConfig(), repos, &fakeGenerator{})
+			gh.readmes["a"] = tt.payload
+
+			raw, _, err := s.fetchDetails(context.Background(), repos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(raw) != 1 || raw[0].ReadmeRaw != readme {
+				t.Errorf("raw = %+v, want the decoded README", raw)
+			}
+		})
+	}
+}
+
+func TestFetchDetailsTranscodesREADME(t *testing.T) {
+	const readme = "# Café\n\nA CLI à la carte.\n"
+
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{name: "BOM", payload: "\xef\xbb\xbf" + readme, want: readme},
+		{name: "Latin-1", payload: "# Caf\xe9\n\nA CLI \xe0 la carte.\n", want: readme},
+		{name: "base64 Latin-1", payload: base64.StdEncoding.EncodeToString([]byte("# Caf\xe9\n\nA CLI \xe0 la carte.\n")), want: readme},
+		{name: "binary dropped", payload: "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := []github.Repo{testRepo("a", 1)}
+			s, gh := newTestSyncer(testConfig(), repos, &fakeGenerator{})
+			gh.readmes["a"] = tt.payload
+
+			raw, _, err := s.fetchDetails(context.Background(), repos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(raw) != 1 || raw[0].ReadmeRaw != tt.want {
+				t.Errorf("raw = %+v, want README %q", raw, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourcesPrune(t *testing.T) {
+	listed := map[string]bool{"api": true, "mono": true, "hidden": true}
+	fetched := []github.Repo{testRepo("api", 1), testRepo("mono", 1)}
+	raw := []mapper.RawProject{
+		{Slug: "api"},
+		{Slug: "mono-pkg-a", Parent: "mono"},
+	}
+	src := newSources(listed, fetched, raw)
+
+	tests := []struct {
+		slug string
+		want bool
+	}{
+		{slug: "api", want: true},
+		{slug: "hidden", want: true},
+		{slug: "mono-pkg-a", want: true},
+		{slug: "mono-pkg-b", want: false},
+		{slug: "api-old", want: false},
+		{slug: "hidden-pkg", want: true},
+		{slug: "gone", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.slug, func(t *testing.T) {
+			kept, pruned := src.prune([]contentful.Project{{Slug: tt.slug}})
+			if got := len(kept) == 1; got != tt.want {
+				t.Errorf("kept %s = %v, want %v", tt.slug, got, tt.want)
+			}
+			if wantPruned := map[bool]int{true: 0, false: 1}[tt.want]; pruned != wantPruned {
+				t.Errorf("pruned = %d, want %d", pruned, wantPruned)
+			}
+		})
+	}
+}
+
+func TestDedupeSlugs(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  []mapper.RawProject
+		want []string
+	}{
+		{
+			name: "no collisions",
+			raw:  []mapper.RawProject{{Slug: "api"}, {Slug: "mono-web", Parent: "mono"}},
+			want: []string{"api", "mono-web"},
+		},
+		{
+			name: "repo wins over sub-project",
+			raw:  []mapper.RawProject{{Slug: "mono-web", Parent: "mono"}, {Slug: "mono-web"}},
+			want: []string{"mono-web"},
+		},
+		{
+			name: "first parent wins between sub-projects",
+			raw:  []mapper.RawProject{{Slug: "a-b-c", Parent: "a-b"}, {Slug: "a-b-c", Parent: "a"}},
+			want: []string{"a-b-c"},
+		},
+		{
+			name: "duplicate path",
+			raw:  []mapper.RawProject{{Slug: "mono-web", Parent: "mono"}, {Slug: "mono-web", Parent: "mono"}},
+			want: []string{"mono-web"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dedupeSlugs(tt.raw)
+			var slugs []string
+			for _, p := range got {
+				slugs = append(slugs, p.Slug)
+			}
+			if !reflect.DeepEqual(slugs, tt.want) {
+				t.Errorf("slugs = %v, want %v", slugs, tt.want)
+			}
+		})
+	}
+
+	got := dedupeSlugs([]mapper.RawProject{{Slug: "a-b-c", Parent: "a-b"}, {Slug: "a-b-c", Parent: "a"}})
+	if got[0].Parent != "a" {
+		t.Errorf("kept sub-project of %q, want %q", got[0].Parent, "a")
+	}
+}
+
+func TestRunLocales(t *testing.T) {
+	repos := []github.Repo{testRepo("api", 1), testRepo("cli", 2), testRepo("web", 3)}
+	withLocales := func(c *config.Config) { c.Locales = []string{"es-ES"} }
+
+	t.Run("unchanged content skips translation", func(t *testing.T) {
+		cfg := testConfig()
+		withLocales(cfg)
+		cma := &fakeCMA{version: 1}
+		gen := &fakeGenerator{}
+		s, _ := newTestSyncer(cfg, repos, gen, cma)
+
+		if _, err := s.Run(context.Background(), RunOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		if got := gen.translationCount(); got != 1 {
+			t.Fatalf("first run made %d translation requests, want 1", got)
+		}
+		stats, err := s.Run(context.Background(), RunOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Status != "no-changes" {
+			t.Errorf("Status = %q, want %q", stats.Status, "no-changes")
+		}
+		if got := gen.translationCount(); got != 1 {
+			t.Errorf("second run made %d more translation requests, want 0", got-1)
+		}
+	})
+
+	t.Run("dry run skips translation", func(t *testing.T) {
+		cfg := testConfig()
+		withLocales(cfg)
+		gen := &fakeGenerator{}
+		s, _ := newTestSyncer(cfg, repos, gen, &fakeCMA{version: 1})
+		if _, err := s.Run(context.Background(), RunOptions{DryRun: true}); err != nil {
+			t.Fatal(err)
+		}
+		if got := gen.translationCount(); got != 0 {
+			t.Errorf("made %d translation requests, want 0", got)
+		}
+	})
+
+	t.Run("batch size chunks translation", func(t *testing.T) {
+		cfg := testConfig()
+		withLocales(cfg)
+		cfg.GeminiBatchSize = 2
+		gen := &fakeGenerator{}
+		cma := &fakeCMA{version: 1}
+		s, _ := newTestSyncer(cfg, repos, gen, cma)
+		if _, err := s.Run(context.Background(), RunOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		if got := gen.translationCount(); got != 2 {
+			t.Errorf("made %d translation requests, want 2", got)
+		}
+		for _, p := range cma.localized["es-ES"] {
+			if !strings.HasPrefix(p.ShortDescription, "es-ES ") {
+				t.Errorf("%s es-ES text = %q, want a translation", p.Slug, p.ShortDescription)
+			}
+		}
+	})
+
+	t.Run("degraded run keeps the current translation of CMS copies", func(t *testing.T) {
+		cfg := testConfig()
+		withLocales(cfg)
+		cma := &fakeCMA{
+			projects:  []contentful.Project{{Slug: "web", ShortDescription: "Web"}},
+			localized: map[string][]contentful.Project{"es-ES": {{Slug: "web", ShortDescription: "Web (es)"}}},
+			version:   1,
+		}
+		s, _ := newTestSyncer(cfg, repos, &fakeGenerator{fail: map[string]bool{"web": true}}, cma)
+		if _, err := s.Run(context.Background(), RunOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]string{}
+		for _, p := range cma.localized["es-ES"] {
+			got[p.Slug] = p.ShortDescription
+		}
+		want := map[string]string{"api": "es-ES About api", "cli": "es-ES About cli", "web": "Web (es)"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("es-ES text = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestRunFillGaps(t *testing.T) {
+	repos := []github.Repo{testRepo("api", 1), testRepo("cli", 2), testRepo("web", 3)}
+	cfg := testConfig()
+	cfg.FillGaps = true
+	cfg.FieldTransforms = []transform.Rule{{Field: "name", Op: "suffix", Arg: "!"}}
+	cma := &fakeCMA{
+		projects: []contentful.Project{{Slug: "api", Name: "API!"}, {Slug: "cli", Name: "CLI!"}},
+		version:  1,
+	}
+	s, gh := newTestSyncer(cfg, repos, &fakeGenerator{}, cma)
+
+	stats, err := s.Run(context.Background(), RunOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gh.fetched["api"] > 0 || gh.fetched["cli"] > 0 || gh.fetched["web"] != 1 {
+		t.Errorf("fetched %v, want only web", gh.fetched)
+	}
+	names := map[string]string{}
+	for _, p := range stats.Targets[0].Projects {
+		names[p.Slug] = p.Name
+	}
+	want := map[string]string{"api": "API!", "cli": "CLI!", "web": "web!"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+	if stats.NewAdded != 1 {
+		t.Errorf("NewAdded = %d, want 1", stats.NewAdded)
+	}
+}
+
+func TestRunArchive(t *testing.T) {
+	old := testRepo("old", 400)
+	old.Archived = true
+
+	tests := []struct {
+		name        string
+		repos       []github.Repo
+		wantStatus  string
+		wantMain    []string
+		wantArchive []string
+	}{
+		{
+			name:        "archived repo goes to the archive",
+			repos:       []github.Repo{testRepo("api", 1), old},
+			wantStatus:  "success",
+			wantMain:    []string{"api"},
+			wantArchive: []string{"old"},
+		},
+		{
+			name:        "archive written without active repos",
+			repos:       []github.Repo{old},
+			wantStatus:  "empty",
+			wantArchive: []string{"old"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.ArchiveEntryID = "archive"
+			cma := &fakeCMA{version: 1}
+			s, _ := newTestSyncer(cfg, tt.repos, &fakeGenerator{}, cma)
+
+			stats, err := s.Run(context.Background(), RunOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", stats.Status, tt.wantStatus)
+			}
+			if got := projectSlugs(cma.projects); !reflect.DeepEqual(got, tt.wantMain) {
+				t.Errorf("main slugs = %v, want %v", got, tt.wantMain)
+			}
+			if got := projectSlugs(cma.entries["archive"]); !reflect.DeepEqual(got, tt.wantArchive) {
+				t.Errorf("archive slugs = %v, want %v", got, tt.wantArchive)
+			}
+			if stats.ArchiveTotal != len(tt.wantArchive) {
+				t.Errorf("ArchiveTotal = %d, want %d", stats.ArchiveTotal, len(tt.wantArchive))
+			}
+		})
+	}
+}
+
+func TestRunKeepsLiveURLs(t *testing.T) {
+	bad := "not a url"
+	broken := testRepo("broken", 1)
+	broken.Homepage = &bad
+	repos := []github.Repo{broken, testRepo("plain", 2)}
+
+	cfg := testConfig()
+	cfg.NormalizeURLs = true
+	cma := &fakeCMA{
+		projects: []contentful.Project{
+			{Slug: "broken", LiveURL: "https://old-broken.dev"},
+			{Slug: "plain", LiveURL: "https://plain.dev"},
+		},
+		version: 1,
+	}
+	s, _ := newTestSyncer(cfg, repos, &fakeGenerator{}, cma)
+
+	stats, err := s.Run(context.Background(), RunOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, p := range stats.Targets[0].Projects {
+		got[p.Slug] = p.LiveURL
+	}
+	want := map[string]string{"broken": "", "plain": "https://plain.dev"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("liveUrls = %v, want %v", got, want)
+	}
+}
+
+func TestRunEnrichCache(t *testing.T) {
+	tests := []struct {
+		name        string
+		force       bool
+		forceEnrich bool
+		wantCalls   int
+		wantWrites  int
+	}{
+		{name: "cached and unchanged", wantCalls: 0, wantWrites: 1},
+		{name: "force writes from the cache", force: true, wantCalls: 0, wantWrites: 2},
+		{name: "force enrich bypasses the cache without writing", forceEnrich: true, wantCalls: 1, wantWrites: 1},
+		{name: "both", force: true, forceEnrich: true, wantCalls: 1, wantWrites: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := []github.Repo{testRepo("api", 1), testRepo("cli", 2)}
+			cfg := testConfig()
+			cfg.EnrichCachePath = filepath.Join(t.TempDir(), "cache.json")
+			cma := &fakeCMA{version: 1}
+			s, _ := newTestSyncer(cfg, repos, &fakeGenerator{}, cma)
+			if _, err := s.Run(context.Background(), RunOptions{}); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg.ForceUpdate, cfg.ForceEnrich = tt.force, tt.forceEnrich
+			gen := &fakeGenerator{}
+			s.SetGenerator(gen)
+			if _, err := s.Run(context.Background(), RunOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			if got := gen.requestCount(); got != tt.wantCalls {
+				t.Errorf("second run made %d Gemini requests, want %d", got, tt.wantCalls)
+			}
+			if len(cma.updates) != tt.wantWrites || len(cma.published) != tt.wantWrites {
+				t.Errorf("got %d updates and %d publishes, want %d each", len(cma.updates), len(cma.published), tt.wantWrites)
+			}
+		})
+	}
+}
+
+func TestRunPreserveFeatured(t *testing.T) {
+	repos := []github.Repo{testRepo("new", 1), testRepo("recent", 2), testRepo("old", 30), testRepo("older", 40)}
+
+	tests := []struct {
+		name         string
+		featuredCMS  []string
+		recompute    bool
+		priority     []string
+		wantFeatured []string
+	}{
+		{name: "nothing configured pins every CMS-featured project", featuredCMS: []string{"old", "older"}, wantFeatured: []string{"old", "older"}},
+		{name: "pins fill slots before automatic picks", featuredCMS: []string{"old"}, wantFeatured: []string{"old", "new"}},
+		{name: "nothing featured in the CMS", wantFeatured: []string{"new", "recent"}},
+		{name: "recompute ignores CMS flags", featuredCMS: []string{"old", "older"}, recompute: true, wantFeatured: []string{"new", "recent"}},
+		{name: "after priority slugs", featuredCMS: []string{"old"}, priority: []string{"recent"}, wantFeatured: []string{"recent", "old"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.RecomputeFeatured = tt.recompute
+			cfg.PriorityOrder = tt.priority
+			var current []contentful.Project
+			for _, slug := range []string{"old", "older", "recent"} {
+				current = append(current, contentful.Project{Slug: slug, Featured: slices.Contains(tt.featuredCMS, slug)})
+			}
+			reconciled := successEntry(time.Now().Add(-time.Hour), "")
+			reconciled.FeaturedScheme = FeaturedScheme
+			cma := &fakeCMA{projects: current, version: 1, buildLog: []contentful.BuildLogEntry{reconciled}}
+			s, _ := newTestSyncer(cfg, repos, &fakeGenerator{}, cma)
+
+			stats, err := s.Run(context.Background(), RunOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var featured []string
+			for _, p := range stats.Targets[0].Projects {
+				if p.Featured {
+					featured = append(featured, p.Slug)
+				}
+			}
+			if !reflect.DeepEqual(featured, tt.wantFeatured) {
+				t.Errorf("featured = %v, want %v", featured, tt.wantFeatured)
+			}
+		})
+	}
+}
+
+func TestRunReconcilesFeatured(t *testing.T) {
+	repos := []github.Repo{testRepo("new", 1), testRepo("recent", 2), testRepo("old", 30)}
+	stale := []contentful.Project{{Slug: "old", Featured: true}, {Slug: "recent"}, {Slug: "new"}}
+	older := successEntry(time.Now().Add(-time.Hour), "")
+
+	tests := []struct {
+		name         string
+		scheme       int
+		dryRun       bool
+		wantFeatured []string
+		wantScheme   int
+	}{
+		{name: "first run ignores stale flags", wantFeatured: []string{"new", "recent"}, wantScheme: FeaturedScheme},
+		{name: "dry run reconciles without recording it", dryRun: true, wantFeatured: []string{"new", "recent"}},
+		{name: "later runs keep pinned flags", scheme: FeaturedScheme, wantFeatured: []string{"old", "new"}, wantScheme: FeaturedScheme},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			entry := older
+			entry.FeaturedScheme = tt.scheme
+			cma := &fakeCMA{projects: slices.Clone(stale), version: 1, buildLog: []contentful.BuildLogEntry{entry}}
+			s, _ := newTestSyncer(cfg, repos, &fakeGenerator{}, cma)
+
+			stats, err := s.Run(context.Background(), RunOptions{DryRun: tt.dryRun})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var featured []string
+			for _, p := range stats.Targets[0].Projects {
+				if p.Featured {
+					featured = append(featured, p.Slug)
+				}
+			}
+			if !reflect.DeepEqual(featured, tt.wantFeatured) {
+				t.Errorf("featured = %v, want %v", featured, tt.wantFeatured)
+			}
+			if stats.FeaturedScheme != tt.wantScheme {
+				t.Errorf("FeaturedScheme = %d, want %d", stats.FeaturedScheme, tt.wantScheme)
+			}
+		})
+	}
+}
+
+func TestRunKeepsPinsPastBuildLogPruning(t *testing.T) {
+	const keep = 3
+	repos := []github.Repo{testRepo("new", 1), testRepo("recent", 2), testRepo("old", 30)}
+	pinned := []contentful.Project{{Slug: "old", Featured: true}, {Slug: "recent", Featured: true}, {Slug: "new"}}
+
+	tests := []struct {
+		name          string
+		skipUnchanged bool
+	}{
+		{name: "unchanged writes"},
+		{name: "early exits", skipUnchanged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.SkipUnchanged = tt.skipUnchanged
+			reconciled := successEntry(time.Now().Add(-time.Hour), ConfigHash(cfg))
+			reconciled.FeaturedScheme = FeaturedScheme
+			cma := &fakeCMA{projects: slices.Clone(pinned), version: 1, buildLog: []contentful.BuildLogEntry{reconciled}}
+			s, _ := newTestSyncer(cfg, repos, &fakeGenerator{}, cma)
+			cfg.ForceUpdate = true
+			first, err := s.Run(context.Background(), RunOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cma.recordRun(cfg, first, keep)
+			cfg.ForceUpdate = false
+
+			// Enough unchanged runs to prune the entry that recorded the scheme
+			for i := 0; i < keep+1; i++ {
+				stats, err := s.Run(context.Background(), RunOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if stats.Status != "no-changes" || stats.FeaturedScheme != FeaturedScheme {
+					t.Fatalf("run %d: status %q, scheme %d, want no-changes under scheme %d", i+1, stats.Status, stats.FeaturedScheme, FeaturedScheme)
+				}
+				cma.recordRun(cfg, stats, keep)
+			}
+			if slices.ContainsFunc(cma.buildLog, func(e contentful.BuildLogEntry) bool { return e.Status == "success" }) {
+				t.Fatalf("build log still holds the success entry: %+v", cma.buildLog)
+			}
+
+			cfg.ForceUpdate = true
+			stats, err := s.Run(context.Background(), RunOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var featured []string
+			for _, p := range stats.Targets[0].Projects {
+				if p.Featured {
+					featured = append(featured, p.Slug)
+				}
+			}
+			if want := []string{"recent", "old"}; !reflect.DeepEqual(featured, want) {
+				t.Errorf("featured = %v, want the pin kept: %v", featured, want)
+			}
+		})
+	}
+}
+
+func TestRunSeededIsDeterministic(t *testing.T) {
+	var repos []github.Repo
+	for i := range 9 {
+		repos = append(repos, testRepo(fmt.Sprintf("repo-%d", i), i+1))
+	}
+	seed := int64(42)
+
+	run := func() (prompts []string, written []string) {
+		cfg := testConfig()
+		cfg.RandomSeed = &seed
+		cfg.GeminiBatchSize = 4
+		cfg.FetchConcurrency = len(repos)
+		gen := &fakeGenerator{}
+		cma := &fakeCMA{version: 1}
+		s, gh := newTestSyncer(cfg, repos, gen, cma)
+		gh.jitter = 5 * time.Millisecond
+
+		if _, err := s.Run(context.Background(), RunOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		// Chunks may be sent in any order; what each one holds must not vary
+		prompts = slices.Clone(gen.prompts)
+		slices.Sort(prompts)
+		return prompts, projectSlugs(cma.projects)
+	}
+
+	wantPrompts, wantWritten := run()
+	for i := range 5 {
+		prompts, written := run()
+		if !reflect.DeepEqual(prompts, wantPrompts) {
+			t.Fatalf("run %d sent different prompts:\n%q\nwant\n%q", i+2, prompts, wantPrompts)
+		}
+		if !reflect.DeepEqual(written, wantWritten) {
+			t.Fatalf("run %d wrote %v, want %v", i+2, written, wantWritten)
+		}
+	}
+}
+
+func TestRunFanOut(t *testing.T) {
+	boom := errors.New("500 internal error")
+	repos := []github.Repo{testRepo("api", 1), testRepo("cli", 2)}
+
+	tests := []struct {
+		name       string
+		failing    []bool
+		wantErr    bool
+		wantStatus string
+	}{
+		{name: "every target written", failing: []bool{false, false}, wantStatus: "success"},
+		{name: "one target failing", failing: []bool{false, true}, wantStatus: "partial"},
+		{name: "primary failing", failing: []bool{true, false}, wantStatus: "partial"},
+		{name: "every target failing", failing: []bool{true, true}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cmas []*fakeCMA
+			for _, fail := range tt.failing {
+				cma := &fakeCMA{version: 1}
+				if fail {
+					cma.updateErr = boom
+				}
+				cmas = append(cmas, cma)
+			}
+			gen := &fakeGenerator{}
+			s, gh := newTestSyncer(testConfig(), repos, gen, cmas...)
+
+			stats, err := s.Run(context.Background(), RunOptions{})
+			if gen.requestCount() != 1 || gh.fetchCount() != len(repos) {
+				t.Errorf("got %d Gemini requests and %d fetches, want 1 and %d", gen.requestCount(), gh.fetchCount(), len(repos))
+			}
+			if tt.wantErr {
+				if !errors.Is(err, boom) {
+					t.Fatalf("err = %v, want %v", err, boom)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", stats.Status, tt.wantStatus)
+			}
+			for i, fail := range tt.failing {
+				ts := stats.Targets[i]
+				if ts.Name != fmt.Sprintf("target-%d", i) {
+					t.Errorf("Targets[%d].Name = %q, want results in target order", i, ts.Name)
+				}
+				if (ts.Err != nil) != fail {
+					t.Errorf("Targets[%d].Err = %v, want failure %v", i, ts.Err, fail)
+				}
+				written := len(cmas[i].published) == 1 && reflect.DeepEqual(projectSlugs(cmas[i].projects), []string{"api", "cli"})
+				if written == fail {
+					t.Errorf("target %d written = %v, want %v", i, written, !fail)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+// DisplayDateLayout is the format of Project.DisplayDate.
+const DisplayDateLayout = "2006-01-02"
+
+// ParseDisplayDates reads a semicolon-separated list of slug:YYYY-MM-DD
+// entries, e.g. "flagship:2024-03-01;other-repo:2023-11-15".
+func ParseDisplayDates(spec string) (map[string]string, error) {
+	dates := map[string]string{}
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		slug, date, ok := strings.Cut(part, ":")
+		slug, date = strings.TrimSpace(slug), strings.TrimSpace(date)
+		if !ok || slug == "" {
+			return nil, fmt.Errorf("display date %q: expected slug:YYYY-MM-DD", part)
+		}
+		if _, err := time.Parse(DisplayDateLayout, date); err != nil {
+			return nil, fmt.Errorf("display date %q: expected YYYY-MM-DD, got %q", part, date)
+		}
+		dates[slug] = date
+	}
+	return dates, nil
+}
+
+// SetDisplayDates fills DisplayDate on every project from overrides, falling
+// back to the PushedAt date. Projects with neither keep their current value.
+// PushedAt itself is left alone, so ranking is unaffected.
+func SetDisplayDates(projects []contentful.Project, overrides map[string]string) []contentful.Project {
+	for i := range projects {
+		if date, ok := overrides[projects[i].Slug]; ok {
+			projects[i].DisplayDate = date
+		} else if !projects[i].PushedAt.IsZero() {
+			projects[i].DisplayDate = projects[i].PushedAt.UTC().Format(DisplayDateLayout)
+		}
+	}
+	return projects
+}
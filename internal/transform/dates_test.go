@@ -0,0 +1,62 @@
+package transform
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+func TestParseDisplayDates(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "empty", spec: "", want: map[string]string{}},
+		{name: "several", spec: " flagship:2024-03-01 ; other-repo:2023-11-15;", want: map[string]string{"flagship": "2024-03-01", "other-repo": "2023-11-15"}},
+		{name: "missing date", spec: "flagship", wantErr: true},
+		{name: "missing slug", spec: ":2024-03-01", wantErr: true},
+		{name: "bad date", spec: "flagship:03/01/2024", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDisplayDates(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDisplayDates(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetDisplayDates(t *testing.T) {
+	pushed := time.Date(2026, 5, 4, 23, 30, 0, 0, time.FixedZone("PDT", -7*3600))
+
+	tests := []struct {
+		name      string
+		project   contentful.Project
+		overrides map[string]string
+		want      string
+	}{
+		{name: "pushed date in UTC", project: contentful.Project{Slug: "a", PushedAt: pushed}, want: "2026-05-05"},
+		{name: "override wins", project: contentful.Project{Slug: "a", PushedAt: pushed}, overrides: map[string]string{"a": "2024-03-01"}, want: "2024-03-01"},
+		{name: "override for another slug", project: contentful.Project{Slug: "a", PushedAt: pushed}, overrides: map[string]string{"b": "2024-03-01"}, want: "2026-05-05"},
+		{name: "no pushed date keeps the current value", project: contentful.Project{Slug: "a", DisplayDate: "2020-01-01"}, want: "2020-01-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SetDisplayDates([]contentful.Project{tt.project}, tt.overrides)
+			if got[0].DisplayDate != tt.want {
+				t.Errorf("DisplayDate = %q, want %q", got[0].DisplayDate, tt.want)
+			}
+			if !got[0].PushedAt.Equal(tt.project.PushedAt) {
+				t.Errorf("PushedAt = %v, want it unchanged", got[0].PushedAt)
+			}
+		})
+	}
+}
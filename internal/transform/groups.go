@@ -0,0 +1,64 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+// OtherGroup collects technologies that no configured group lists.
+const OtherGroup = "Other"
+
+// Groups maps a lowercased technology name to its display group.
+type Groups map[string]string
+
+// ParseGroups reads a semicolon-separated list of group:tech|tech entries,
+// e.g. "Languages:Go|Python;Databases:PostgreSQL|Redis". Technologies match
+// case-insensitively; listing one under two groups is an error.
+func ParseGroups(spec string) (Groups, error) {
+	groups := Groups{}
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		group, techs, ok := strings.Cut(part, ":")
+		if !ok || strings.TrimSpace(group) == "" {
+			return nil, fmt.Errorf("group %q: expected group:tech|tech", part)
+		}
+		group = strings.TrimSpace(group)
+		for _, t := range strings.Split(techs, "|") {
+			key := strings.ToLower(strings.TrimSpace(t))
+			if key == "" {
+				continue
+			}
+			if prev, dup := groups[key]; dup && prev != group {
+				return nil, fmt.Errorf("group %q: %q is already in %q", part, t, prev)
+			}
+			groups[key] = group
+		}
+	}
+	return groups, nil
+}
+
+// GroupTechnologies sets TechnologiesByGroup on every project from its flat
+// Technologies list, keeping their order within each group. Unlisted
+// technologies go to OtherGroup. It is a no-op when groups is empty.
+func GroupTechnologies(projects []contentful.Project, groups Groups) {
+	if len(groups) == 0 {
+		return
+	}
+	for i := range projects {
+		byGroup := make(map[string][]string)
+		for _, t := range projects[i].Technologies {
+			group, ok := groups[strings.ToLower(t)]
+			if !ok {
+				group = OtherGroup
+			}
+			byGroup[group] = append(byGroup[group], t)
+		}
+		projects[i].TechnologiesByGroup = byGroup
+	}
+}
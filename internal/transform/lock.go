@@ -0,0 +1,88 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+// Locks maps a project slug to the JSON field names whose CMS values must
+// survive enrichment.
+type Locks map[string][]string
+
+// ParseLocks reads a semicolon-separated list of slug:field|field entries,
+// e.g. "flagship:technologies|highlights;other-repo:category".
+func ParseLocks(spec string) (Locks, error) {
+	locks := Locks{}
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		slug, fields, ok := strings.Cut(part, ":")
+		if !ok || strings.TrimSpace(slug) == "" {
+			return nil, fmt.Errorf("lock %q: expected slug:field|field", part)
+		}
+		slug = strings.TrimSpace(slug)
+		for _, f := range strings.Split(fields, "|") {
+			f = strings.TrimSpace(f)
+			if !lockable(f) {
+				return nil, fmt.Errorf("lock %q: unknown field %q", part, f)
+			}
+			locks[slug] = append(locks[slug], f)
+		}
+	}
+	return locks, nil
+}
+
+// RestoreLocked returns a copy of projects where every locked field is reset
+// to the value the same slug currently has in existing. Projects without a
+// CMS counterpart keep their generated values.
+func RestoreLocked(projects, existing []contentful.Project, locks Locks) []contentful.Project {
+	out := make([]contentful.Project, len(projects))
+	copy(out, projects)
+	if len(locks) == 0 {
+		return out
+	}
+
+	bySlug := make(map[string]contentful.Project, len(existing))
+	for _, p := range existing {
+		bySlug[p.Slug] = p
+	}
+
+	for i := range out {
+		prev, ok := bySlug[out[i].Slug]
+		if !ok {
+			continue
+		}
+		for _, field := range locks[out[i].Slug] {
+			restoreField(&out[i], prev, field)
+		}
+	}
+	return out
+}
+
+func lockable(field string) bool {
+	if field == "technologies" || field == "highlights" {
+		return true
+	}
+	_, ok := fieldPtr(&contentful.Project{}, field)
+	return ok
+}
+
+func restoreField(p *contentful.Project, prev contentful.Project, field string) {
+	switch field {
+	case "technologies":
+		p.Technologies = prev.Technologies
+	case "highlights":
+		p.Highlights = prev.Highlights
+	default:
+		dst, _ := fieldPtr(p, field)
+		src, _ := fieldPtr(&prev, field)
+		if dst != nil && src != nil {
+			*dst = *src
+		}
+	}
+}
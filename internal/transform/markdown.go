@@ -0,0 +1,288 @@
+package transform
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+// StripMarkdown converts inline markdown in each project's short and long
+// description and highlights to plain text: emphasis and strikethrough
+// markers are removed, links and images become their text, code spans keep
+// their content, and leading heading, quote and list markers are dropped.
+func StripMarkdown(projects []contentful.Project) []contentful.Project {
+	for i := range projects {
+		p := &projects[i]
+		p.ShortDescription = PlainText(p.ShortDescription)
+		p.LongDescription = PlainText(p.LongDescription)
+		if p.Highlights != nil {
+			highlights := make([]string, len(p.Highlights))
+			for j, h := range p.Highlights {
+				highlights[j] = PlainText(h)
+			}
+			p.Highlights = highlights
+		}
+	}
+	return projects
+}
+
+// PlainText renders markdown text as plain text, line by line. Emphasis is
+// paired with CommonMark's flanking rules, so snake_case words and lone
+// asterisks are left alone.
+func PlainText(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = renderInline(stripBlockMarker(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// stripBlockMarker removes a leading ATX heading, blockquote or list marker.
+func stripBlockMarker(line string) string {
+	rest := strings.TrimLeft(line, " ")
+	indent := line[:len(line)-len(rest)]
+
+	for strings.HasPrefix(rest, ">") {
+		rest = strings.TrimLeft(rest[1:], " ")
+	}
+	if n := len(rest) - len(strings.TrimLeft(rest, "#")); n > 0 && n <= 6 && (n == len(rest) || rest[n] == ' ') {
+		return indent + strings.TrimRight(strings.TrimSpace(rest[n:]), "# ")
+	}
+	if len(rest) >= 2 && strings.IndexByte("-*+", rest[0]) >= 0 && rest[1] == ' ' {
+		return indent + rest[2:]
+	}
+	if n := len(rest) - len(strings.TrimLeft(rest, "0123456789")); n > 0 && n < 10 && len(rest) > n+1 &&
+		(rest[n] == '.' || rest[n] == ')') && rest[n+1] == ' ' {
+		return indent + rest[n+2:]
+	}
+	return indent + rest
+}
+
+// segment is a piece of inline text or a run of emphasis delimiters.
+type segment struct {
+	text string
+
+	delim             byte
+	n                 int
+	canOpen, canClose bool
+}
+
+// renderInline strips inline markdown from a single line.
+func renderInline(s string) string {
+	var segs []segment
+	var buf strings.Builder
+	flush := func() {
+		if buf.Len() > 0 {
+			segs = append(segs, segment{text: buf.String()})
+			buf.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			buf.WriteByte(s[i+1])
+			i += 2
+
+		case c == '`':
+			n := runLength(s, i, '`')
+			if end := closingBackticks(s, i+n, n); end >= 0 {
+				buf.WriteString(trimCodeSpan(s[i+n : end]))
+				i = end + n
+			} else {
+				buf.WriteString(s[i : i+n])
+				i += n
+			}
+
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if text, end, ok := parseLink(s, i+1); ok {
+				buf.WriteString(renderInline(text))
+				i = end
+			} else {
+				buf.WriteByte(c)
+				i++
+			}
+
+		case c == '[':
+			if text, end, ok := parseLink(s, i); ok {
+				buf.WriteString(renderInline(text))
+				i = end
+			} else {
+				buf.WriteByte(c)
+				i++
+			}
+
+		case c == '<':
+			if end := strings.IndexByte(s[i:], '>'); end > 0 && isAutolink(s[i+1:i+end]) {
+				buf.WriteString(s[i+1 : i+end])
+				i += end + 1
+			} else {
+				buf.WriteByte(c)
+				i++
+			}
+
+		case c == '*' || c == '_' || c == '~':
+			n := runLength(s, i, c)
+			if c == '~' && n != 2 {
+				buf.WriteString(s[i : i+n])
+				i += n
+				continue
+			}
+			before, _ := utf8.DecodeLastRuneInString(s[:i])
+			after, _ := utf8.DecodeRuneInString(s[i+n:])
+			if i == 0 {
+				before = ' '
+			}
+			if i+n == len(s) {
+				after = ' '
+			}
+			left := !unicode.IsSpace(after) && (!isPunct(after) || unicode.IsSpace(before) || isPunct(before))
+			right := !unicode.IsSpace(before) && (!isPunct(before) || unicode.IsSpace(after) || isPunct(after))
+			seg := segment{delim: c, n: n, canOpen: left, canClose: right}
+			if c == '_' {
+				seg.canOpen = left && (!right || isPunct(before))
+				seg.canClose = right && (!left || isPunct(after))
+			}
+			flush()
+			segs = append(segs, seg)
+			i += n
+
+		default:
+			buf.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+
+	matchEmphasis(segs)
+
+	var out strings.Builder
+	for _, seg := range segs {
+		if seg.delim == 0 {
+			out.WriteString(seg.text)
+		} else {
+			out.WriteString(strings.Repeat(string(seg.delim), seg.n))
+		}
+	}
+	return out.String()
+}
+
+// matchEmphasis pairs each closing delimiter run with the nearest open run
+// of the same character and consumes the matched markers from both.
+func matchEmphasis(segs []segment) {
+	for ci := range segs {
+		closer := &segs[ci]
+		for closer.delim != 0 && closer.canClose && closer.n > 0 {
+			oi := ci - 1
+			for ; oi >= 0; oi-- {
+				if segs[oi].delim == closer.delim && segs[oi].canOpen && segs[oi].n > 0 {
+					break
+				}
+			}
+			if oi < 0 {
+				break
+			}
+			opener := &segs[oi]
+			m := min(opener.n, closer.n)
+			opener.n -= m
+			closer.n -= m
+			// Runs between a matched pair can no longer open across it
+			for k := oi + 1; k < ci; k++ {
+				segs[k].canOpen = false
+			}
+		}
+	}
+}
+
+// parseLink parses "[text](dest)" or "[text][ref]" starting at the "[" at i,
+// returning the text and the index after the link.
+func parseLink(s string, i int) (text string, end int, ok bool) {
+	closeText := matchBracket(s, i, '[', ']')
+	if closeText < 0 || closeText+1 >= len(s) {
+		return "", 0, false
+	}
+	switch s[closeText+1] {
+	case '(':
+		closeDest := matchBracket(s, closeText+1, '(', ')')
+		if closeDest < 0 {
+			return "", 0, false
+		}
+		return s[i+1 : closeText], closeDest + 1, true
+	case '[':
+		closeRef := matchBracket(s, closeText+1, '[', ']')
+		if closeRef < 0 {
+			return "", 0, false
+		}
+		return s[i+1 : closeText], closeRef + 1, true
+	}
+	return "", 0, false
+}
+
+// matchBracket returns the index of the bracket closing the one at i,
+// honouring nesting and backslash escapes, or -1.
+func matchBracket(s string, i int, open, close byte) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+func runLength(s string, i int, c byte) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+	return n
+}
+
+// closingBackticks finds a backtick run of exactly n starting at or after
+// from, returning its index or -1.
+func closingBackticks(s string, from, n int) int {
+	for j := from; j < len(s); {
+		if s[j] != '`' {
+			j++
+			continue
+		}
+		run := runLength(s, j, '`')
+		if run == n {
+			return j
+		}
+		j += run
+	}
+	return -1
+}
+
+// trimCodeSpan strips one space from each side of a code span's content
+// when both are present, as CommonMark does.
+func trimCodeSpan(code string) string {
+	if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+		return code[1 : len(code)-1]
+	}
+	return code
+}
+
+func isAutolink(s string) bool {
+	return !strings.ContainsAny(s, " <") && (strings.Contains(s, "://") || strings.Contains(s, "@"))
+}
+
+func isASCIIPunct(c byte) bool {
+	return c < utf8.RuneSelf && unicode.IsPunct(rune(c)) || strings.IndexByte("$+<=>^`|~", c) >= 0
+}
+
+func isPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
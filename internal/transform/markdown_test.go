@@ -0,0 +1,67 @@
+package transform
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "A fast CLI.", "A fast CLI."},
+		{"bold", "A **fast** CLI.", "A fast CLI."},
+		{"bold underscores", "A __fast__ CLI.", "A fast CLI."},
+		{"italic", "An *opinionated* and _small_ CLI.", "An opinionated and small CLI."},
+		{"bold italic", "***Very*** fast.", "Very fast."},
+		{"nested emphasis", "**Fast and *tiny***", "Fast and tiny"},
+		{"strikethrough", "~~slow~~ fast", "slow fast"},
+		{"link", "Built on [Cobra](https://cobra.dev).", "Built on Cobra."},
+		{"emphasis inside link", "See [the **docs**](https://a.dev/docs).", "See the docs."},
+		{"reference link", "See [the docs][1].", "See the docs."},
+		{"image", "![Logo](logo.png) inside", "Logo inside"},
+		{"autolink", "Visit <https://a.dev>.", "Visit https://a.dev."},
+		{"code span", "Run `go test ./...` first.", "Run go test ./... first."},
+		{"snake_case kept", "Reads snake_case_names from my_config.yml.", "Reads snake_case_names from my_config.yml."},
+		{"lone asterisks kept", "Rated 5 * 4 = 20.", "Rated 5 * 4 = 20."},
+		{"C# kept", "Written in C# and F#.", "Written in C# and F#."},
+		{"heading marker dropped", "## C# tools", "C# tools"},
+		{"list marker dropped", "- **Fast** startup", "Fast startup"},
+		{"numbered list marker dropped", "1. First", "First"},
+		{"plus list marker dropped", "+ First", "First"},
+		{"blockquote marker dropped", "> quoted *text*", "quoted text"},
+		{"escaped markers kept", `Use \*args and \_name.`, "Use *args and _name."},
+		{"unclosed emphasis kept", "A **fast CLI.", "A **fast CLI."},
+		{"multiple lines", "**One**\n_Two_", "One\nTwo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlainText(tt.in); got != tt.want {
+				t.Errorf("PlainText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripMarkdown(t *testing.T) {
+	projects := []contentful.Project{{
+		Name:             "**api**",
+		ShortDescription: "A **fast** API.",
+		LongDescription:  "Built with [Go](https://go.dev) and *love*.",
+		Highlights:       []string{"Supports `C#` clients", "Uses snake_case config"},
+	}}
+	got := StripMarkdown(projects)[0]
+	want := contentful.Project{
+		Name:             "**api**",
+		ShortDescription: "A fast API.",
+		LongDescription:  "Built with Go and love.",
+		Highlights:       []string{"Supports C# clients", "Uses snake_case config"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("StripMarkdown() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,80 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+// SectionRoutes maps a lowercased category to the section IDs its projects
+// are written to.
+type SectionRoutes map[string][]string
+
+// ParseSectionRoutes reads a semicolon-separated list of
+// category:section|section entries, e.g.
+// "Library:libraries|backend;Web:frontend". Categories match
+// case-insensitively and a section listed twice for one category counts once.
+func ParseSectionRoutes(spec string) (SectionRoutes, error) {
+	routes := SectionRoutes{}
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		category, sections, ok := strings.Cut(part, ":")
+		key := strings.ToLower(strings.TrimSpace(category))
+		if !ok || key == "" {
+			return nil, fmt.Errorf("route %q: expected category:section|section", part)
+		}
+		for _, s := range strings.Split(sections, "|") {
+			s = strings.TrimSpace(s)
+			if s != "" && !containsString(routes[key], s) {
+				routes[key] = append(routes[key], s)
+			}
+		}
+		if len(routes[key]) == 0 {
+			return nil, fmt.Errorf("route %q: no sections listed", part)
+		}
+	}
+	return routes, nil
+}
+
+// RouteSections groups projects by section. A project goes to every section
+// its category maps to, or to fallback when the category is unmapped; with
+// an empty fallback unmapped projects are left out. Each section keeps the
+// input order and holds a slug at most once.
+func RouteSections(projects []contentful.Project, routes SectionRoutes, fallback string) map[string][]contentful.Project {
+	bySection := make(map[string][]contentful.Project)
+	seen := make(map[string]map[string]bool)
+	for _, p := range projects {
+		sections, ok := routes[strings.ToLower(p.Category)]
+		if !ok {
+			if fallback == "" {
+				continue
+			}
+			sections = []string{fallback}
+		}
+		for _, s := range sections {
+			if seen[s] == nil {
+				seen[s] = make(map[string]bool)
+			}
+			if seen[s][p.Slug] {
+				continue
+			}
+			seen[s][p.Slug] = true
+			bySection[s] = append(bySection[s], p)
+		}
+	}
+	return bySection
+}
+
+func containsString(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
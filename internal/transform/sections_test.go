@@ -0,0 +1,74 @@
+package transform
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+func TestParseSectionRoutes(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    SectionRoutes
+		wantErr bool
+	}{
+		{name: "empty", spec: "", want: SectionRoutes{}},
+		{name: "one to many", spec: "Library:libraries|backend; Web:frontend", want: SectionRoutes{"library": {"libraries", "backend"}, "web": {"frontend"}}},
+		{name: "repeated sections count once", spec: "Library:libraries|libraries;library:backend", want: SectionRoutes{"library": {"libraries", "backend"}}},
+		{name: "missing sections", spec: "Library:", wantErr: true},
+		{name: "missing category", spec: ":libraries", wantErr: true},
+		{name: "no colon", spec: "Library", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSectionRoutes(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSectionRoutes(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteSections(t *testing.T) {
+	routes := SectionRoutes{"library": {"libraries", "backend"}, "backend": {"backend"}}
+	projects := []contentful.Project{
+		{Slug: "orm", Category: "Library"},
+		{Slug: "api", Category: "backend"},
+		{Slug: "site", Category: "Web"},
+		{Slug: "orm", Category: "Library"},
+	}
+
+	tests := []struct {
+		name     string
+		fallback string
+		want     map[string][]string
+	}{
+		{
+			name:     "multiple sections and fallback",
+			fallback: "misc",
+			want:     map[string][]string{"libraries": {"orm"}, "backend": {"orm", "api"}, "misc": {"site"}},
+		},
+		{
+			name: "no fallback drops unmapped",
+			want: map[string][]string{"libraries": {"orm"}, "backend": {"orm", "api"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string][]string)
+			for section, ps := range RouteSections(projects, routes, tt.fallback) {
+				for _, p := range ps {
+					got[section] = append(got[section], p.Slug)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RouteSections = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+// DefaultAliases maps common technology nicknames to their canonical name.
+// TECH_ALIASES adds to and overrides these.
+var DefaultAliases = Aliases{
+	"postgres":   "PostgreSQL",
+	"psql":       "PostgreSQL",
+	"js":         "JavaScript",
+	"ts":         "TypeScript",
+	"k8s":        "Kubernetes",
+	"golang":     "Go",
+	"node":       "Node.js",
+	"nodejs":     "Node.js",
+	"mongo":      "MongoDB",
+	"tailwind":   "Tailwind CSS",
+	"reactjs":    "React",
+	"vuejs":      "Vue.js",
+	"nextjs":     "Next.js",
+	"gcp":        "Google Cloud",
+	"amazon aws": "AWS",
+}
+
+// Aliases maps a lowercased technology alias to its canonical name.
+type Aliases map[string]string
+
+// ParseAliases reads a semicolon-separated list of canonical:alias|alias
+// entries, e.g. "PostgreSQL:Postgres|psql;Kubernetes:k8s", on top of
+// DefaultAliases. Aliases match case-insensitively.
+func ParseAliases(spec string) (Aliases, error) {
+	aliases := make(Aliases, len(DefaultAliases))
+	for k, v := range DefaultAliases {
+		aliases[k] = v
+	}
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		canonical, names, ok := strings.Cut(part, ":")
+		canonical = strings.TrimSpace(canonical)
+		if !ok || canonical == "" {
+			return nil, fmt.Errorf("alias %q: expected canonical:alias|alias", part)
+		}
+		for _, n := range strings.Split(names, "|") {
+			if key := strings.ToLower(strings.TrimSpace(n)); key != "" {
+				aliases[key] = canonical
+			}
+		}
+	}
+	return aliases, nil
+}
+
+// Canonical returns the canonical name for tech, or tech itself when it is
+// not an alias.
+func (a Aliases) Canonical(tech string) string {
+	if c, ok := a[strings.ToLower(strings.TrimSpace(tech))]; ok {
+		return c
+	}
+	return tech
+}
+
+// FilterTechnologies maps each project's technologies through aliases,
+// de-duplicates them case-insensitively keeping the first spelling, then
+// drops any on deny and, when allow is non-empty, any not on allow. Both
+// lists match the canonical names case-insensitively. What is left is cut
+// to the first max entries; zero leaves it uncapped.
+func FilterTechnologies(projects []contentful.Project, aliases Aliases, deny, allow []string, max int) []contentful.Project {
+	denied := lowerSet(deny)
+	allowed := lowerSet(allow)
+	for i := range projects {
+		seen := make(map[string]bool, len(projects[i].Technologies))
+		kept := make([]string, 0, len(projects[i].Technologies))
+		for _, t := range projects[i].Technologies {
+			t = strings.TrimSpace(aliases.Canonical(t))
+			key := strings.ToLower(t)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			if denied[key] || (len(allowed) > 0 && !allowed[key]) {
+				continue
+			}
+			kept = append(kept, t)
+		}
+		if max > 0 && len(kept) > max {
+			kept = kept[:max]
+		}
+		projects[i].Technologies = kept
+	}
+	return projects
+}
+
+func lowerSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[strings.ToLower(v)] = true
+	}
+	return set
+}
@@ -0,0 +1,64 @@
+package transform
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+func TestFilterTechnologies(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []string
+		aliases Aliases
+		deny    []string
+		allow   []string
+		max     int
+		want    []string
+	}{
+		{
+			name: "case-insensitive duplicates keep the first spelling",
+			in:   []string{"Docker", "Go", "docker", " Go ", ""},
+			want: []string{"Docker", "Go"},
+		},
+		{
+			name:    "aliases merge spellings",
+			in:      []string{"Postgres", "PostgreSQL", "Go"},
+			aliases: Aliases{"postgres": "PostgreSQL"},
+			want:    []string{"PostgreSQL", "Go"},
+		},
+		{
+			name: "denylist",
+			in:   []string{"Go", "Git", "markdown", "Docker"},
+			deny: []string{"git", "Markdown"},
+			want: []string{"Go", "Docker"},
+		},
+		{
+			name:  "allowlist",
+			in:    []string{"Go", "Make", "React"},
+			allow: []string{"go", "react"},
+			want:  []string{"Go", "React"},
+		},
+		{
+			name: "cap after filtering",
+			in:   []string{"Git", "Go", "go", "Docker", "React", "Redis"},
+			deny: []string{"Git"},
+			max:  3,
+			want: []string{"Go", "Docker", "React"},
+		},
+		{
+			name: "zero max leaves it uncapped",
+			in:   []string{"Go", "Docker", "React"},
+			want: []string{"Go", "Docker", "React"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FilterTechnologies([]contentful.Project{{Technologies: tt.in}}, tt.aliases, tt.deny, tt.allow, tt.max)
+			if !reflect.DeepEqual(got[0].Technologies, tt.want) {
+				t.Errorf("technologies = %q, want %q", got[0].Technologies, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package transform
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+// Rule applies a single named transform to one Project field.
+type Rule struct {
+	Field string
+	Op    string
+	Arg   string
+}
+
+// Parse reads a semicolon-separated list of rules in the form
+// field:op or field:op=arg, e.g. "githubUrl:prefix=https://x;category:uppercase".
+// Fields use their JSON names. Supported ops are prefix, suffix, uppercase,
+// truncate and query, which sets a URL query parameter given as key=value.
+func Parse(spec string) ([]Rule, error) {
+	var rules []Rule
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		field, opArg, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("transform %q: expected field:op", part)
+		}
+		op, arg, _ := strings.Cut(opArg, "=")
+		r := Rule{Field: strings.TrimSpace(field), Op: strings.TrimSpace(op), Arg: arg}
+
+		if _, ok := fieldPtr(&contentful.Project{}, r.Field); !ok {
+			return nil, fmt.Errorf("transform %q: unknown field %q", part, r.Field)
+		}
+		switch r.Op {
+		case "prefix", "suffix", "uppercase":
+		case "truncate":
+			if n, err := strconv.Atoi(r.Arg); err != nil || n < 0 {
+				return nil, fmt.Errorf("transform %q: truncate needs a non-negative length", part)
+			}
+		case "query":
+			if key, _, _ := strings.Cut(r.Arg, "="); key == "" {
+				return nil, fmt.Errorf("transform %q: query needs key=value", part)
+			}
+		default:
+			return nil, fmt.Errorf("transform %q: unknown op %q", part, r.Op)
+		}
+
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// Apply runs every rule, in order, against each project.
+func Apply(projects []contentful.Project, rules []Rule) []contentful.Project {
+	for i := range projects {
+		for _, r := range rules {
+			ptr, ok := fieldPtr(&projects[i], r.Field)
+			if !ok {
+				continue
+			}
+			*ptr = apply(*ptr, r)
+		}
+	}
+	return projects
+}
+
+func apply(value string, r Rule) string {
+	switch r.Op {
+	case "prefix":
+		if value == "" {
+			return value
+		}
+		return r.Arg + value
+	case "suffix":
+		if value == "" {
+			return value
+		}
+		return value + r.Arg
+	case "uppercase":
+		return strings.ToUpper(value)
+	case "truncate":
+		n, _ := strconv.Atoi(r.Arg)
+		runes := []rune(value)
+		if len(runes) > n {
+			return string(runes[:n])
+		}
+	case "query":
+		key, val, _ := strings.Cut(r.Arg, "=")
+		return SetQueryParam(value, key, val)
+	}
+	return value
+}
+
+// SetQueryParam sets key=value in rawURL's query string, keeping any other
+// parameters in their original order and the fragment intact. An existing
+// key is replaced where it first appears and its repeats dropped, so
+// applying it twice is a no-op. Empty or unparsable URLs are returned
+// unchanged.
+func SetQueryParam(rawURL, key, value string) string {
+	if rawURL == "" {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	// Rewritten pair by pair, since url.Values.Encode would sort the keys
+	param := url.QueryEscape(key) + "=" + url.QueryEscape(value)
+	var pairs []string
+	replaced := false
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		k, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(k); err == nil && name == key {
+			if !replaced {
+				pairs = append(pairs, param)
+				replaced = true
+			}
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+	if !replaced {
+		pairs = append(pairs, param)
+	}
+	u.RawQuery = strings.Join(pairs, "&")
+	return u.String()
+}
+
+// fieldPtr maps a Project JSON field name to the string it addresses.
+func fieldPtr(p *contentful.Project, field string) (*string, bool) {
+	switch field {
+	case "name":
+		return &p.Name, true
+	case "slug":
+		return &p.Slug, true
+	case "shortDescription":
+		return &p.ShortDescription, true
+	case "longDescription":
+		return &p.LongDescription, true
+	case "githubUrl":
+		return &p.GithubURL, true
+	case "liveUrl":
+		return &p.LiveURL, true
+	case "gradient":
+		return &p.Gradient, true
+	case "category":
+		return &p.Category, true
+	}
+	return nil, false
+}
+
+// FitHighlights drops highlights from the end of each project until their
+// combined length in characters is at most maxTotal. Zero disables it.
+func FitHighlights(projects []contentful.Project, maxTotal int) []contentful.Project {
+	if maxTotal <= 0 {
+		return projects
+	}
+	for i := range projects {
+		total := 0
+		for j, h := range projects[i].Highlights {
+			total += utf8.RuneCountInString(h)
+			if total > maxTotal {
+				projects[i].Highlights = projects[i].Highlights[:j]
+				break
+			}
+		}
+	}
+	return projects
+}
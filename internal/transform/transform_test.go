@@ -0,0 +1,64 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+func TestSetQueryParam(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"no query", "https://a.dev/app", "https://a.dev/app?ref=portfolio"},
+		{"existing query keeps its order", "https://a.dev/?z=1&a=2", "https://a.dev/?z=1&a=2&ref=portfolio"},
+		{"fragment kept", "https://a.dev/docs#install", "https://a.dev/docs?ref=portfolio#install"},
+		{"query and fragment", "https://a.dev/?b=2#top", "https://a.dev/?b=2&ref=portfolio#top"},
+		{"existing key replaced in place", "https://a.dev/?z=1&ref=old&a=2", "https://a.dev/?z=1&ref=portfolio&a=2"},
+		{"repeated key collapsed", "https://a.dev/?ref=a&x=1&ref=b", "https://a.dev/?ref=portfolio&x=1"},
+		{"already set", "https://a.dev/?ref=portfolio", "https://a.dev/?ref=portfolio"},
+		{"empty", "", ""},
+		{"unparsable", "http://[::1", "http://[::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SetQueryParam(tt.in, "ref", "portfolio"); got != tt.want {
+				t.Errorf("SetQueryParam(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyQueryRule(t *testing.T) {
+	rules := []Rule{
+		{Field: "githubUrl", Op: "query", Arg: "ref=portfolio"},
+		{Field: "liveUrl", Op: "query", Arg: "ref=portfolio"},
+	}
+	tests := []struct {
+		name                 string
+		in                   contentful.Project
+		wantGitHub, wantLive string
+	}{
+		{
+			name:       "both URLs",
+			in:         contentful.Project{GithubURL: "https://github.com/octo/a", LiveURL: "https://a.dev/?lang=en"},
+			wantGitHub: "https://github.com/octo/a?ref=portfolio",
+			wantLive:   "https://a.dev/?lang=en&ref=portfolio",
+		},
+		{
+			name:       "empty live URL untouched",
+			in:         contentful.Project{GithubURL: "https://github.com/octo/a"},
+			wantGitHub: "https://github.com/octo/a?ref=portfolio",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Apply([]contentful.Project{tt.in}, rules)[0]
+			if got.GithubURL != tt.wantGitHub || got.LiveURL != tt.wantLive {
+				t.Errorf("urls = %q, %q, want %q, %q", got.GithubURL, got.LiveURL, tt.wantGitHub, tt.wantLive)
+			}
+		})
+	}
+}
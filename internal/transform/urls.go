@@ -0,0 +1,74 @@
+package transform
+
+import (
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+// NormalizeURLs trims each project's URL fields and adds an https:// scheme
+// when none is given. Values that still do not parse as an absolute http(s)
+// URL are logged and cleared; with strict, a project whose required
+// githubUrl is invalid is dropped instead. An empty liveUrl is left as is.
+func NormalizeURLs(projects []contentful.Project, strict bool) []contentful.Project {
+	kept := projects[:0]
+	for _, p := range projects {
+		if p.LiveURL != "" {
+			u, ok := normalizeURL(p.LiveURL)
+			if !ok {
+				log.Printf("WARNING: clearing invalid liveUrl %q on %s", p.LiveURL, p.Slug)
+			}
+			p.LiveURL = u
+		}
+
+		u, ok := normalizeURL(p.GithubURL)
+		if !ok {
+			if strict {
+				log.Printf("WARNING: dropping %s: invalid githubUrl %q", p.Slug, p.GithubURL)
+				continue
+			}
+			log.Printf("WARNING: clearing invalid githubUrl %q on %s", p.GithubURL, p.Slug)
+		}
+		p.GithubURL = u
+		kept = append(kept, p)
+	}
+	return kept
+}
+
+// KeepLiveURLs fills the liveUrl of each slug in refill with the one the same
+// slug currently has in existing, so a repo whose homepage was cleared keeps
+// its link. refill should hold the slugs that came back without a liveUrl,
+// not those NormalizeURLs cleared as invalid.
+func KeepLiveURLs(projects, existing []contentful.Project, refill map[string]bool) []contentful.Project {
+	current := make(map[string]string, len(existing))
+	for _, p := range existing {
+		if p.LiveURL != "" {
+			current[p.Slug] = p.LiveURL
+		}
+	}
+	for i := range projects {
+		if projects[i].LiveURL == "" && refill[projects[i].Slug] {
+			projects[i].LiveURL = current[projects[i].Slug]
+		}
+	}
+	return projects
+}
+
+// normalizeURL returns the cleaned URL and whether it is valid. An empty
+// input is returned as-is and counts as invalid.
+func normalizeURL(raw string) (string, bool) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return "", false
+	}
+	if !strings.Contains(s, "://") {
+		s = "https://" + strings.TrimPrefix(s, "//")
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.ContainsAny(s, " \t\n") {
+		return "", false
+	}
+	return u.String(), true
+}
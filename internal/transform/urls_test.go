@@ -0,0 +1,86 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/alberto-moreno-sa/github-cms-sync/internal/contentful"
+)
+
+func TestNormalizeURLs(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         contentful.Project
+		strict     bool
+		wantGitHub string
+		wantLive   string
+		wantKept   bool
+	}{
+		{
+			name:       "valid",
+			in:         contentful.Project{GithubURL: "https://github.com/octo/a", LiveURL: "https://a.dev"},
+			wantGitHub: "https://github.com/octo/a", wantLive: "https://a.dev", wantKept: true,
+		},
+		{
+			name:       "scheme-less",
+			in:         contentful.Project{GithubURL: "github.com/octo/a", LiveURL: "a.dev/app"},
+			wantGitHub: "https://github.com/octo/a", wantLive: "https://a.dev/app", wantKept: true,
+		},
+		{
+			name:       "whitespace",
+			in:         contentful.Project{GithubURL: "  https://github.com/octo/a\n", LiveURL: " https://a.dev "},
+			wantGitHub: "https://github.com/octo/a", wantLive: "https://a.dev", wantKept: true,
+		},
+		{
+			name:       "invalid live url cleared",
+			in:         contentful.Project{GithubURL: "https://github.com/octo/a", LiveURL: "ftp://a.dev"},
+			wantGitHub: "https://github.com/octo/a", wantKept: true,
+		},
+		{
+			name:     "invalid github url cleared",
+			in:       contentful.Project{GithubURL: "not a url"},
+			wantKept: true,
+		},
+		{
+			name:   "invalid github url dropped when strict",
+			in:     contentful.Project{GithubURL: "not a url"},
+			strict: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeURLs([]contentful.Project{tt.in}, tt.strict)
+			if kept := len(got) == 1; kept != tt.wantKept {
+				t.Fatalf("kept = %v, want %v", kept, tt.wantKept)
+			}
+			if !tt.wantKept {
+				return
+			}
+			if got[0].GithubURL != tt.wantGitHub || got[0].LiveURL != tt.wantLive {
+				t.Errorf("got githubUrl %q liveUrl %q, want %q %q", got[0].GithubURL, got[0].LiveURL, tt.wantGitHub, tt.wantLive)
+			}
+		})
+	}
+}
+
+func TestKeepLiveURLs(t *testing.T) {
+	existing := []contentful.Project{
+		{Slug: "a", LiveURL: "https://a.dev"},
+		{Slug: "b", LiveURL: "https://b.dev"},
+		{Slug: "c", LiveURL: "https://c.dev"},
+	}
+	projects := []contentful.Project{
+		{Slug: "a"},
+		{Slug: "b"},
+		{Slug: "c", LiveURL: "https://new-c.dev"},
+		{Slug: "d"},
+	}
+	refill := map[string]bool{"a": true, "d": true}
+
+	got := KeepLiveURLs(projects, existing, refill)
+	want := map[string]string{"a": "https://a.dev", "b": "", "c": "https://new-c.dev", "d": ""}
+	for _, p := range got {
+		if p.LiveURL != want[p.Slug] {
+			t.Errorf("%s liveUrl = %q, want %q", p.Slug, p.LiveURL, want[p.Slug])
+		}
+	}
+}